@@ -0,0 +1,162 @@
+package autograde
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// limits of an extracted archive, checked against the bytes actually written
+	MaxArchiveFiles  = 1000
+	MaxEntrySize     = 10 * 1024 * 1024
+	MaxExtractedSize = 50 * 1024 * 1024
+)
+
+// archive escapes its directory, has links or is larger than the limits
+var ErrUnsafeArchive = errors.New("unsafe archive")
+
+// extract the regular files of the zip whose name passes keep into dest
+func ExtractZip(src, dest string, keep func(name string) bool) error {
+	archive, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("unable to open zip: %w", err)
+	}
+	defer archive.Close()
+
+	if len(archive.File) > MaxArchiveFiles {
+		return fmt.Errorf("%w: more than %d files", ErrUnsafeArchive, MaxArchiveFiles)
+	}
+
+	var total int64
+	for _, entry := range archive.File {
+		target, err := entryPath(dest, entry.Name)
+		if err != nil {
+			return err
+		}
+
+		mode := entry.Mode()
+		if mode&os.ModeSymlink != 0 || (!mode.IsRegular() && !mode.IsDir()) {
+			return fmt.Errorf("%w: %s isn't a regular file", ErrUnsafeArchive, entry.Name)
+		}
+
+		if mode.IsDir() || !keep(filepath.ToSlash(entry.Name)) {
+			continue
+		}
+
+		// declared size can lie, the copy below is limited again
+		if entry.UncompressedSize64 > MaxEntrySize {
+			return fmt.Errorf("%w: %s is larger than %d bytes", ErrUnsafeArchive, entry.Name, MaxEntrySize)
+		}
+
+		written, err := extractEntry(entry, target)
+		if err != nil {
+			return err
+		}
+
+		total += written
+		if total > MaxExtractedSize {
+			return fmt.Errorf("%w: extracted files are larger than %d bytes", ErrUnsafeArchive, MaxExtractedSize)
+		}
+	}
+
+	return nil
+}
+
+// path of the entry inside dest, absolute names & names leaving dest are rejected (zip slip)
+func entryPath(dest, name string) (string, error) {
+	if name == "" || strings.Contains(name, "\\") || filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("%w: invalid file name %q", ErrUnsafeArchive, name)
+	}
+
+	target := filepath.Join(dest, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dest, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %q is outside the archive", ErrUnsafeArchive, name)
+	}
+
+	return target, nil
+}
+
+func extractEntry(entry *zip.File, target string) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return 0, err
+	}
+
+	reader, err := entry.Open()
+	if err != nil {
+		return 0, fmt.Errorf("unable to read %s: %w", entry.Name, err)
+	}
+	defer reader.Close()
+
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	written, err := io.Copy(file, io.LimitReader(reader, MaxEntrySize+1))
+	if err != nil {
+		return written, fmt.Errorf("unable to extract %s: %w", entry.Name, err)
+	}
+
+	if written > MaxEntrySize {
+		return written, fmt.Errorf("%w: %s is larger than %d bytes", ErrUnsafeArchive, entry.Name, MaxEntrySize)
+	}
+
+	return written, nil
+}
+
+// test suite must be a safe zip with at least one go test file
+func CheckTestSuite(path string) error {
+	dir, err := os.MkdirTemp("", "golearn-suite-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	tests := 0
+	err = ExtractZip(path, dir, func(name string) bool {
+		if isTestFile(name) {
+			tests++
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+
+	if tests == 0 {
+		return fmt.Errorf("test suite has no _test.go file")
+	}
+
+	// only declared Test functions are scored, the suite must parse & have one
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(defaultGoMod), 0o644); err != nil {
+		return err
+	}
+
+	declared, err := declaredTests(dir)
+	if err != nil {
+		return err
+	}
+
+	if declared.count() == 0 {
+		return fmt.Errorf("test suite has no Test function")
+	}
+
+	return nil
+}
+
+func isTestFile(name string) bool {
+	return strings.HasSuffix(name, "_test.go")
+}
+
+// test fixtures are read from testdata directories
+func isTestData(name string) bool {
+	return name == "testdata" || strings.HasPrefix(name, "testdata/") || strings.Contains(name, "/testdata/")
+}
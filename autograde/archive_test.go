@@ -0,0 +1,207 @@
+package autograde
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type zipEntry struct {
+	name    string
+	content []byte
+	mode    fs.FileMode
+}
+
+func writeZip(t *testing.T, entries ...zipEntry) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "archive.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writer := zip.NewWriter(file)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		if entry.mode != 0 {
+			header.SetMode(entry.mode)
+		}
+
+		w, err := writer.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(entry.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func file(name, content string) zipEntry {
+	return zipEntry{name: name, content: []byte(content)}
+}
+
+func TestExtractZip(t *testing.T) {
+	tooMany := make([]zipEntry, MaxArchiveFiles+1)
+	for i := range tooMany {
+		tooMany[i] = file(fmt.Sprintf("f%d.go", i), "package f")
+	}
+
+	// compresses to a few kb each, only the extracted size is large
+	bomb := make([]zipEntry, MaxExtractedSize/MaxEntrySize+1)
+	for i := range bomb {
+		bomb[i] = zipEntry{name: fmt.Sprintf("part%d.go", i), content: make([]byte, MaxEntrySize)}
+	}
+
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "regular files",
+			entries: []zipEntry{file("main.go", "package main"), {name: "pkg/", mode: fs.ModeDir | 0o755}, file("pkg/pkg.go", "package pkg")},
+			want:    []string{"main.go", "pkg/pkg.go"},
+		},
+		{name: "parent directory", entries: []zipEntry{file("../evil.go", "package evil")}, wantErr: true},
+		{name: "parent directory inside path", entries: []zipEntry{file("pkg/../../evil.go", "package evil")}, wantErr: true},
+		{name: "absolute path", entries: []zipEntry{file("/tmp/evil.go", "package evil")}, wantErr: true},
+		{name: "windows path", entries: []zipEntry{file(`..\..\evil.go`, "package evil")}, wantErr: true},
+		{name: "symlink", entries: []zipEntry{{name: "link.go", content: []byte("/etc/passwd"), mode: fs.ModeSymlink | 0o777}}, wantErr: true},
+		{name: "symlink skipped by keep", entries: []zipEntry{{name: "README", content: []byte("/etc/passwd"), mode: fs.ModeSymlink | 0o777}}, wantErr: true},
+		{name: "device", entries: []zipEntry{{name: "dev.go", mode: fs.ModeDevice | 0o644}}, wantErr: true},
+		{name: "too many files", entries: tooMany, wantErr: true},
+		{name: "entry too large", entries: []zipEntry{{name: "big.go", content: make([]byte, MaxEntrySize+1)}}, wantErr: true},
+		{name: "total too large", entries: bomb, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dest := filepath.Join(root, "dest")
+
+			err := ExtractZip(writeZip(t, tt.entries...), dest, func(name string) bool {
+				return strings.HasSuffix(name, ".go")
+			})
+
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsafeArchive) {
+					t.Fatalf("got %v, want ErrUnsafeArchive", err)
+				}
+
+				// nothing is written next to the destination
+				if _, err := os.Stat(filepath.Join(root, "evil.go")); !os.IsNotExist(err) {
+					t.Fatal("entry escaped the destination")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			filepath.WalkDir(dest, func(path string, entry fs.DirEntry, err error) error {
+				if err == nil && !entry.IsDir() {
+					rel, _ := filepath.Rel(dest, path)
+					got = append(got, filepath.ToSlash(rel))
+				}
+				return nil
+			})
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("got files %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryPath(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "dest")
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "main.go", want: filepath.Join(dest, "main.go")},
+		{name: "pkg/./sub/file.go", want: filepath.Join(dest, "pkg", "sub", "file.go")},
+		{name: "pkg/../main.go", want: filepath.Join(dest, "main.go")},
+		{name: "", wantErr: true},
+		{name: "..", wantErr: true},
+		{name: "../dest2/main.go", wantErr: true},
+		{name: "a/../../main.go", wantErr: true},
+		{name: "/etc/passwd", wantErr: true},
+		{name: `pkg\main.go`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := entryPath(dest, tt.name)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsafeArchive) {
+					t.Fatalf("got %q %v, want ErrUnsafeArchive", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTestSuite(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		wantErr string
+	}{
+		{name: "suite", entries: []zipEntry{file("calc_test.go", "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {}\n")}},
+		{name: "no test file", entries: []zipEntry{file("calc.go", "package calc\n")}, wantErr: "test suite has no _test.go file"},
+		{name: "no test function", entries: []zipEntry{file("calc_test.go", "package calc\n\nfunc helper() {}\n")}, wantErr: "test suite has no Test function"},
+		{name: "not go", entries: []zipEntry{file("calc_test.go", "not go")}, wantErr: "test suite file calc_test.go"},
+		{name: "zip slip", entries: []zipEntry{file("../calc_test.go", "package calc\n")}, wantErr: ErrUnsafeArchive.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckTestSuite(writeZip(t, tt.entries...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := CheckTestSuite(filepath.Join(t.TempDir(), "missing.zip")); err == nil {
+		t.Fatal("expected an error for a missing archive")
+	}
+
+	if err := CheckTestSuite(writeZip(t)); err == nil || !strings.Contains(err.Error(), "no _test.go") {
+		t.Fatalf("got %v for an empty archive", err)
+	}
+}
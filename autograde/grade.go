@@ -0,0 +1,85 @@
+package autograde
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// module file written when the submission has none, only the standard library can be used then
+const defaultGoMod = "module submission\n\ngo 1.22\n"
+
+// extract the submission, put the hidden tests over it & run them in the sandbox,
+// tests shipped with the submission are left out so only the hidden suite counts
+func Grade(ctx context.Context, sandbox Sandbox, submissionZip, testSuiteZip string, timeout time.Duration) (*Result, error) {
+	dir, err := os.MkdirTemp("", "golearn-grade-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	err = ExtractZip(submissionZip, dir, func(name string) bool {
+		return !isTestFile(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := moduleRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ExtractZip(testSuiteZip, root, func(name string) bool {
+		return isTestFile(name) || isTestData(name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	declared, err := declaredTests(root)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := sandbox.Run(ctx, root, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseResult(output, declared)
+}
+
+// directory of the shallowest go.mod, the extraction directory gets one when there's none
+func moduleRoot(dir string) (string, error) {
+	root := ""
+	depth := -1
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || entry.Name() != "go.mod" {
+			return nil
+		}
+
+		moduleDir := filepath.Dir(path)
+		if d := strings.Count(moduleDir, string(filepath.Separator)); depth < 0 || d < depth {
+			root, depth = moduleDir, d
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if root != "" {
+		return root, nil
+	}
+
+	return dir, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(defaultGoMod), 0o644)
+}
@@ -0,0 +1,209 @@
+package autograde
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// output kept per test & for a failed build
+const maxTestOutput = 4 * 1024
+
+type TestStatus string
+
+const (
+	TestPass TestStatus = "pass"
+	TestFail TestStatus = "fail"
+	TestSkip TestStatus = "skip"
+)
+
+type TestCase struct {
+	Package string
+	Name    string
+	Status  TestStatus
+	Elapsed float64
+	Output  string
+}
+
+// outcome of a test run, score is the share of passed tests out of 100
+type Result struct {
+	Tests       []TestCase
+	Passed      int
+	Failed      int
+	Skipped     int
+	BuildFailed bool
+	BuildOutput string
+	Score       int
+	Feedback    string
+}
+
+// event written by go test -json
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// parse go test -json output, subtests count as part of their top level test,
+// the stream is written by submission code so only declared tests are scored & a failure is never undone
+func ParseResult(output []byte, declared DeclaredTests) (*Result, error) {
+	type testKey struct{ pkg, name string }
+
+	cases := make(map[testKey]*TestCase)
+	packageOutput := make(map[string]*strings.Builder)
+	failedPackages := make(map[string]bool)
+	events := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 64*1024), MaxOutputSize)
+	for scanner.Scan() {
+		var event testEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// build errors are printed as plain text before the json events
+			appendLimited(packageOutputOf(packageOutput, ""), scanner.Text()+"\n")
+			continue
+		}
+		events++
+
+		if event.Test == "" {
+			appendLimited(packageOutputOf(packageOutput, event.Package), event.Output)
+			if event.Action == "fail" {
+				failedPackages[event.Package] = true
+			}
+			continue
+		}
+
+		name := strings.SplitN(event.Test, "/", 2)[0]
+		if !declared.has(event.Package, name) {
+			continue
+		}
+
+		key := testKey{event.Package, name}
+		testCase, ok := cases[key]
+		if !ok {
+			testCase = &TestCase{Package: event.Package, Name: name}
+			cases[key] = testCase
+		}
+
+		switch event.Action {
+		case "output":
+			if len(testCase.Output) < maxTestOutput {
+				testCase.Output += event.Output
+			}
+		case "pass", "fail", "skip":
+			if event.Test == name && testCase.Status != TestFail {
+				testCase.Status = TestStatus(event.Action)
+				testCase.Elapsed = event.Elapsed
+			}
+		}
+	}
+
+	if events == 0 {
+		return nil, fmt.Errorf("test run gave no result: %s", truncate(string(output), maxTestOutput))
+	}
+
+	result := &Result{}
+	testedPackages := make(map[string]bool)
+	for _, testCase := range cases {
+		testCase.Output = truncate(testCase.Output, maxTestOutput)
+		testedPackages[testCase.Package] = true
+
+		switch testCase.Status {
+		case TestPass:
+			result.Passed++
+		case TestSkip:
+			result.Skipped++
+		default:
+			// test without an outcome was killed by a panic or the timeout
+			testCase.Status = TestFail
+			result.Failed++
+		}
+
+		result.Tests = append(result.Tests, *testCase)
+	}
+
+	// declared test without any event never ran
+	for pkg, names := range declared {
+		for _, name := range names {
+			if cases[testKey{pkg, name}] == nil {
+				result.Failed++
+				result.Tests = append(result.Tests, TestCase{Package: pkg, Name: name, Status: TestFail})
+			}
+		}
+	}
+
+	sort.Slice(result.Tests, func(i, j int) bool {
+		if result.Tests[i].Package != result.Tests[j].Package {
+			return result.Tests[i].Package < result.Tests[j].Package
+		}
+		return result.Tests[i].Name < result.Tests[j].Name
+	})
+
+	// package failing before any of its tests ran didn't build
+	var buildOutput strings.Builder
+	for pkg := range failedPackages {
+		if !testedPackages[pkg] {
+			result.BuildFailed = true
+			buildOutput.WriteString(packageOutputOf(packageOutput, pkg).String())
+		}
+	}
+	if result.BuildFailed {
+		buildOutput.WriteString(packageOutputOf(packageOutput, "").String())
+		result.BuildOutput = truncate(buildOutput.String(), maxTestOutput)
+	}
+
+	result.score()
+	return result, nil
+}
+
+func (r *Result) score() {
+	total := r.Passed + r.Failed
+
+	switch {
+	case r.BuildFailed:
+		r.Score = 0
+		r.Feedback = "build failed:\n" + r.BuildOutput
+	case total == 0:
+		r.Score = 0
+		r.Feedback = "no test was run"
+	default:
+		r.Score = int(math.Round(100 * float64(r.Passed) / float64(total)))
+		r.Feedback = fmt.Sprintf("%d of %d tests passed", r.Passed, total)
+
+		var failed []string
+		for _, testCase := range r.Tests {
+			if testCase.Status == TestFail {
+				failed = append(failed, testCase.Name)
+			}
+		}
+		if len(failed) > 0 {
+			r.Feedback += ", failed: " + strings.Join(failed, ", ")
+		}
+	}
+}
+
+func packageOutputOf(outputs map[string]*strings.Builder, pkg string) *strings.Builder {
+	if outputs[pkg] == nil {
+		outputs[pkg] = &strings.Builder{}
+	}
+	return outputs[pkg]
+}
+
+func appendLimited(b *strings.Builder, text string) {
+	if b.Len() < maxTestOutput {
+		b.WriteString(text)
+	}
+}
+
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return text[:limit] + "\n... output truncated"
+}
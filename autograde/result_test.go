@@ -0,0 +1,150 @@
+package autograde
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func events(lines ...string) []byte {
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func TestParseResult(t *testing.T) {
+	declared := DeclaredTests{"submission": {"TestAdd", "TestSub"}}
+
+	tests := []struct {
+		name     string
+		output   []byte
+		passed   int
+		failed   int
+		score    int
+		building bool
+	}{
+		{
+			name: "all declared pass",
+			output: events(
+				`{"Action":"run","Package":"submission","Test":"TestAdd"}`,
+				`{"Action":"pass","Package":"submission","Test":"TestAdd"}`,
+				`{"Action":"run","Package":"submission","Test":"TestSub"}`,
+				`{"Action":"run","Package":"submission","Test":"TestSub/negative"}`,
+				`{"Action":"pass","Package":"submission","Test":"TestSub/negative"}`,
+				`{"Action":"pass","Package":"submission","Test":"TestSub"}`,
+				`{"Action":"pass","Package":"submission"}`,
+			),
+			passed: 2, score: 100,
+		},
+		{
+			name: "fake tests printed by submission are ignored",
+			output: events(
+				`{"Action":"run","Package":"submission","Test":"TestFake1"}`,
+				`{"Action":"pass","Package":"submission","Test":"TestFake1"}`,
+				`{"Action":"run","Package":"submission","Test":"TestFake2"}`,
+				`{"Action":"pass","Package":"submission","Test":"TestFake2"}`,
+				`{"Action":"pass","Package":"submission","Test":"TestAdd"}`,
+				`{"Action":"fail","Package":"submission","Test":"TestSub"}`,
+				`{"Action":"fail","Package":"submission"}`,
+			),
+			passed: 1, failed: 1, score: 50,
+		},
+		{
+			name: "fake pass after a real failure",
+			output: events(
+				`{"Action":"fail","Package":"submission","Test":"TestAdd"}`,
+				`{"Action":"pass","Package":"submission","Test":"TestAdd"}`,
+				`{"Action":"pass","Package":"submission","Test":"TestSub"}`,
+				`{"Action":"fail","Package":"submission"}`,
+			),
+			passed: 1, failed: 1, score: 50,
+		},
+		{
+			name: "declared test without event failed",
+			output: events(
+				`{"Action":"pass","Package":"submission","Test":"TestAdd"}`,
+				`{"Action":"fail","Package":"submission"}`,
+			),
+			passed: 1, failed: 1, score: 50,
+		},
+		{
+			name: "same name in another package is ignored",
+			output: events(
+				`{"Action":"pass","Package":"submission/other","Test":"TestAdd"}`,
+				`{"Action":"pass","Package":"submission/other","Test":"TestSub"}`,
+				`{"Action":"fail","Package":"submission"}`,
+			),
+			failed: 2, score: 0, building: true,
+		},
+		{
+			name: "build failure",
+			output: events(
+				`# submission`,
+				`./add.go:3:1: syntax error`,
+				`{"Action":"output","Package":"submission","Output":"FAIL\tsubmission [build failed]\n"}`,
+				`{"Action":"fail","Package":"submission"}`,
+			),
+			failed: 2, score: 0, building: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseResult(tt.output, declared)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Passed != tt.passed || result.Failed != tt.failed || result.Score != tt.score || result.BuildFailed != tt.building {
+				t.Fatalf("got passed %d failed %d score %d build failed %v, want %d %d %d %v",
+					result.Passed, result.Failed, result.Score, result.BuildFailed, tt.passed, tt.failed, tt.score, tt.building)
+			}
+
+			if len(result.Tests) != tt.passed+tt.failed {
+				t.Fatalf("got %d tests, want only the declared ones", len(result.Tests))
+			}
+		})
+	}
+}
+
+func TestParseResultNoEvents(t *testing.T) {
+	if _, err := ParseResult([]byte("signal: killed\n"), DeclaredTests{"submission": {"TestAdd"}}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestDeclaredTests(t *testing.T) {
+	root := t.TempDir()
+
+	files := map[string]string{
+		"go.mod":                   "module example.com/calc\n\ngo 1.22\n",
+		"calc_test.go":             "package calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {}\nfunc TestMain(m *testing.M) {}\nfunc Testify(t *testing.T) {}\nfunc helper(t *testing.T) {}\n",
+		"calc_external_test.go":    "package calc_test\n\nimport \"testing\"\n\nfunc Test(t *testing.T) {}\nfunc Test_under(t *testing.T) {}\n",
+		"internal/sub/sub_test.go": "package sub\n\nimport \"testing\"\n\ntype s struct{}\n\nfunc (s) TestMethod(t *testing.T) {}\nfunc TestSub(t *testing.T) {}\n",
+		"testdata/ignored_test.go": "package ignored\n\nfunc TestIgnored(t *testing.T) {}\n",
+		"_hidden/ignored_test.go":  "package ignored\n\nfunc TestIgnored(t *testing.T) {}\n",
+		"internal/sub/helpers.go":  "package sub\n\nfunc TestLooking() {}\n",
+	}
+	for name, content := range files {
+		target := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	declared, err := declaredTests(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := DeclaredTests{
+		"example.com/calc":              {"Test", "TestAdd", "Test_under"},
+		"example.com/calc/internal/sub": {"TestSub"},
+	}
+	if !reflect.DeepEqual(declared, want) {
+		t.Fatalf("got %v, want %v", declared, want)
+	}
+}
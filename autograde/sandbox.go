@@ -0,0 +1,159 @@
+package autograde
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// output of a test run kept at most, the rest is dropped
+const MaxOutputSize = 1024 * 1024
+
+// runs go test -json on a module directory & returns its output,
+// a failing test run isn't an error, only a run that couldn't finish is
+type Sandbox interface {
+	Name() string
+	Run(ctx context.Context, dir string, timeout time.Duration) ([]byte, error)
+}
+
+// throwaway container without network, capabilities or a writable root, limited in memory, cpu & processes
+type ContainerSandbox struct {
+	Runtime string
+	Image   string
+}
+
+func (s *ContainerSandbox) Name() string {
+	return s.Runtime
+}
+
+func (s *ContainerSandbox) Run(ctx context.Context, dir string, timeout time.Duration) ([]byte, error) {
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, fmt.Errorf("failed to generate sandbox name")
+	}
+	name := "golearn-grade-" + hex.EncodeToString(suffix)
+
+	// build & test get the timeout plus a minute, then the container is killed
+	ctx, cancel := context.WithTimeout(ctx, timeout+time.Minute)
+	defer cancel()
+
+	if err := os.Chmod(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, s.Runtime, "run", "--rm", "--name", name,
+		"--network", "none",
+		"--memory", "512m", "--memory-swap", "512m",
+		"--cpus", "1",
+		"--pids-limit", "256",
+		"--read-only",
+		"--tmpfs", "/tmp:rw,exec,size=512m",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--user", "65534:65534",
+		"--env", "HOME=/tmp",
+		"--env", "GOCACHE=/tmp/gocache",
+		"--env", "GOPATH=/tmp/gopath",
+		"--env", "GOPROXY=off",
+		"--env", "GOTOOLCHAIN=local",
+		"--env", "CGO_ENABLED=0",
+		"--volume", dir+":/work:ro",
+		"--workdir", "/work",
+		s.Image,
+		"go", "test", "-json", "-timeout", timeout.String(), "./...",
+	)
+
+	output := &limitedBuffer{limit: MaxOutputSize}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		// killing the cli leaves the container running
+		exec.Command(s.Runtime, "rm", "--force", name).Run()
+		return output.Bytes(), fmt.Errorf("test run timed out after %s", timeout+time.Minute)
+	}
+
+	// go test exits with 1 when a test fails, the runtime uses 125 and up for its own errors
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() < 125 {
+		return output.Bytes(), nil
+	}
+	if err != nil {
+		return output.Bytes(), fmt.Errorf("sandbox failed: %v", err)
+	}
+
+	return output.Bytes(), nil
+}
+
+// runs go test directly on the host without any isolation, used for local development only
+type LocalSandbox struct{}
+
+func (s *LocalSandbox) Name() string {
+	return "local"
+}
+
+func (s *LocalSandbox) Run(ctx context.Context, dir string, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+time.Minute)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-json", "-timeout", timeout.String(), "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOPROXY=off", "GOFLAGS=", "CGO_ENABLED=0")
+
+	output := &limitedBuffer{limit: MaxOutputSize}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return output.Bytes(), fmt.Errorf("test run timed out after %s", timeout+time.Minute)
+	}
+
+	if _, ok := err.(*exec.ExitError); ok {
+		return output.Bytes(), nil
+	}
+
+	return output.Bytes(), err
+}
+
+// choose sandbox from SANDBOX_RUNTIME env (docker, podman or local), default to docker
+func NewSandbox() Sandbox {
+	runtime := os.Getenv("SANDBOX_RUNTIME")
+	if runtime == "local" {
+		return &LocalSandbox{}
+	}
+
+	if runtime == "" {
+		runtime = "docker"
+	}
+
+	image := os.Getenv("SANDBOX_IMAGE")
+	if image == "" {
+		image = "golang:1.22-alpine"
+	}
+
+	return &ContainerSandbox{Runtime: runtime, Image: image}
+}
+
+// keeps the first limit bytes written & drops the rest without failing the writer
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.Buffer.Write(p[:remaining])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+
+	return len(p), nil
+}
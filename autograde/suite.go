@@ -0,0 +1,141 @@
+package autograde
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// top level tests of the hidden suite by package import path, only these are scored
+type DeclaredTests map[string][]string
+
+func (d DeclaredTests) has(pkg, name string) bool {
+	for _, test := range d[pkg] {
+		if test == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (d DeclaredTests) count() int {
+	total := 0
+	for _, tests := range d {
+		total += len(tests)
+	}
+	return total
+}
+
+// collect Test functions of every _test.go under root, submission tests are never extracted so they all belong to the suite
+func declaredTests(root string) (DeclaredTests, error) {
+	modulePath, err := readModulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	declared := make(DeclaredTests)
+	fset := token.NewFileSet()
+
+	err = filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// go tool skips these directories too
+		if entry.IsDir() {
+			name := entry.Name()
+			if filePath != root && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !isTestFile(entry.Name()) {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, filePath, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("test suite file %s: %v", entry.Name(), err)
+		}
+
+		rel, err := filepath.Rel(root, filepath.Dir(filePath))
+		if err != nil {
+			return err
+		}
+
+		pkg := modulePath
+		if rel != "." {
+			pkg = path.Join(modulePath, filepath.ToSlash(rel))
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if ok && fn.Recv == nil && isTestName(fn.Name.Name) && !declared.has(pkg, fn.Name.Name) {
+				declared[pkg] = append(declared[pkg], fn.Name.Name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tests := range declared {
+		sort.Strings(tests)
+	}
+
+	return declared, nil
+}
+
+// same rule as go test, Test followed by nothing or a non lowercase letter
+func isTestName(name string) bool {
+	if name == "TestMain" || !strings.HasPrefix(name, "Test") {
+		return false
+	}
+
+	if len(name) == len("Test") {
+		return true
+	}
+
+	r, _ := utf8.DecodeRuneInString(name[len("Test"):])
+	return !unicode.IsLower(r)
+}
+
+// module path from the module directive of go.mod
+func readModulePath(goMod string) (string, error) {
+	file, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "module" {
+			continue
+		}
+
+		if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+			return unquoted, nil
+		}
+		return fields[1], nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("go.mod has no module path")
+}
@@ -1,20 +1,138 @@
 package db
 
 import (
+	"fmt"
+
 	"github.com/nadyafa/go-learn/entity"
 	"gorm.io/gorm"
 )
 
-func RunMigration(db *gorm.DB) {
-	db.AutoMigrate(
+func RunMigration(db *gorm.DB) error {
+	// columns added by this run are filled from the data they replace
+	hadAttendStatus := db.Migrator().HasColumn(&entity.Attendance{}, "status")
+	hadAttendClosed := db.Migrator().HasColumn(&entity.Class{}, "attendance_closed")
+	hadGradedAt := db.Migrator().HasColumn(&entity.ProjectSub{}, "graded_at")
+
+	// keep one check-in per student & class before the unique index is created, attended rows win
+	if db.Migrator().HasTable(&entity.Attendance{}) && !db.Migrator().HasIndex(&entity.Attendance{}, "idx_attendance_student_class") {
+		err := db.Exec(`
+		DELETE FROM attendances WHERE attend_id IN (
+			SELECT attend_id FROM (
+				SELECT attend_id, ROW_NUMBER() OVER (PARTITION BY student_id, class_id ORDER BY attended DESC, attend_id) AS n
+				FROM attendances
+			) d
+			WHERE d.n > 1
+		)`).Error
+		if err != nil {
+			return fmt.Errorf("dedupe attendances: %w", err)
+		}
+	}
+
+	err := db.AutoMigrate(
 		&entity.User{},
 		&entity.Course{},
+		&entity.Room{},
 		&entity.Class{},
+		&entity.Rubric{},
+		&entity.RubricCriterion{},
+		&entity.RubricLevel{},
+		&entity.GradeCategory{},
+		&entity.GradeLetter{},
 		&entity.Project{},
 		// &entity.Test{},
 		&entity.Enrollment{},
+		&entity.EnrollmentHistory{},
+		&entity.CourseInvite{},
+		&entity.InviteRedemption{},
+		&entity.CompletionRule{},
+		&entity.Certificate{},
+		&entity.Team{},
+		&entity.TeamMember{},
 		&entity.ProjectSub{},
+		&entity.DeadlineExtension{},
+		&entity.CriterionGrade{},
+		&entity.PeerReview{},
+		&entity.PeerCriterionGrade{},
+		&entity.SimilarityReport{},
+		&entity.SimilarityPair{},
+		&entity.SimilaritySegment{},
+		&entity.TestResult{},
+		&entity.GradeExcusal{},
 		// &entity.TestSub{},
 		&entity.Attendance{},
+		&entity.CheckinCode{},
+		&entity.CheckinAttempt{},
+		&entity.Excuse{},
 	)
+	if err != nil {
+		return err
+	}
+
+	// check-ins recorded before statuses existed only know whether the student attended
+	if !hadAttendStatus {
+		err = db.Exec(`UPDATE attendances SET status = CASE WHEN attended THEN 'present' ELSE 'absent' END`).Error
+		if err != nil {
+			return fmt.Errorf("backfill attendance status: %w", err)
+		}
+	}
+
+	// classes that ended before absences were tracked are left as they are
+	if !hadAttendClosed {
+		err = db.Exec(`UPDATE classes SET attendance_closed = true WHERE end_date < NOW()`).Error
+		if err != nil {
+			return fmt.Errorf("close ended classes: %w", err)
+		}
+	}
+
+	// attempts scored or commented before grading was recorded count as graded, unless only the autograder scored them
+	if !hadGradedAt {
+		err = db.Exec(`
+		UPDATE project_subs SET graded_at = updated_at
+		WHERE (score <> 0 OR description <> '') AND (COALESCE(auto_status, '') = '' OR auto_overridden)`).Error
+		if err != nil {
+			return fmt.Errorf("backfill graded attempts: %w", err)
+		}
+	}
+
+	// number submissions made before attempts were tracked, newest one becomes the latest attempt
+	err = db.Exec(`
+	UPDATE project_subs ps SET attempt = n.attempt, is_latest = n.attempt = n.total
+	FROM (
+		SELECT project_sub_id,
+			ROW_NUMBER() OVER (PARTITION BY project_id, student_id ORDER BY submission_date, project_sub_id) AS attempt,
+			COUNT(*) OVER (PARTITION BY project_id, student_id) AS total
+		FROM project_subs
+	) n
+	WHERE ps.project_sub_id = n.project_sub_id AND ps.attempt = 0`).Error
+	if err != nil {
+		return fmt.Errorf("number attempts: %w", err)
+	}
+
+	// files saved before the storage layer kept their path under uploads/, which is the local storage root
+	err = db.Exec(`
+	UPDATE project_subs SET file_key = substring(project_path from 9), project_path = regexp_replace(project_path, '^.*/', '')
+	WHERE (file_key IS NULL OR file_key = '') AND project_path LIKE 'uploads/%'`).Error
+	if err != nil {
+		return fmt.Errorf("backfill file keys: %w", err)
+	}
+
+	// excuse documents were saved the same way under uploads/excuses
+	err = db.Exec(`
+	UPDATE excuses SET document_key = substring(document_path from 9), document_path = regexp_replace(document_path, '^.*/', '')
+	WHERE (document_key IS NULL OR document_key = '') AND document_path LIKE 'uploads/%'`).Error
+	if err != nil {
+		return fmt.Errorf("backfill excuse document keys: %w", err)
+	}
+
+	if db.Migrator().HasColumn(&entity.Project{}, "test_suite_path") {
+		err = db.Exec(`UPDATE projects SET test_suite_key = substring(test_suite_path from 9) WHERE test_suite_path LIKE 'uploads/%'`).Error
+		if err != nil {
+			return fmt.Errorf("backfill test suite keys: %w", err)
+		}
+		if err := db.Migrator().DropColumn(&entity.Project{}, "test_suite_path"); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
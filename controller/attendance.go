@@ -1,7 +1,9 @@
 package controller
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nadyafa/go-learn/middleware"
@@ -13,6 +15,10 @@ type AttendController interface {
 	StudentAttendClass(ctx *gin.Context)
 	GetClassAttendances(ctx *gin.Context)
 	DeleteAttendanceByID(ctx *gin.Context)
+	GetCheckinCode(ctx *gin.Context)
+	GetCheckinQR(ctx *gin.Context)
+	StudentCheckin(ctx *gin.Context)
+	BulkUpdateAttendances(ctx *gin.Context)
 }
 
 type AttendControllerImpl struct {
@@ -25,7 +31,7 @@ func NewAttendController(attendService service.AttendService) AttendController {
 	}
 }
 
-// create course (admin & student)
+// record attendance manually (admin & mentor)
 func (c *AttendControllerImpl) StudentAttendClass(ctx *gin.Context) {
 	// check if the currentUser is admin
 	claims, _ := ctx.Get("currentUser")
@@ -72,7 +78,8 @@ func (c *AttendControllerImpl) StudentAttendClass(ctx *gin.Context) {
 		ClassID:   attend.ClassID,
 		CourseID:  attend.CourseID,
 		Attended:  attend.Attended,
-		AttendAt:  attend.AttendAt,
+		Status:    string(attend.Status),
+		AttendAt:  middleware.LocalTime(ctx, attend.AttendAt),
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{
@@ -119,7 +126,8 @@ func (c *AttendControllerImpl) GetClassAttendances(ctx *gin.Context) {
 			ClassID:   attend.ClassID,
 			CourseID:  attend.CourseID,
 			Attended:  attend.Attended,
-			AttendAt:  attend.AttendAt,
+			Status:    string(attend.Status),
+			AttendAt:  middleware.LocalTime(ctx, attend.AttendAt),
 		}
 
 		attendResponses = append(attendResponses, attendResp)
@@ -169,3 +177,217 @@ func (c *AttendControllerImpl) DeleteAttendanceByID(ctx *gin.Context) {
 		"code":    http.StatusOK,
 	})
 }
+
+// get current check-in code of a running class (admin & mentor)
+func (c *AttendControllerImpl) GetCheckinCode(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get check-in code",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// get classID param
+	classID := ctx.Param("class_id")
+
+	checkinCode, err := c.attendService.GetCheckinCode(userClaims, courseID, classID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// succeed response
+	checkinResp := model.CheckinCodeResp{
+		ClassID:   checkinCode.ClassID,
+		CourseID:  checkinCode.CourseID,
+		Code:      checkinCode.Code,
+		ExpiresAt: middleware.LocalTime(ctx, checkinCode.ExpiresAt),
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Check-in code fetch successfully",
+		"code":    http.StatusOK,
+		"data":    checkinResp,
+	})
+}
+
+// get current check-in code as QR PNG to display in class (admin & mentor)
+func (c *AttendControllerImpl) GetCheckinQR(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get check-in code",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// get classID param
+	classID := ctx.Param("class_id")
+
+	checkinCode, err := c.attendService.GetCheckinCode(userClaims, courseID, classID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	png, err := middleware.GenerateQRCode(checkinCode.Code, 256)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusInternalServerError,
+		})
+		return
+	}
+
+	// code rotates, so QR must not be cached
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Check-In-Expires-At", middleware.LocalTime(ctx, checkinCode.ExpiresAt).Format(time.RFC3339))
+	ctx.Data(http.StatusOK, "image/png", png)
+}
+
+// check in to a running class with code (student only)
+func (c *AttendControllerImpl) StudentCheckin(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to check in",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// get classID param
+	classID := ctx.Param("class_id")
+
+	// bind json body with model
+	var checkinReq model.CheckinReq
+
+	if err := ctx.ShouldBindJSON(&checkinReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	attend, created, err := c.attendService.StudentCheckin(userClaims, courseID, classID, checkinReq.Code)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	attendResp := model.AttendResp{
+		AttendID:  attend.AttendID,
+		StudentID: attend.StudentID,
+		ClassID:   attend.ClassID,
+		CourseID:  attend.CourseID,
+		Attended:  attend.Attended,
+		Status:    string(attend.Status),
+		AttendAt:  middleware.LocalTime(ctx, attend.AttendAt),
+	}
+
+	if !created {
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("Student already checked in to classID %s", classID),
+			"data":    attendResp,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("Student successfully checked in to classID %s", classID),
+		"data":    attendResp,
+	})
+}
+
+// set attendance status for the whole roster in one request (admin & mentor)
+func (c *AttendControllerImpl) BulkUpdateAttendances(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to update attendances",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// get classID param
+	classID := ctx.Param("class_id")
+
+	// bind json body with model
+	var bulkReq model.BulkAttendReq
+
+	if err := ctx.ShouldBindJSON(&bulkReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	attendances, err := c.attendService.BulkUpdateAttendances(userClaims, courseID, classID, bulkReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// create attendance list response
+	var attendResponses []model.AttendResp
+
+	for _, attend := range attendances {
+		attendResp := model.AttendResp{
+			AttendID:  attend.AttendID,
+			StudentID: attend.StudentID,
+			ClassID:   attend.ClassID,
+			CourseID:  attend.CourseID,
+			Attended:  attend.Attended,
+			Status:    string(attend.Status),
+			AttendAt:  middleware.LocalTime(ctx, attend.AttendAt),
+		}
+
+		attendResponses = append(attendResponses, attendResp)
+	}
+
+	// succeed response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ClassID %s attendances updated successfully", classID),
+		"code":    http.StatusOK,
+		"data":    attendResponses,
+	})
+}
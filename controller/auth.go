@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/middleware"
 	"github.com/nadyafa/go-learn/model"
 	"github.com/nadyafa/go-learn/service"
 )
@@ -13,6 +14,7 @@ type AuthController interface {
 	UserSignup(ctx *gin.Context)
 	UserSignin(ctx *gin.Context)
 	UserSignout(ctx *gin.Context)
+	SetPassword(ctx *gin.Context)
 }
 
 type AuthControllerImpl struct {
@@ -49,8 +51,9 @@ func (c *AuthControllerImpl) UserSignup(ctx *gin.Context) {
 		Username:  user.Username,
 		Email:     user.Email,
 		Role:      string(user.Role),
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		Timezone:  user.Timezone,
+		CreatedAt: middleware.LocalTime(ctx, user.CreatedAt),
+		UpdatedAt: middleware.LocalTime(ctx, user.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{
@@ -96,8 +99,9 @@ func (c *AuthControllerImpl) UserSignin(ctx *gin.Context) {
 		Username:  user.Username,
 		Email:     user.Email,
 		Role:      string(user.Role),
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		Timezone:  user.Timezone,
+		CreatedAt: middleware.LocalTime(ctx, user.CreatedAt),
+		UpdatedAt: middleware.LocalTime(ctx, user.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
@@ -123,3 +127,26 @@ func (c *AuthControllerImpl) UserSignout(ctx *gin.Context) {
 		"message": "User sign out successfully",
 	})
 }
+
+func (c *AuthControllerImpl) SetPassword(ctx *gin.Context) {
+	var setPassword model.SetPassword
+
+	// binding incoming req
+	if err := ctx.ShouldBindJSON(&setPassword); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid input",
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// call setPassword service
+	if err := c.authService.SetPassword(ctx.Query("token"), setPassword); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Password set successfully, please sign in",
+	})
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type AutogradeController interface {
+	RegradeSubmission(ctx *gin.Context)
+	RegradeProject(ctx *gin.Context)
+}
+
+type AutogradeControllerImpl struct {
+	autogradeService service.AutogradeService
+}
+
+func NewAutogradeController(autogradeService service.AutogradeService) AutogradeController {
+	return &AutogradeControllerImpl{
+		autogradeService: autogradeService,
+	}
+}
+
+// queue one submission for the test suite again (admin & mentor)
+func (c *AutogradeControllerImpl) RegradeSubmission(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to regrade a submission",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & projectSubID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	projectSubID := ctx.Param("project_sub_id")
+
+	if err := c.autogradeService.RegradeSubmission(userClaims, courseID, projectID, projectSubID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response, the test run happens in background
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": fmt.Sprintf("ProjectSubID %s queued for grading", projectSubID),
+		"code":    http.StatusAccepted,
+	})
+}
+
+// queue the latest submissions of the project for the test suite again (admin & mentor)
+func (c *AutogradeControllerImpl) RegradeProject(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to regrade submissions",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	queued, err := c.autogradeService.RegradeProject(userClaims, courseID, projectID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response, the test runs happen in background
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": fmt.Sprintf("%d submissions queued for grading", queued),
+		"code":    http.StatusAccepted,
+	})
+}
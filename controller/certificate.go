@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type CertificateController interface {
+	GetCertificatePDF(ctx *gin.Context)
+	VerifyCertificate(ctx *gin.Context)
+	RevokeCertificate(ctx *gin.Context)
+}
+
+type CertificateControllerImpl struct {
+	certificateService service.CertificateService
+}
+
+func NewCertificateController(certificateService service.CertificateService) CertificateController {
+	return &CertificateControllerImpl{
+		certificateService: certificateService,
+	}
+}
+
+func certificateResponse(ctx *gin.Context, certificate *entity.Certificate) model.CertificateVerifyResp {
+	certificateResp := model.CertificateVerifyResp{
+		Serial:       certificate.Serial,
+		Valid:        certificate.RevokedAt == nil,
+		StudentName:  certificate.StudentName,
+		CourseName:   certificate.CourseName,
+		MentorName:   certificate.MentorName,
+		CompletedAt:  middleware.LocalTime(ctx, certificate.CompletedAt),
+		IssuedAt:     middleware.LocalTime(ctx, certificate.CreatedAt),
+		RevokeReason: certificate.RevokeReason,
+	}
+
+	if certificate.RevokedAt != nil {
+		revokedAt := middleware.LocalTime(ctx, *certificate.RevokedAt)
+		certificateResp.RevokedAt = &revokedAt
+	}
+
+	return certificateResp
+}
+
+// download completion certificate (admin, mentor & the student)
+func (c *CertificateControllerImpl) GetCertificatePDF(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get a certificate",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & enrollID param
+	courseID := ctx.Param("course_id")
+	enrollID := ctx.Param("enroll_id")
+
+	certificate, pdf, err := c.certificateService.GetCertificatePDF(userClaims, courseID, enrollID, middleware.UserLocation(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=certificate-%s.pdf", certificate.Serial))
+	ctx.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// confirm certificate authenticity (public)
+func (c *CertificateControllerImpl) VerifyCertificate(ctx *gin.Context) {
+	certificate, err := c.certificateService.VerifyCertificate(ctx.Param("serial"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	message := "Certificate is valid"
+	if certificate.RevokedAt != nil {
+		message = "Certificate has been revoked"
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    certificateResponse(ctx, certificate),
+	})
+}
+
+// revoke certificate (admin only)
+func (c *CertificateControllerImpl) RevokeCertificate(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to revoke a certificate",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var revokeReq model.RevokeCertificateReq
+	if err := ctx.ShouldBindJSON(&revokeReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	certificate, err := c.certificateService.RevokeCertificate(userClaims, ctx.Param("serial"), revokeReq.Reason)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Certificate %s has been revoked", certificate.Serial),
+		"data":    certificateResponse(ctx, certificate),
+	})
+}
@@ -17,6 +17,7 @@ type ClassController interface {
 	GetClassByID(ctx *gin.Context)
 	UpdateClassByID(ctx *gin.Context)
 	DeleteClassByID(ctx *gin.Context)
+	GetClassMeeting(ctx *gin.Context)
 }
 
 type ClassControllerImpl struct {
@@ -72,10 +73,12 @@ func (c *ClassControllerImpl) CreateClass(ctx *gin.Context) {
 		CourseID:    class.CourseID,
 		ClassName:   class.ClassName,
 		Description: class.Description,
-		StartDate:   class.StartDate,
-		EndDate:     class.EndDate,
-		CreatedAt:   class.CreatedAt,
-		UpdatedAt:   class.UpdatedAt,
+		RoomID:      class.RoomID,
+		Online:      class.MeetingURL != "",
+		StartDate:   middleware.LocalTime(ctx, class.StartDate),
+		EndDate:     middleware.LocalTime(ctx, class.EndDate),
+		CreatedAt:   middleware.LocalTime(ctx, class.CreatedAt),
+		UpdatedAt:   middleware.LocalTime(ctx, class.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{
@@ -118,10 +121,12 @@ func (c *ClassControllerImpl) GetClasses(ctx *gin.Context) {
 			CourseID:    class.CourseID,
 			ClassName:   class.ClassName,
 			Description: class.Description,
-			StartDate:   class.StartDate,
-			EndDate:     class.EndDate,
-			CreatedAt:   class.CreatedAt,
-			UpdatedAt:   class.UpdatedAt,
+			RoomID:      class.RoomID,
+			Online:      class.MeetingURL != "",
+			StartDate:   middleware.LocalTime(ctx, class.StartDate),
+			EndDate:     middleware.LocalTime(ctx, class.EndDate),
+			CreatedAt:   middleware.LocalTime(ctx, class.CreatedAt),
+			UpdatedAt:   middleware.LocalTime(ctx, class.UpdatedAt),
 		}
 
 		classResponses = append(classResponses, classResp)
@@ -168,10 +173,12 @@ func (c *ClassControllerImpl) GetClassByID(ctx *gin.Context) {
 		CourseID:    class.CourseID,
 		ClassName:   class.ClassName,
 		Description: class.Description,
-		StartDate:   class.StartDate,
-		EndDate:     class.EndDate,
-		CreatedAt:   class.CreatedAt,
-		UpdatedAt:   class.UpdatedAt,
+		RoomID:      class.RoomID,
+		Online:      class.MeetingURL != "",
+		StartDate:   middleware.LocalTime(ctx, class.StartDate),
+		EndDate:     middleware.LocalTime(ctx, class.EndDate),
+		CreatedAt:   middleware.LocalTime(ctx, class.CreatedAt),
+		UpdatedAt:   middleware.LocalTime(ctx, class.UpdatedAt),
 	}
 
 	// succeed response
@@ -227,10 +234,12 @@ func (c *ClassControllerImpl) UpdateClassByID(ctx *gin.Context) {
 		CourseID:    class.CourseID,
 		ClassName:   class.ClassName,
 		Description: class.Description,
-		StartDate:   class.StartDate,
-		EndDate:     class.EndDate,
-		CreatedAt:   class.CreatedAt,
-		UpdatedAt:   time.Now(),
+		RoomID:      class.RoomID,
+		Online:      class.MeetingURL != "",
+		StartDate:   middleware.LocalTime(ctx, class.StartDate),
+		EndDate:     middleware.LocalTime(ctx, class.EndDate),
+		CreatedAt:   middleware.LocalTime(ctx, class.CreatedAt),
+		UpdatedAt:   middleware.LocalTime(ctx, time.Now()),
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
@@ -274,3 +283,47 @@ func (c *ClassControllerImpl) DeleteClassByID(ctx *gin.Context) {
 		"code":    http.StatusOK,
 	})
 }
+
+// get class meeting link (admin, course mentor & enrolled student)
+func (c *ClassControllerImpl) GetClassMeeting(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get class meeting",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// get classID param
+	classID := ctx.Param("class_id")
+
+	class, err := c.classService.GetClassMeeting(userClaims, courseID, classID)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	// succeed response
+	meetingResp := model.ClassMeetingResp{
+		ClassID:         class.ClassID,
+		MeetingProvider: class.MeetingProvider,
+		MeetingURL:      class.MeetingURL,
+		StartDate:       middleware.LocalTime(ctx, class.StartDate),
+		EndDate:         middleware.LocalTime(ctx, class.EndDate),
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Class meeting fetch successfully",
+		"code":    http.StatusOK,
+		"data":    meetingResp,
+	})
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type CompletionController interface {
+	GetCompletionRule(ctx *gin.Context)
+	SetCompletionRule(ctx *gin.Context)
+	PreviewCompletion(ctx *gin.Context)
+	EvaluateCompletion(ctx *gin.Context)
+}
+
+type CompletionControllerImpl struct {
+	completionService service.CompletionService
+}
+
+func NewCompletionController(completionService service.CompletionService) CompletionController {
+	return &CompletionControllerImpl{
+		completionService: completionService,
+	}
+}
+
+func completionRuleResponse(ctx *gin.Context, rule *entity.CompletionRule) model.CompletionRuleResp {
+	ruleResp := model.CompletionRuleResp{
+		CourseID:           rule.CourseID,
+		MinAttendanceRate:  rule.MinAttendanceRate,
+		MinAverageScore:    rule.MinAverageScore,
+		RequireAllProjects: rule.RequireAllProjects,
+		UpdatedAt:          middleware.LocalTime(ctx, rule.UpdatedAt),
+	}
+
+	if rule.EvaluatedAt != nil {
+		evaluatedAt := middleware.LocalTime(ctx, *rule.EvaluatedAt)
+		ruleResp.EvaluatedAt = &evaluatedAt
+	}
+
+	return ruleResp
+}
+
+// course completion criteria (for all)
+func (c *CompletionControllerImpl) GetCompletionRule(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get completion rules",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	rule, err := c.completionService.GetCompletionRule(userClaims, courseID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Completion rules fetch successfully",
+		"data":    completionRuleResponse(ctx, rule),
+	})
+}
+
+// create or replace course completion criteria (admin & mentor)
+func (c *CompletionControllerImpl) SetCompletionRule(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to set completion rules",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	var ruleReq model.CompletionRuleReq
+	if err := ctx.ShouldBindJSON(&ruleReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	rule, err := c.completionService.SetCompletionRule(userClaims, courseID, ruleReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Completion rules saved successfully",
+		"data":    completionRuleResponse(ctx, rule),
+	})
+}
+
+// outcome of every enrolled student without applying it (admin & mentor)
+func (c *CompletionControllerImpl) PreviewCompletion(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to preview course completion",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	result, err := c.completionService.PreviewCompletion(userClaims, courseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("%d students would complete, %d would fail", result.Completed, result.Failed),
+		"data":    result,
+	})
+}
+
+// move enrolled students to complete or failed (admin & mentor)
+func (c *CompletionControllerImpl) EvaluateCompletion(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to evaluate course completion",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	result, err := c.completionService.EvaluateCompletion(userClaims, courseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("%d students completed, %d failed", result.Completed, result.Failed),
+		"data":    result,
+	})
+}
@@ -64,14 +64,16 @@ func (c *CourseControllerImpl) CreateCourse(ctx *gin.Context) {
 
 	// success response
 	courseResp := model.CourseResp{
-		CourseID:    course.CourseID,
-		CourseName:  course.CourseName,
-		Description: course.Description,
-		MentorID:    course.MentorID,
-		StartDate:   course.StartDate,
-		EndDate:     course.EndDate,
-		CreatedAt:   course.CreatedAt,
-		UpdatedAt:   course.UpdatedAt,
+		CourseID:             course.CourseID,
+		CourseName:           course.CourseName,
+		Description:          course.Description,
+		MentorID:             course.MentorID,
+		LateThresholdMinutes: course.LateThresholdMinutes,
+		DropDeadlineDays:     course.DropDeadlineDays,
+		StartDate:            middleware.LocalTime(ctx, course.StartDate),
+		EndDate:              middleware.LocalTime(ctx, course.EndDate),
+		CreatedAt:            middleware.LocalTime(ctx, course.CreatedAt),
+		UpdatedAt:            middleware.LocalTime(ctx, course.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{
@@ -108,14 +110,16 @@ func (c *CourseControllerImpl) GetCourses(ctx *gin.Context) {
 
 	for _, course := range courses {
 		courseResp := model.CourseResp{
-			CourseID:    course.CourseID,
-			CourseName:  course.CourseName,
-			Description: course.Description,
-			MentorID:    course.MentorID,
-			StartDate:   course.StartDate,
-			EndDate:     course.EndDate,
-			CreatedAt:   course.CreatedAt,
-			UpdatedAt:   course.UpdatedAt,
+			CourseID:             course.CourseID,
+			CourseName:           course.CourseName,
+			Description:          course.Description,
+			MentorID:             course.MentorID,
+			LateThresholdMinutes: course.LateThresholdMinutes,
+			DropDeadlineDays:     course.DropDeadlineDays,
+			StartDate:            middleware.LocalTime(ctx, course.StartDate),
+			EndDate:              middleware.LocalTime(ctx, course.EndDate),
+			CreatedAt:            middleware.LocalTime(ctx, course.CreatedAt),
+			UpdatedAt:            middleware.LocalTime(ctx, course.UpdatedAt),
 		}
 
 		courseResponses = append(courseResponses, courseResp)
@@ -156,14 +160,16 @@ func (c *CourseControllerImpl) GetCourseByID(ctx *gin.Context) {
 
 	// succeed response
 	courseResp := model.CourseResp{
-		CourseID:    course.CourseID,
-		CourseName:  course.CourseName,
-		Description: course.Description,
-		MentorID:    course.MentorID,
-		StartDate:   course.StartDate,
-		EndDate:     course.EndDate,
-		CreatedAt:   course.CreatedAt,
-		UpdatedAt:   course.UpdatedAt,
+		CourseID:             course.CourseID,
+		CourseName:           course.CourseName,
+		Description:          course.Description,
+		MentorID:             course.MentorID,
+		LateThresholdMinutes: course.LateThresholdMinutes,
+		DropDeadlineDays:     course.DropDeadlineDays,
+		StartDate:            middleware.LocalTime(ctx, course.StartDate),
+		EndDate:              middleware.LocalTime(ctx, course.EndDate),
+		CreatedAt:            middleware.LocalTime(ctx, course.CreatedAt),
+		UpdatedAt:            middleware.LocalTime(ctx, course.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
@@ -210,14 +216,16 @@ func (c *CourseControllerImpl) UpdateCourseByID(ctx *gin.Context) {
 
 	// succeed response
 	courseResp := model.CourseResp{
-		CourseID:    course.CourseID,
-		CourseName:  course.CourseName,
-		Description: course.Description,
-		MentorID:    course.MentorID,
-		StartDate:   course.StartDate,
-		EndDate:     course.EndDate,
-		CreatedAt:   course.CreatedAt,
-		UpdatedAt:   course.UpdatedAt,
+		CourseID:             course.CourseID,
+		CourseName:           course.CourseName,
+		Description:          course.Description,
+		MentorID:             course.MentorID,
+		LateThresholdMinutes: course.LateThresholdMinutes,
+		DropDeadlineDays:     course.DropDeadlineDays,
+		StartDate:            middleware.LocalTime(ctx, course.StartDate),
+		EndDate:              middleware.LocalTime(ctx, course.EndDate),
+		CreatedAt:            middleware.LocalTime(ctx, course.CreatedAt),
+		UpdatedAt:            middleware.LocalTime(ctx, course.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
@@ -3,6 +3,10 @@ package controller
 import (
 	"fmt"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nadyafa/go-learn/entity"
@@ -14,6 +18,38 @@ import (
 type EnrollController interface {
 	StudentEnroll(ctx *gin.Context)
 	UpdateStudentEnroll(ctx *gin.Context)
+	GetEnrollHistories(ctx *gin.Context)
+	GetCourseEnrolls(ctx *gin.Context)
+	GetMyEnrolls(ctx *gin.Context)
+	SearchEnrolls(ctx *gin.Context)
+	DropEnroll(ctx *gin.Context)
+	ReviewDropEnroll(ctx *gin.Context)
+	ImportEnrolls(ctx *gin.Context)
+	JoinByInvite(ctx *gin.Context)
+}
+
+func enrollResponse(ctx *gin.Context, enroll *entity.Enrollment) model.EnrollResp {
+	enrollResp := model.EnrollResp{
+		EnrollmentID: enroll.EnrollmentID,
+		StudentID:    enroll.StudentID,
+		CourseID:     enroll.CourseID,
+		EnrollStatus: enroll.EnrollStatus,
+		DropReason:   enroll.DropReason,
+		CreatedAt:    middleware.LocalTime(ctx, enroll.CreatedAt),
+		UpdatedAt:    middleware.LocalTime(ctx, enroll.UpdatedAt),
+	}
+
+	if !enroll.EnrollmentDate.IsZero() {
+		enrollDate := middleware.LocalTime(ctx, enroll.EnrollmentDate)
+		enrollResp.EnrollmentDate = &enrollDate
+	}
+
+	if enroll.DropRequestedAt != nil {
+		dropRequestedAt := middleware.LocalTime(ctx, *enroll.DropRequestedAt)
+		enrollResp.DropRequestedAt = &dropRequestedAt
+	}
+
+	return enrollResp
 }
 
 type EnrollControllerImpl struct {
@@ -43,12 +79,10 @@ func (c *EnrollControllerImpl) StudentEnroll(ctx *gin.Context) {
 	courseID := ctx.Param("course_id")
 
 	// bind json body with model
-	var studentID struct {
-		StudentID uint `json:"student_id"`
-	}
+	var enrollReq model.EnrollReq
 
 	// validate with model req
-	if err := ctx.ShouldBindJSON(&studentID); err != nil {
+	if err := ctx.ShouldBindJSON(&enrollReq); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 			"code":  http.StatusBadRequest,
@@ -57,7 +91,7 @@ func (c *EnrollControllerImpl) StudentEnroll(ctx *gin.Context) {
 	}
 
 	// enroll to a course
-	enroll, err := c.enrollService.StudentEnroll(userClaims, courseID, fmt.Sprint(studentID.StudentID))
+	enroll, err := c.enrollService.StudentEnroll(userClaims, courseID, fmt.Sprint(enrollReq.StudentID), enrollReq.InviteCode)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -67,23 +101,47 @@ func (c *EnrollControllerImpl) StudentEnroll(ctx *gin.Context) {
 	}
 
 	// success response
-	enrollResp := model.EnrollResp{
-		EnrollmentID:   enroll.EnrollmentID,
-		StudentID:      enroll.StudentID,
-		CourseID:       enroll.CourseID,
-		EnrollmentDate: nil,
-		EnrollStatus:   entity.Pending,
-		CreatedAt:      enroll.CreatedAt,
-		UpdatedAt:      enroll.UpdatedAt,
+	message := fmt.Sprintf("UserID %d enrollment request has been sent", enroll.StudentID)
+	if enroll.EnrollStatus == entity.Enroll {
+		message = fmt.Sprintf("UserID %d has joined the course", enroll.StudentID)
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": message,
+		"data":    enrollResponse(ctx, enroll),
+	})
+}
+
+// join a course from invite link (student only)
+func (c *EnrollControllerImpl) JoinByInvite(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "User must sign in to join a course",
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	enroll, err := c.enrollService.JoinByInvite(userClaims, ctx.Param("code"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
 	}
 
+	// success response
 	ctx.JSON(http.StatusCreated, gin.H{
-		"message": fmt.Sprintf("UserID %d enrollment request has been sent", enroll.StudentID),
-		"data":    enrollResp,
+		"message": fmt.Sprintf("UserID %d has joined the course", enroll.StudentID),
+		"data":    enrollResponse(ctx, enroll),
 	})
 }
 
-// admin only
+// admin, course mentor & student (own enrollment) depending on the transition
 func (c *EnrollControllerImpl) UpdateStudentEnroll(ctx *gin.Context) {
 	// check if the user is signed in
 	claims, _ := ctx.Get("currentUser")
@@ -96,14 +154,12 @@ func (c *EnrollControllerImpl) UpdateStudentEnroll(ctx *gin.Context) {
 		return
 	}
 
-	// get courseID
+	// get courseID & enrollID
 	courseID := ctx.Param("course_id")
+	enrollID := ctx.Param("enroll_id")
 
-	// validate role input
-	var enrollReq struct {
-		StudentID    uint   `json:"student_id" validate:"required"`
-		EnrollStatus string `json:"enroll_status" validate:"required"`
-	}
+	// validate status input
+	var enrollReq model.EnrollStatusReq
 
 	if err := ctx.ShouldBindJSON(&enrollReq); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
@@ -114,7 +170,7 @@ func (c *EnrollControllerImpl) UpdateStudentEnroll(ctx *gin.Context) {
 	}
 
 	// update enrollment
-	enroll, err := c.enrollService.UpdateStudentEnroll(userClaims, courseID, fmt.Sprint(enrollReq.StudentID), entity.Status(enrollReq.EnrollStatus))
+	enroll, err := c.enrollService.UpdateStudentEnroll(userClaims, courseID, enrollID, entity.Status(enrollReq.EnrollStatus), enrollReq.Reason)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -124,18 +180,486 @@ func (c *EnrollControllerImpl) UpdateStudentEnroll(ctx *gin.Context) {
 	}
 
 	// success response
-	enrollResp := model.EnrollResp{
-		EnrollmentID:   enroll.EnrollmentID,
-		StudentID:      enroll.StudentID,
-		CourseID:       enroll.CourseID,
-		EnrollmentDate: &enroll.EnrollmentDate,
-		EnrollStatus:   enroll.EnrollStatus,
-		CreatedAt:      enroll.CreatedAt,
-		UpdatedAt:      enroll.UpdatedAt,
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("UserID %d enrollment status updated to %s", enroll.StudentID, enroll.EnrollStatus),
+		"data":    enrollResponse(ctx, enroll),
+	})
+}
+
+// admin, course mentor & the enrolled student
+func (c *EnrollControllerImpl) GetEnrollHistories(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "User must sign in to see enrollment history",
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	// get courseID & enrollID
+	courseID := ctx.Param("course_id")
+	enrollID := ctx.Param("enroll_id")
+
+	histories, err := c.enrollService.GetEnrollHistories(userClaims, courseID, enrollID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	historiesResp := make([]model.EnrollHistoryResp, 0, len(histories))
+	for _, history := range histories {
+		historiesResp = append(historiesResp, model.EnrollHistoryResp{
+			HistoryID:    history.HistoryID,
+			EnrollmentID: history.EnrollmentID,
+			FromStatus:   history.FromStatus,
+			ToStatus:     history.ToStatus,
+			ActorID:      history.ActorID,
+			ActorRole:    history.ActorRole,
+			Reason:       history.Reason,
+			CreatedAt:    middleware.LocalTime(ctx, history.CreatedAt),
+		})
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("UserID %d enrollment request has been sent", enroll.StudentID),
-		"data":    enrollResp,
+		"message": fmt.Sprintf("EnrollmentID %s history fetch successfully", enrollID),
+		"data":    historiesResp,
+	})
+}
+
+var enrollDetailColumns = []middleware.ExportColumn{
+	middleware.NumberColumn("enrollment_id"),
+	middleware.NumberColumn("student_id"),
+	middleware.TextColumn("username"),
+	middleware.TextColumn("email"),
+	middleware.NumberColumn("course_id"),
+	middleware.TextColumn("course_name"),
+	middleware.TextColumn("enrollment_date"),
+	middleware.TextColumn("completion_status"),
+	middleware.TextColumn("created_at"),
+}
+
+// read status, search & pagination query, export ignores pagination
+func enrollFilterQuery(ctx *gin.Context) (model.EnrollFilter, error) {
+	filter := model.EnrollFilter{
+		Status:  entity.Status(ctx.Query("status")),
+		Search:  ctx.Query("q"),
+		Page:    1,
+		PerPage: model.DefaultPerPage,
+	}
+
+	if pageStr := ctx.Query("page"); pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return filter, fmt.Errorf("page must be a positive number")
+		}
+		filter.Page = page
+	}
+
+	if perPageStr := ctx.Query("per_page"); perPageStr != "" {
+		perPage, err := strconv.Atoi(perPageStr)
+		if err != nil || perPage < 1 || perPage > model.MaxPerPage {
+			return filter, fmt.Errorf("per_page must be between 1 and %d", model.MaxPerPage)
+		}
+		filter.PerPage = perPage
+	}
+
+	if ctx.Query("format") != "" {
+		filter.Page = 1
+		filter.PerPage = 0
+	}
+
+	return filter, nil
+}
+
+// respond with a page of enrollments, or export file when format query given
+func writeEnrolls(ctx *gin.Context, fileName, message string, enrolls []model.EnrollDetail, total int64, filter model.EnrollFilter) {
+	if format := ctx.Query("format"); format != "" {
+		var rows [][]string
+		for _, enroll := range enrolls {
+			enrollDate := ""
+			if !enroll.EnrollmentDate.IsZero() {
+				enrollDate = middleware.LocalTime(ctx, enroll.EnrollmentDate).Format(time.RFC3339)
+			}
+
+			rows = append(rows, []string{
+				fmt.Sprint(enroll.EnrollmentID),
+				fmt.Sprint(enroll.StudentID),
+				enroll.Username,
+				enroll.Email,
+				fmt.Sprint(enroll.CourseID),
+				enroll.CourseName,
+				enrollDate,
+				string(enroll.EnrollStatus),
+				middleware.LocalTime(ctx, enroll.CreatedAt).Format(time.RFC3339),
+			})
+		}
+
+		if err := middleware.WriteExport(ctx, format, fileName, enrollDetailColumns, rows); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+				"code":  http.StatusBadRequest,
+			})
+		}
+		return
+	}
+
+	enrollsResp := make([]model.EnrollDetailResp, 0, len(enrolls))
+	for _, enroll := range enrolls {
+		enrollResp := model.EnrollDetailResp{
+			EnrollmentID: enroll.EnrollmentID,
+			StudentID:    enroll.StudentID,
+			Username:     enroll.Username,
+			Email:        enroll.Email,
+			CourseID:     enroll.CourseID,
+			CourseName:   enroll.CourseName,
+			EnrollStatus: enroll.EnrollStatus,
+			CreatedAt:    middleware.LocalTime(ctx, enroll.CreatedAt),
+		}
+
+		if !enroll.EnrollmentDate.IsZero() {
+			enrollDate := middleware.LocalTime(ctx, enroll.EnrollmentDate)
+			enrollResp.EnrollmentDate = &enrollDate
+		}
+
+		enrollsResp = append(enrollsResp, enrollResp)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":    message,
+		"code":       http.StatusOK,
+		"data":       enrollsResp,
+		"pagination": model.NewPagination(filter.Page, filter.PerPage, total),
+	})
+}
+
+// course roster (admin & course mentor)
+func (c *EnrollControllerImpl) GetCourseEnrolls(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "User must sign in to see course enrollments",
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	// get courseID
+	courseID := ctx.Param("course_id")
+
+	filter, err := enrollFilterQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	enrolls, total, err := c.enrollService.GetCourseEnrolls(userClaims, courseID, filter)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	writeEnrolls(ctx, fmt.Sprintf("course-%s-enrollments", courseID), "Course enrollments fetch successfully", enrolls, total, filter)
+}
+
+// enrollments of the signed in user (for all)
+func (c *EnrollControllerImpl) GetMyEnrolls(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "User must sign in to see their enrollments",
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	filter, err := enrollFilterQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	enrolls, total, err := c.enrollService.GetMyEnrolls(userClaims, filter)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	writeEnrolls(ctx, fmt.Sprintf("user-%d-enrollments", userClaims.UserID), "Enrollments fetch successfully", enrolls, total, filter)
+}
+
+// search enrollments across courses (admin only)
+func (c *EnrollControllerImpl) SearchEnrolls(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "User must sign in to search enrollments",
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	filter, err := enrollFilterQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// optional course & student filter
+	if courseIDStr := ctx.Query("course_id"); courseIDStr != "" {
+		courseID, err := strconv.ParseUint(courseIDStr, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "course_id must be a number",
+				"code":  http.StatusBadRequest,
+			})
+			return
+		}
+		filter.CourseID = uint(courseID)
+	}
+
+	if studentIDStr := ctx.Query("student_id"); studentIDStr != "" {
+		studentID, err := strconv.ParseUint(studentIDStr, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "student_id must be a number",
+				"code":  http.StatusBadRequest,
+			})
+			return
+		}
+		filter.StudentID = uint(studentID)
+	}
+
+	enrolls, total, err := c.enrollService.SearchEnrolls(userClaims, filter)
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	writeEnrolls(ctx, "enrollments", "Enrollments fetch successfully", enrolls, total, filter)
+}
+
+// student drops their own enrollment
+func (c *EnrollControllerImpl) DropEnroll(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "User must sign in to drop a course",
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	// get courseID & enrollID
+	courseID := ctx.Param("course_id")
+	enrollID := ctx.Param("enroll_id")
+
+	// body is optional, reason is only required after the drop deadline
+	var dropReq model.DropEnrollReq
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&dropReq); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+				"code":  http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	enroll, dropped, err := c.enrollService.DropEnroll(userClaims, courseID, enrollID, dropReq.Reason)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	if !dropped {
+		ctx.JSON(http.StatusAccepted, gin.H{
+			"message": "Drop deadline has passed, your drop request is waiting for admin approval",
+			"data":    enrollResponse(ctx, enroll),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("EnrollmentID %d has been dropped", enroll.EnrollmentID),
+		"data":    enrollResponse(ctx, enroll),
+	})
+}
+
+// approve or reject a late drop request (admin only)
+func (c *EnrollControllerImpl) ReviewDropEnroll(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "User must sign in to review drop requests",
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	// get courseID & enrollID
+	courseID := ctx.Param("course_id")
+	enrollID := ctx.Param("enroll_id")
+
+	var reviewReq model.ReviewDropReq
+	if err := ctx.ShouldBindJSON(&reviewReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	enroll, err := c.enrollService.ReviewDropEnroll(userClaims, courseID, enrollID, *reviewReq.Approve, reviewReq.Reason)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	message := fmt.Sprintf("EnrollmentID %d drop request has been rejected", enroll.EnrollmentID)
+	if *reviewReq.Approve {
+		message = fmt.Sprintf("EnrollmentID %d has been dropped", enroll.EnrollmentID)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    enrollResponse(ctx, enroll),
+	})
+}
+
+// enroll students from csv file (admin only)
+func (c *EnrollControllerImpl) ImportEnrolls(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "User must sign in to import enrollments",
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+
+	// get courseID
+	courseID := ctx.Param("course_id")
+
+	// bind form body with model
+	var importReq model.EnrollImportReq
+	if err := ctx.ShouldBind(&importReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "csv file is required",
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	if strings.ToLower(filepath.Ext(file.Filename)) != ".csv" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "file must be a .csv",
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	if file.Size > middleware.MaxFileSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "file size must be less than 10 mb",
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	openFile, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+	defer openFile.Close()
+
+	result, err := c.enrollService.ImportEnrolls(userClaims, courseID, openFile, entity.Status(importReq.EnrollStatus), importReq.DryRun)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// invalid rows abort the whole import
+	if result.InvalidRows > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": fmt.Sprintf("%d of %d rows are invalid, nothing was imported", result.InvalidRows, result.TotalRows),
+			"code":  http.StatusUnprocessableEntity,
+			"data":  result,
+		})
+		return
+	}
+
+	// success response
+	if result.DryRun {
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": fmt.Sprintf("All %d rows are valid, nothing was imported on dry run", result.TotalRows),
+			"data":    result,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("%d students enrolled, %d users created", result.Enrolled, result.UsersCreated),
+		"data":    result,
 	})
 }
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type ExcuseController interface {
+	SubmitExcuse(ctx *gin.Context)
+	GetClassExcuses(ctx *gin.Context)
+	ReviewExcuse(ctx *gin.Context)
+	DownloadExcuseDocument(ctx *gin.Context)
+}
+
+type ExcuseControllerImpl struct {
+	excuseService service.ExcuseService
+}
+
+func NewExcuseController(excuseService service.ExcuseService) ExcuseController {
+	return &ExcuseControllerImpl{
+		excuseService: excuseService,
+	}
+}
+
+func excuseResponse(ctx *gin.Context, excuse *entity.Excuse) model.ExcuseResp {
+	excuseResp := model.ExcuseResp{
+		ExcuseID:     excuse.ExcuseID,
+		StudentID:    excuse.StudentID,
+		ClassID:      excuse.ClassID,
+		CourseID:     excuse.CourseID,
+		Reason:       excuse.Reason,
+		DocumentPath: excuse.DocumentPath,
+		ScanStatus:   excuse.ScanStatus,
+		Status:       string(excuse.Status),
+		ReviewerID:   excuse.ReviewerID,
+		ReviewNote:   excuse.ReviewNote,
+		CreatedAt:    middleware.LocalTime(ctx, excuse.CreatedAt),
+		UpdatedAt:    middleware.LocalTime(ctx, excuse.UpdatedAt),
+	}
+
+	if excuse.ReviewedAt != nil {
+		reviewedAt := middleware.LocalTime(ctx, *excuse.ReviewedAt)
+		excuseResp.ReviewedAt = &reviewedAt
+	}
+
+	return excuseResp
+}
+
+// submit absence excuse with optional document (student only)
+func (c *ExcuseControllerImpl) SubmitExcuse(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to submit an excuse",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// get classID param
+	classID := ctx.Param("class_id")
+
+	// bind form body with model
+	var excuseReq model.ExcuseReq
+
+	if err := ctx.ShouldBind(&excuseReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// document is optional, type & size are checked while it's streamed to the storage
+	var upload *model.FileUpload
+	file, err := ctx.FormFile("document")
+	if err == nil {
+		openFile, err := file.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		defer openFile.Close()
+
+		upload = &model.FileUpload{
+			File: openFile,
+			Name: file.Filename,
+			Size: file.Size,
+		}
+	} else if middleware.IsUploadTooLarge(err) {
+		formFileError(ctx, err)
+		return
+	}
+
+	excuse, err := c.excuseService.SubmitExcuse(userClaims, courseID, classID, excuseReq.Reason, upload)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("ExcuseID %d submitted successfully", excuse.ExcuseID),
+		"data":    excuseResponse(ctx, excuse),
+	})
+}
+
+// get class excuses (admin & mentor see all, student see their own)
+func (c *ExcuseControllerImpl) GetClassExcuses(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get excuse lists",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// get classID param
+	classID := ctx.Param("class_id")
+
+	excuses, err := c.excuseService.GetClassExcuses(userClaims, courseID, classID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// create excuse list response
+	var excuseResponses []model.ExcuseResp
+
+	for _, excuse := range excuses {
+		excuseResponses = append(excuseResponses, excuseResponse(ctx, &excuse))
+	}
+
+	// succeed response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Excuse lists fetch successfully",
+		"code":    http.StatusOK,
+		"data":    excuseResponses,
+	})
+}
+
+// approve or reject excuse (admin & mentor)
+func (c *ExcuseControllerImpl) ReviewExcuse(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to review an excuse",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// get classID param
+	classID := ctx.Param("class_id")
+
+	// get excuseID param
+	excuseID := ctx.Param("excuse_id")
+
+	// bind json body with model
+	var reviewReq model.ReviewExcuseReq
+
+	if err := ctx.ShouldBindJSON(&reviewReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	excuse, err := c.excuseService.ReviewExcuse(userClaims, courseID, classID, excuseID, reviewReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// succeed response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ExcuseID %s has been %s", excuseID, excuse.Status),
+		"code":    http.StatusOK,
+		"data":    excuseResponse(ctx, excuse),
+	})
+}
+
+// download link of the supporting document (admin, course mentor & the student who submitted it)
+func (c *ExcuseControllerImpl) DownloadExcuseDocument(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to download an excuse document",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, classID & excuseID param
+	courseID := ctx.Param("course_id")
+	classID := ctx.Param("class_id")
+	excuseID := ctx.Param("excuse_id")
+
+	link, err := c.excuseService.GetExcuseDocument(userClaims, courseID, classID, excuseID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Download link created successfully",
+		"code":    http.StatusOK,
+		"data":    fileURLResponse(ctx, link),
+	})
+}
@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type ExtensionController interface {
+	GrantExtension(ctx *gin.Context)
+	GetExtensions(ctx *gin.Context)
+	RevokeExtension(ctx *gin.Context)
+}
+
+type ExtensionControllerImpl struct {
+	extensionService service.ExtensionService
+}
+
+func NewExtensionController(extensionService service.ExtensionService) ExtensionController {
+	return &ExtensionControllerImpl{
+		extensionService: extensionService,
+	}
+}
+
+func extensionResponse(ctx *gin.Context, extension *entity.DeadlineExtension) model.ExtensionResp {
+	return model.ExtensionResp{
+		ExtensionID: extension.ExtensionID,
+		ProjectID:   extension.ProjectID,
+		StudentID:   extension.StudentID,
+		Deadline:    middleware.LocalTime(ctx, extension.Deadline),
+		Reason:      extension.Reason,
+		GrantedBy:   extension.GrantedBy,
+		CreatedAt:   middleware.LocalTime(ctx, extension.CreatedAt),
+		UpdatedAt:   middleware.LocalTime(ctx, extension.UpdatedAt),
+	}
+}
+
+// grant deadline extension to a student (admin & mentor)
+func (c *ExtensionControllerImpl) GrantExtension(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to grant an extension",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	var extensionReq model.ExtensionReq
+	if err := ctx.ShouldBindJSON(&extensionReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	extension, err := c.extensionService.GrantExtension(userClaims, courseID, projectID, extensionReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Deadline of StudentID %d extended successfully", extension.StudentID),
+		"data":    extensionResponse(ctx, extension),
+	})
+}
+
+// get project extensions (for all, student only sees their own)
+func (c *ExtensionControllerImpl) GetExtensions(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get extensions",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	extensions, err := c.extensionService.GetExtensions(userClaims, courseID, projectID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	extensionsResp := make([]model.ExtensionResp, 0, len(extensions))
+	for i := range extensions {
+		extensionsResp = append(extensionsResp, extensionResponse(ctx, &extensions[i]))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Extensions fetch successfully",
+		"code":    http.StatusOK,
+		"data":    extensionsResp,
+	})
+}
+
+// revoke deadline extension (admin & mentor)
+func (c *ExtensionControllerImpl) RevokeExtension(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to revoke an extension",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & extensionID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	extensionID := ctx.Param("extension_id")
+
+	if err := c.extensionService.RevokeExtension(userClaims, courseID, projectID, extensionID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ExtensionID %s has been revoked", extensionID),
+	})
+}
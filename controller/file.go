@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/service"
+	"github.com/nadyafa/go-learn/storage"
+)
+
+type FileController interface {
+	DownloadFile(ctx *gin.Context)
+}
+
+type FileControllerImpl struct {
+	fileService service.FileService
+}
+
+func NewFileController(fileService service.FileService) FileController {
+	return &FileControllerImpl{
+		fileService: fileService,
+	}
+}
+
+// download through a signed link, the link itself is the authorization (for all)
+func (c *FileControllerImpl) DownloadFile(ctx *gin.Context) {
+	key := ctx.Query("key")
+	fileName := ctx.Query("name")
+
+	object, err := c.fileService.GetSignedFile(key, fileName, ctx.Query("expires"), ctx.Query("signature"))
+	if errors.Is(err, storage.ErrNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": "file not found",
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusForbidden,
+		})
+		return
+	}
+	defer object.Body.Close()
+
+	// browsers must not guess a type, uploads could hold html or scripts
+	ctx.DataFromReader(http.StatusOK, object.Size, object.ContentType, object.Body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": fileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
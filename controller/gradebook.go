@@ -0,0 +1,459 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type GradebookController interface {
+	CreateCategory(ctx *gin.Context)
+	GetCategories(ctx *gin.Context)
+	UpdateCategory(ctx *gin.Context)
+	DeleteCategory(ctx *gin.Context)
+	GetGradeScale(ctx *gin.Context)
+	SaveGradeScale(ctx *gin.Context)
+	ExcuseProject(ctx *gin.Context)
+	UnexcuseProject(ctx *gin.Context)
+	GetGradebook(ctx *gin.Context)
+	GetMyGrades(ctx *gin.Context)
+}
+
+type GradebookControllerImpl struct {
+	gradebookService service.GradebookService
+}
+
+func NewGradebookController(gradebookService service.GradebookService) GradebookController {
+	return &GradebookControllerImpl{
+		gradebookService: gradebookService,
+	}
+}
+
+func gradeCategoryResponse(ctx *gin.Context, category *entity.GradeCategory) model.GradeCategoryResp {
+	return model.GradeCategoryResp{
+		CategoryID: category.CategoryID,
+		CourseID:   category.CourseID,
+		Name:       category.Name,
+		Weight:     category.Weight,
+		DropLowest: category.DropLowest,
+		CreatedAt:  middleware.LocalTime(ctx, category.CreatedAt),
+		UpdatedAt:  middleware.LocalTime(ctx, category.UpdatedAt),
+	}
+}
+
+func gradeScaleResponse(letters []entity.GradeLetter) []model.GradeLetterResp {
+	scaleResp := make([]model.GradeLetterResp, 0, len(letters))
+	for _, letter := range letters {
+		scaleResp = append(scaleResp, model.GradeLetterResp{
+			Letter:   letter.Letter,
+			MinScore: letter.MinScore,
+		})
+	}
+
+	return scaleResp
+}
+
+func formatGrade(score *float64) string {
+	if score == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*score, 'f', 2, 64)
+}
+
+// one row per student, project cell has the score or the reason it has none
+func gradebookExport(gradebook *model.Gradebook) ([]middleware.ExportColumn, [][]string) {
+	columns := []middleware.ExportColumn{middleware.NumberColumn("student_id"), middleware.TextColumn("username"), middleware.TextColumn("email")}
+	for _, project := range gradebook.Projects {
+		columns = append(columns, middleware.NumberColumn(project.ProjectName))
+	}
+
+	if len(gradebook.Students) > 0 {
+		for _, category := range gradebook.Students[0].Categories {
+			columns = append(columns, middleware.NumberColumn(fmt.Sprintf("%s (%.2f%%)", category.Name, category.Weight)))
+		}
+	}
+	columns = append(columns, middleware.NumberColumn("total"), middleware.TextColumn("letter"))
+
+	var rows [][]string
+	for _, student := range gradebook.Students {
+		row := []string{fmt.Sprint(student.StudentID), student.Username, student.Email}
+
+		for _, item := range student.Items {
+			cell := formatGrade(item.Score)
+			switch {
+			case item.Status != model.GradeGraded && item.Status != model.GradeMissing:
+				cell = string(item.Status)
+			case item.Dropped:
+				cell += " (dropped)"
+			}
+			row = append(row, cell)
+		}
+
+		for _, category := range student.Categories {
+			row = append(row, formatGrade(category.Average))
+		}
+
+		rows = append(rows, append(row, formatGrade(student.Total), student.Letter))
+	}
+
+	return columns, rows
+}
+
+// add weighted category (admin & mentor)
+func (c *GradebookControllerImpl) CreateCategory(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to create a grade category",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	var categoryReq model.GradeCategoryReq
+	if err := ctx.ShouldBindJSON(&categoryReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	category, err := c.gradebookService.CreateCategory(userClaims, courseID, categoryReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("Grade category %s created successfully", category.Name),
+		"data":    gradeCategoryResponse(ctx, category),
+	})
+}
+
+// get course grade categories (for all)
+func (c *GradebookControllerImpl) GetCategories(ctx *gin.Context) {
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	categories, err := c.gradebookService.GetCategories(courseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	categoriesResp := make([]model.GradeCategoryResp, 0, len(categories))
+	for i := range categories {
+		categoriesResp = append(categoriesResp, gradeCategoryResponse(ctx, &categories[i]))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Grade categories fetch successfully",
+		"code":    http.StatusOK,
+		"data":    categoriesResp,
+	})
+}
+
+// update grade category (admin & mentor)
+func (c *GradebookControllerImpl) UpdateCategory(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to update a grade category",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & categoryID param
+	courseID := ctx.Param("course_id")
+	categoryID := ctx.Param("category_id")
+
+	var categoryReq model.GradeCategoryReq
+	if err := ctx.ShouldBindJSON(&categoryReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	category, err := c.gradebookService.UpdateCategory(userClaims, courseID, categoryID, categoryReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("CategoryID %s updated successfully", categoryID),
+		"data":    gradeCategoryResponse(ctx, category),
+	})
+}
+
+// delete grade category, its projects become uncategorized (admin & mentor)
+func (c *GradebookControllerImpl) DeleteCategory(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to delete a grade category",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & categoryID param
+	courseID := ctx.Param("course_id")
+	categoryID := ctx.Param("category_id")
+
+	if err := c.gradebookService.DeleteCategory(userClaims, courseID, categoryID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("CategoryID %s has been deleted", categoryID),
+	})
+}
+
+// get letter grade scale (for all)
+func (c *GradebookControllerImpl) GetGradeScale(ctx *gin.Context) {
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	letters, err := c.gradebookService.GetGradeScale(courseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Grade scale fetch successfully",
+		"code":    http.StatusOK,
+		"data":    gradeScaleResponse(letters),
+	})
+}
+
+// replace letter grade scale (admin & mentor)
+func (c *GradebookControllerImpl) SaveGradeScale(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to update the grade scale",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	var scaleReq model.GradeScaleReq
+	if err := ctx.ShouldBindJSON(&scaleReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	letters, err := c.gradebookService.SaveGradeScale(userClaims, courseID, scaleReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Grade scale updated successfully",
+		"data":    gradeScaleResponse(letters),
+	})
+}
+
+// leave a project out of a student's grade (admin & mentor)
+func (c *GradebookControllerImpl) ExcuseProject(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to excuse a project",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	var excusalReq model.GradeExcusalReq
+	if err := ctx.ShouldBindJSON(&excusalReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	excusal, err := c.gradebookService.ExcuseProject(userClaims, courseID, projectID, excusalReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ProjectID %s excused for StudentID %d", projectID, excusal.StudentID),
+		"data": model.GradeExcusalResp{
+			ExcusalID: excusal.ExcusalID,
+			ProjectID: excusal.ProjectID,
+			StudentID: excusal.StudentID,
+			Reason:    excusal.Reason,
+			ExcusedBy: excusal.ExcusedBy,
+			CreatedAt: middleware.LocalTime(ctx, excusal.CreatedAt),
+		},
+	})
+}
+
+// count the project in a student's grade again (admin & mentor)
+func (c *GradebookControllerImpl) UnexcuseProject(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to remove an excusal",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & studentID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	studentID := ctx.Param("student_id")
+
+	if err := c.gradebookService.UnexcuseProject(userClaims, courseID, projectID, studentID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ProjectID %s counts for StudentID %s again", projectID, studentID),
+	})
+}
+
+// grade grid of every enrolled student, ?format=csv|xlsx to export (admin & mentor)
+func (c *GradebookControllerImpl) GetGradebook(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get the gradebook",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	gradebook, err := c.gradebookService.GetGradebook(userClaims, courseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	columns, rows := gradebookExport(gradebook)
+	writeReport(ctx, fmt.Sprintf("course-%s-gradebook", courseID), "Gradebook fetch successfully", gradebook, columns, rows)
+}
+
+// student's own grades (student only)
+func (c *GradebookControllerImpl) GetMyGrades(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get their grades",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	gradebook, err := c.gradebookService.GetMyGrades(userClaims, courseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Grades fetch successfully",
+		"code":    http.StatusOK,
+		"data": gin.H{
+			"course_id": gradebook.CourseID,
+			"scale":     gradebook.Scale,
+			"projects":  gradebook.Projects,
+			"grades":    gradebook.Students[0],
+		},
+	})
+}
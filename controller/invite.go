@@ -0,0 +1,218 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type InviteController interface {
+	CreateInvite(ctx *gin.Context)
+	GetCourseInvites(ctx *gin.Context)
+	GetInviteByID(ctx *gin.Context)
+	RevokeInvite(ctx *gin.Context)
+}
+
+type InviteControllerImpl struct {
+	inviteService service.InviteService
+}
+
+func NewInviteController(inviteService service.InviteService) InviteController {
+	return &InviteControllerImpl{
+		inviteService: inviteService,
+	}
+}
+
+func inviteResponse(ctx *gin.Context, invite *entity.CourseInvite) model.InviteResp {
+	inviteResp := model.InviteResp{
+		InviteID:    invite.InviteID,
+		CourseID:    invite.CourseID,
+		Code:        invite.Code,
+		Link:        middleware.InviteLink(invite.Code),
+		Status:      invite.Status(time.Now()),
+		CreatedBy:   invite.CreatedBy,
+		MaxUses:     invite.MaxUses,
+		UsedCount:   invite.UsedCount,
+		EmailDomain: invite.EmailDomain,
+		CreatedAt:   middleware.LocalTime(ctx, invite.CreatedAt),
+		UpdatedAt:   middleware.LocalTime(ctx, invite.UpdatedAt),
+	}
+
+	if invite.MaxUses > 0 {
+		remainingUses := max(invite.MaxUses-invite.UsedCount, 0)
+		inviteResp.RemainingUses = &remainingUses
+	}
+
+	if invite.ExpiresAt != nil {
+		expiresAt := middleware.LocalTime(ctx, *invite.ExpiresAt)
+		inviteResp.ExpiresAt = &expiresAt
+	}
+
+	if invite.RevokedAt != nil {
+		revokedAt := middleware.LocalTime(ctx, *invite.RevokedAt)
+		inviteResp.RevokedAt = &revokedAt
+	}
+
+	for _, redemption := range invite.Redemptions {
+		inviteResp.Redemptions = append(inviteResp.Redemptions, model.InviteRedemptionResp{
+			StudentID:    redemption.StudentID,
+			EnrollmentID: redemption.EnrollmentID,
+			CreatedAt:    middleware.LocalTime(ctx, redemption.CreatedAt),
+		})
+	}
+
+	return inviteResp
+}
+
+// create course invite code (admin & mentor)
+func (c *InviteControllerImpl) CreateInvite(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to create an invite code",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// every field is optional
+	var inviteReq model.InviteReq
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&inviteReq); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+				"code":  http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	invite, err := c.inviteService.CreateInvite(userClaims, courseID, inviteReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("Invite code %s created successfully", invite.Code),
+		"data":    inviteResponse(ctx, invite),
+	})
+}
+
+// list course invite codes with usage (admin & mentor)
+func (c *InviteControllerImpl) GetCourseInvites(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get invite codes",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	invites, err := c.inviteService.GetCourseInvites(userClaims, courseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	invitesResp := make([]model.InviteResp, 0, len(invites))
+	for i := range invites {
+		invitesResp = append(invitesResp, inviteResponse(ctx, &invites[i]))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Invite codes fetch successfully",
+		"data":    invitesResp,
+	})
+}
+
+// invite code with its redemptions (admin & mentor)
+func (c *InviteControllerImpl) GetInviteByID(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get invite code",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & inviteID param
+	courseID := ctx.Param("course_id")
+	inviteID := ctx.Param("invite_id")
+
+	invite, err := c.inviteService.GetInviteByID(userClaims, courseID, inviteID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Invite code fetch successfully",
+		"data":    inviteResponse(ctx, invite),
+	})
+}
+
+// revoke invite code (admin & mentor)
+func (c *InviteControllerImpl) RevokeInvite(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to revoke invite code",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & inviteID param
+	courseID := ctx.Param("course_id")
+	inviteID := ctx.Param("invite_id")
+
+	invite, err := c.inviteService.RevokeInvite(userClaims, courseID, inviteID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Invite code %s has been revoked", invite.Code),
+		"data":    inviteResponse(ctx, invite),
+	})
+}
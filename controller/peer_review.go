@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type PeerReviewController interface {
+	AssignReviews(ctx *gin.Context)
+	GetReviews(ctx *gin.Context)
+	SubmitReview(ctx *gin.Context)
+	RateReview(ctx *gin.Context)
+	DownloadReviewFile(ctx *gin.Context)
+}
+
+type PeerReviewControllerImpl struct {
+	peerReviewService service.PeerReviewService
+}
+
+func NewPeerReviewController(peerReviewService service.PeerReviewService) PeerReviewController {
+	return &PeerReviewControllerImpl{
+		peerReviewService: peerReviewService,
+	}
+}
+
+// anonymous review hides the reviewer & mentor rating from the reviewed student
+func peerReviewResponse(ctx *gin.Context, review *entity.PeerReview, anonymous bool) model.PeerReviewResp {
+	var grades []model.CriterionGradeResp
+	for _, grade := range review.Grades {
+		grades = append(grades, model.CriterionGradeResp{
+			CriterionID: grade.CriterionID,
+			LevelID:     grade.LevelID,
+			Points:      grade.Points,
+			Comment:     grade.Comment,
+		})
+	}
+
+	reviewResp := model.PeerReviewResp{
+		ReviewID:     review.ReviewID,
+		ProjectID:    review.ProjectID,
+		ProjectSubID: review.ProjectSubID,
+		Score:        review.Score,
+		Comment:      review.Comment,
+		Grades:       grades,
+		SubmittedAt:  middleware.LocalTimePtr(ctx, review.SubmittedAt),
+	}
+
+	if !anonymous {
+		reviewResp.ReviewerID = &review.ReviewerID
+		reviewResp.Quality = review.Quality
+		reviewResp.QualityNote = review.QualityNote
+	}
+
+	return reviewResp
+}
+
+// assign reviewers to every submission (admin & mentor)
+func (c *PeerReviewControllerImpl) AssignReviews(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to assign peer reviews",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	reviews, err := c.peerReviewService.AssignReviews(userClaims, courseID, projectID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	reviewsResp := make([]model.PeerReviewResp, 0, len(reviews))
+	for i := range reviews {
+		reviewsResp = append(reviewsResp, peerReviewResponse(ctx, &reviews[i], false))
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("%d peer reviews assigned successfully", len(reviews)),
+		"data":    reviewsResp,
+	})
+}
+
+// get peer reviews (for all, student only the ones assigned to them)
+func (c *PeerReviewControllerImpl) GetReviews(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get peer reviews",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	reviews, err := c.peerReviewService.GetReviews(userClaims, courseID, projectID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	reviewsResp := make([]model.PeerReviewResp, 0, len(reviews))
+	for i := range reviews {
+		reviewsResp = append(reviewsResp, peerReviewResponse(ctx, &reviews[i], false))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Peer reviews fetch successfully",
+		"code":    http.StatusOK,
+		"data":    reviewsResp,
+	})
+}
+
+// fill in an assigned review (student only)
+func (c *PeerReviewControllerImpl) SubmitReview(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to submit a peer review",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & reviewID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	reviewID := ctx.Param("review_id")
+
+	var reviewReq model.PeerReviewReq
+	if err := ctx.ShouldBindJSON(&reviewReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	review, err := c.peerReviewService.SubmitReview(userClaims, courseID, projectID, reviewID, reviewReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ReviewID %s submitted successfully", reviewID),
+		"data":    peerReviewResponse(ctx, review, false),
+	})
+}
+
+// rate the quality of a review (admin & mentor)
+func (c *PeerReviewControllerImpl) RateReview(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to rate a peer review",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & reviewID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	reviewID := ctx.Param("review_id")
+
+	var qualityReq model.PeerReviewQualityReq
+	if err := ctx.ShouldBindJSON(&qualityReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	review, err := c.peerReviewService.RateReview(userClaims, courseID, projectID, reviewID, qualityReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ReviewID %s rated successfully", reviewID),
+		"data":    peerReviewResponse(ctx, review, false),
+	})
+}
+
+// stream the reviewed submission under a name that doesn't reveal its author (for all, student only their assigned reviews)
+func (c *PeerReviewControllerImpl) DownloadReviewFile(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to download a reviewed submission",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & reviewID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	reviewID := ctx.Param("review_id")
+
+	link, err := c.peerReviewService.GetReviewFile(userClaims, courseID, projectID, reviewID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Download link created successfully",
+		"code":    http.StatusOK,
+		"data":    fileURLResponse(ctx, link),
+	})
+}
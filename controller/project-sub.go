@@ -2,36 +2,78 @@ package controller
 
 import (
 	"fmt"
-	"io"
 	"net/http"
-	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nadyafa/go-learn/entity"
 	"github.com/nadyafa/go-learn/middleware"
 	"github.com/nadyafa/go-learn/model"
-	"gorm.io/gorm"
+	"github.com/nadyafa/go-learn/service"
+	"github.com/nadyafa/go-learn/storage"
 )
 
 type ProjectSubController interface {
 	StudentSubmitProject(ctx *gin.Context)
 	MentorSubmitScore(ctx *gin.Context)
+	GetProjectSubmissions(ctx *gin.Context)
+	GetProjectSubmissionByID(ctx *gin.Context)
+	GetProjectSubmissionHistory(ctx *gin.Context)
+	DeleteProjectSubmissionByID(ctx *gin.Context)
+	GetSubmissionPeerReviews(ctx *gin.Context)
+	GetSubmissionTestResults(ctx *gin.Context)
+	DownloadProjectSubmission(ctx *gin.Context)
 }
 
 type ProjectSubControllerImpl struct {
-	db *gorm.DB
+	projectSubService service.ProjectSubService
 }
 
-func NewProjectSubController(db *gorm.DB) ProjectSubController {
+func NewProjectSubController(projectSubService service.ProjectSubService) ProjectSubController {
 	return &ProjectSubControllerImpl{
-		db: db,
+		projectSubService: projectSubService,
+	}
+}
+
+func projectSubResponse(ctx *gin.Context, projectSub *entity.ProjectSub) model.MentorSubmitResp {
+	var grades []model.CriterionGradeResp
+	for _, grade := range projectSub.Grades {
+		grades = append(grades, model.CriterionGradeResp{
+			CriterionID: grade.CriterionID,
+			LevelID:     grade.LevelID,
+			Points:      grade.Points,
+			Comment:     grade.Comment,
+		})
+	}
+
+	return model.MentorSubmitResp{
+		ProjectSubID:   projectSub.ProjectSubID,
+		ProjectID:      projectSub.ProjectID,
+		StudentID:      projectSub.StudentID,
+		TeamID:         projectSub.TeamID,
+		Attempt:        projectSub.Attempt,
+		IsLatest:       projectSub.IsLatest,
+		SubmissionDate: middleware.LocalTime(ctx, projectSub.SubmissionDate),
+		DaysLate:       projectSub.DaysLate,
+		ProjectPath:    projectSub.ProjectPath,
+		Score:          projectSub.Score,
+		MentorScore:    projectSub.MentorScore,
+		PeerScore:      projectSub.PeerScore,
+		LatePenalty:    projectSub.LatePenalty,
+		FinalScore:     projectSub.FinalScore(),
+		Grades:         grades,
+		Description:    projectSub.Description,
+		AutoStatus:     projectSub.AutoStatus,
+		AutoScore:      projectSub.AutoScore,
+		AutoFeedback:   projectSub.AutoFeedback,
+		AutoOverridden: projectSub.AutoOverridden,
+		ScanStatus:     projectSub.ScanStatus,
+		ScanSignature:  projectSub.ScanSignature,
 	}
 }
 
 // submit project (student only)
 func (c *ProjectSubControllerImpl) StudentSubmitProject(ctx *gin.Context) {
-	// check if the currentUser is admin
+	// check if the currentUser is student
 	claims, _ := ctx.Get("currentUser")
 	userClaims, ok := claims.(*middleware.UserClaims)
 	if !ok || userClaims.Role != entity.Student {
@@ -42,52 +84,35 @@ func (c *ProjectSubControllerImpl) StudentSubmitProject(ctx *gin.Context) {
 		return
 	}
 
-	// validate courseID
+	// get courseID & projectID param
 	courseID := ctx.Param("course_id")
-	var course entity.Course
-	if err := c.db.First(&course, courseID).Error; err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"message": fmt.Sprintf("CourseID %s not found", courseID),
-			"code":    http.StatusNotFound,
-		})
-		return
-	}
-
-	// validate courseID
 	projectID := ctx.Param("project_id")
-	var project entity.Project
-	if err := c.db.First(&project, projectID).Error; err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"message": fmt.Sprintf("ProjectID %s not found", projectID),
-			"code":    http.StatusNotFound,
-		})
-		return
-	}
 
-	// validate current date with course startDate & endDate
-	currentTime := time.Now()
-	if currentTime.Before(course.StartDate) {
-		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "Submissions are not allowed before the course starts",
-		})
+	// retrieve file from req input
+	file, err := ctx.FormFile("project_path")
+	if err != nil {
+		formFileError(ctx, err)
 		return
 	}
 
-	if currentTime.After(course.EndDate) {
-		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "Project submission period has ended",
+	// type & size are checked against the project file policy while the file is streamed to the storage
+	openFile, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
 		})
 		return
 	}
+	defer openFile.Close()
 
-	// bind json body with model
-	var projectSubReq model.ProjectSubStudent
-
-	// if projectSubReq.Score == 0 {
-	// 	projectSubReq.Score = 0
-	// }
+	upload := model.FileUpload{
+		File: openFile,
+		Name: file.Filename,
+		Size: file.Size,
+	}
 
-	if err := ctx.ShouldBind(&projectSubReq); err != nil {
+	projectSub, err := c.projectSubService.StudentSubmitProject(userClaims, courseID, projectID, upload)
+	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
 			"code":  http.StatusBadRequest,
@@ -95,103 +120,140 @@ func (c *ProjectSubControllerImpl) StudentSubmitProject(ctx *gin.Context) {
 		return
 	}
 
-	// retrieve file from req input
-	file, err := ctx.FormFile("project_path")
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "failed to retrieve file",
+	// success response
+	projectSubResp := model.StudentSubmitResp{
+		ProjectSubID:   projectSub.ProjectSubID,
+		ProjectID:      projectSub.ProjectID,
+		StudentID:      projectSub.StudentID,
+		TeamID:         projectSub.TeamID,
+		Attempt:        projectSub.Attempt,
+		SubmissionDate: middleware.LocalTime(ctx, projectSub.SubmissionDate),
+		DaysLate:       projectSub.DaysLate,
+		LatePenalty:    projectSub.LatePenalty,
+		ProjectPath:    projectSub.ProjectPath,
+		ScanStatus:     projectSub.ScanStatus,
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("ProjectID %s attempt %d submitted successfully", projectID, projectSub.Attempt),
+		"data":    projectSubResp,
+	})
+}
+
+// get project submission list (for all, student only sees their own)
+func (c *ProjectSubControllerImpl) GetProjectSubmissions(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get project submissions",
+			"code":  http.StatusUnauthorized,
 		})
 		return
 	}
 
-	// open file to check file size and validate MIME type
-	openFile, err := file.Open()
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	projectSubs, err := c.projectSubService.GetProjectSubmissions(userClaims, courseID, projectID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
+			"code":  http.StatusBadRequest,
 		})
-		ctx.Abort()
 		return
 	}
-	defer openFile.Close()
 
-	// check file size
-	if err := middleware.CheckFileSize(openFile); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
-		ctx.Abort()
-		return
+	// success response
+	projectSubsResp := make([]model.MentorSubmitResp, 0, len(projectSubs))
+	for i := range projectSubs {
+		projectSubsResp = append(projectSubsResp, projectSubResponse(ctx, &projectSubs[i]))
 	}
 
-	// reset file pointer for MIME type detection
-	openFile.Seek(0, io.SeekStart)
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Project submissions fetch successfully",
+		"code":    http.StatusOK,
+		"data":    projectSubsResp,
+	})
+}
 
-	// validate MIME type
-	if err := middleware.CheckMimeType(ctx, openFile); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
+// get project submission (for all, student only sees their own)
+func (c *ProjectSubControllerImpl) GetProjectSubmissionByID(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get project submission",
+			"code":  http.StatusUnauthorized,
 		})
-		ctx.Abort()
 		return
 	}
 
-	// generate file name based on username & time upload
-	newFileName := middleware.GenerateFileName(project.ProjectName, file.Filename)
-	ctx.Set("newFileName", newFileName)
+	// get courseID, projectID & projectSubID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	projectSubID := ctx.Param("project_sub_id")
 
-	// create directory to save file if it not exist
-	err = os.MkdirAll("uploads", os.ModePerm)
+	projectSub, err := c.projectSubService.GetProjectSubmissionByID(userClaims, courseID, projectID, projectSubID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to create directory",
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
 		})
 		return
 	}
 
-	// move file to directory uploads
-	filePath := fmt.Sprintf("uploads/%s", newFileName)
-	if err := ctx.SaveUploadedFile(file, filePath); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to save file",
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Project submission fetch successfully",
+		"code":    http.StatusOK,
+		"data":    projectSubResponse(ctx, projectSub),
+	})
+}
+
+// submission history of a student (for all, student only their own)
+func (c *ProjectSubControllerImpl) GetProjectSubmissionHistory(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get submission history",
+			"code":  http.StatusUnauthorized,
 		})
 		return
 	}
 
-	// submit projectSub to db
-	projectSub := entity.ProjectSub{
-		ProjectID:      project.ProjectID,
-		StudentID:      userClaims.UserID,
-		SubmissionDate: time.Now(),
-		ProjectPath:    filePath,
-		// Score:          projectSubReq.Score,
-	}
+	// get courseID, projectID & projectSubID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	projectSubID := ctx.Param("project_sub_id")
 
-	if err := c.db.Create(&projectSub).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create course",
-			"code":  http.StatusInternalServerError,
+	projectSubs, err := c.projectSubService.GetProjectSubmissionHistory(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
 		})
 		return
 	}
 
 	// success response
-	projectSubResp := model.StudentSubmitResp{
-		ProjectSubID:   projectSub.ProjectSubID,
-		ProjectID:      projectSub.ProjectID,
-		StudentID:      projectSub.StudentID,
-		SubmissionDate: projectSub.SubmissionDate,
-		ProjectPath:    projectSub.ProjectPath,
+	projectSubsResp := make([]model.MentorSubmitResp, 0, len(projectSubs))
+	for i := range projectSubs {
+		projectSubsResp = append(projectSubsResp, projectSubResponse(ctx, &projectSubs[i]))
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{
-		"message": fmt.Sprintf("Course %s created successfully", course.CourseName),
-		"user":    projectSubResp,
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Submission history fetch successfully",
+		"code":    http.StatusOK,
+		"data":    projectSubsResp,
 	})
 }
 
-// get all student submission list (for all)
-
 // mentor scoring (mentor only)
 func (c *ProjectSubControllerImpl) MentorSubmitScore(ctx *gin.Context) {
 	// make sure user has signed in
@@ -205,74 +267,222 @@ func (c *ProjectSubControllerImpl) MentorSubmitScore(ctx *gin.Context) {
 		return
 	}
 
-	// validate courseID existance
+	// get courseID, projectID & projectSubID param
 	courseID := ctx.Param("course_id")
-	var course entity.Course
-	if err := c.db.First(&course, courseID).Error; err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"message": fmt.Sprintf("CourseID %s not found", courseID),
+	projectID := ctx.Param("project_id")
+	projectSubID := ctx.Param("project_sub_id")
+
+	// get body input
+	var projectSubReq model.ProjectSubMentor
+	if err := ctx.ShouldBindJSON(&projectSubReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
 		})
 		return
 	}
 
-	// get project_sub_id
-	projectSubID := ctx.Param("project_sub_id")
-	var existingProjectSub entity.ProjectSub
-	if err := c.db.First(&existingProjectSub, projectSubID).Error; err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"message": fmt.Sprintf("ProjectSubID %s not found", projectSubID),
+	projectSub, err := c.projectSubService.MentorSubmitScore(userClaims, courseID, projectID, projectSubID, projectSubReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+			"code":    http.StatusBadRequest,
 		})
 		return
 	}
 
-	// get body input
-	var projectSubReq model.ProjectSubMentor
-	if err := ctx.ShouldBindJSON(&projectSubReq); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"message": err.Error(),
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ProjectSubID %s has been scored", projectSubID),
+		"code":    http.StatusOK,
+		"data":    projectSubResponse(ctx, projectSub),
+	})
+}
+
+// delete submission history (admin only)
+func (c *ProjectSubControllerImpl) DeleteProjectSubmissionByID(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to delete project submission",
+			"code":  http.StatusUnauthorized,
 		})
 		return
 	}
 
-	// validation score
-	if projectSubReq.Score < 0 && projectSubReq.Score > 100 {
+	// get courseID, projectID & projectSubID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	projectSubID := ctx.Param("project_sub_id")
+
+	if err := c.projectSubService.DeleteProjectSubmissionByID(userClaims, courseID, projectID, projectSubID); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
-			"message": "Score must be between 0-100",
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
 		})
 		return
 	}
 
-	existingProjectSub.Score = projectSubReq.Score
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ProjectSubID %s has been deleted", projectSubID),
+	})
+}
 
-	if projectSubReq.Description != "" {
-		existingProjectSub.Description = projectSubReq.Description
+// get peer reviews of a submission (for all, student only their own submission without reviewer)
+func (c *ProjectSubControllerImpl) GetSubmissionPeerReviews(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get submission peer reviews",
+			"code":  http.StatusUnauthorized,
+		})
+		return
 	}
 
-	// save to db
-	if err := c.db.Save(&existingProjectSub).Error; err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"message": fmt.Sprintf("Failed to update project submission with ID %s", projectSubID),
-			"code":    http.StatusInternalServerError,
+	// get courseID, projectID & projectSubID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	projectSubID := ctx.Param("project_sub_id")
+
+	reviews, err := c.projectSubService.GetSubmissionPeerReviews(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
 		})
 		return
 	}
 
 	// success response
-	projectSub := model.MentorSubmitResp{
-		ProjectSubID:   existingProjectSub.ProjectSubID,
-		ProjectID:      existingProjectSub.ProjectID,
-		StudentID:      existingProjectSub.StudentID,
-		SubmissionDate: existingProjectSub.SubmissionDate,
-		ProjectPath:    existingProjectSub.ProjectPath,
-		Score:          existingProjectSub.Score,
-		Description:    existingProjectSub.Description,
+	reviewsResp := make([]model.PeerReviewResp, 0, len(reviews))
+	for i := range reviews {
+		reviewsResp = append(reviewsResp, peerReviewResponse(ctx, &reviews[i], userClaims.Role == entity.Student))
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("ProjectSubID %s has been scored", projectSubID),
+		"message": "Submission peer reviews fetch successfully",
 		"code":    http.StatusOK,
-		"data":    projectSub,
+		"data":    reviewsResp,
 	})
 }
 
-// delete submission history (admin only)
+// get auto grading outcome of a submission (for all, student only their own submission without test output)
+func (c *ProjectSubControllerImpl) GetSubmissionTestResults(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get submission test results",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & projectSubID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	projectSubID := ctx.Param("project_sub_id")
+
+	projectSub, results, err := c.projectSubService.GetSubmissionTestResults(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	// success response
+	tests := make([]model.TestResultResp, 0, len(results))
+	for _, result := range results {
+		testResp := model.TestResultResp{
+			Package: result.Package,
+			Test:    result.Test,
+			Status:  result.Status,
+			Elapsed: result.Elapsed,
+		}
+
+		if userClaims.Role != entity.Student {
+			testResp.Output = result.Output
+		}
+
+		tests = append(tests, testResp)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Submission test results fetch successfully",
+		"code":    http.StatusOK,
+		"data": model.AutoGradeResp{
+			ProjectSubID:   projectSub.ProjectSubID,
+			AutoStatus:     projectSub.AutoStatus,
+			AutoScore:      projectSub.AutoScore,
+			AutoFeedback:   projectSub.AutoFeedback,
+			AutoOverridden: projectSub.AutoOverridden,
+			AutoGradedAt:   middleware.LocalTimePtr(ctx, projectSub.AutoGradedAt),
+			Tests:          tests,
+		},
+	})
+}
+
+// stream submission file (for all, student only their own)
+func (c *ProjectSubControllerImpl) DownloadProjectSubmission(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to download project submission",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & projectSubID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	projectSubID := ctx.Param("project_sub_id")
+
+	link, err := c.projectSubService.GetProjectSubmissionFile(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Download link created successfully",
+		"code":    http.StatusOK,
+		"data":    fileURLResponse(ctx, link),
+	})
+}
+
+// missing file or a request body over the upload limit
+func formFileError(ctx *gin.Context, err error) {
+	if middleware.IsUploadTooLarge(err) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": fmt.Sprintf("file size must be at most %s", middleware.FormatSize(middleware.MaxUploadSize)),
+			"code":  http.StatusRequestEntityTooLarge,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		"error": "failed to retrieve file",
+		"code":  http.StatusBadRequest,
+	})
+}
+
+func fileURLResponse(ctx *gin.Context, link *storage.Link) model.FileURLResp {
+	return model.FileURLResp{
+		URL:       link.URL,
+		FileName:  link.FileName,
+		ExpiresAt: middleware.LocalTime(ctx, link.ExpiresAt),
+	}
+}
@@ -16,6 +16,8 @@ type ProjectController interface {
 	GetProjectByID(ctx *gin.Context)
 	UpdateProjectByID(ctx *gin.Context)
 	DeleteProjectByID(ctx *gin.Context)
+	UploadTestSuite(ctx *gin.Context)
+	RemoveTestSuite(ctx *gin.Context)
 }
 
 type ProjectControllerImpl struct {
@@ -67,13 +69,34 @@ func (c *ProjectControllerImpl) CreateProject(ctx *gin.Context) {
 
 	// success response
 	projectResp := model.ProjectResp{
-		ProjectID:   project.ProjectID,
-		CourseID:    project.CourseID,
-		ProjectName: project.ProjectName,
-		Description: project.Description,
-		Deadline:    project.Deadline,
-		CreatedAt:   project.CreatedAt,
-		UpdatedAt:   project.UpdatedAt,
+		ProjectID:          project.ProjectID,
+		CourseID:           project.CourseID,
+		ProjectName:        project.ProjectName,
+		Description:        project.Description,
+		Deadline:           middleware.LocalTime(ctx, project.Deadline),
+		Optional:           project.Optional,
+		MaxAttempts:        project.MaxAttempts,
+		LatePolicy:         project.LatePolicy,
+		GraceHours:         project.GraceHours,
+		PenaltyPerDay:      project.PenaltyPerDay,
+		PenaltyCap:         project.PenaltyCap,
+		RubricID:           project.RubricID,
+		CategoryID:         project.CategoryID,
+		TeamProject:        project.TeamProject,
+		MinTeamSize:        project.MinTeamSize,
+		MaxTeamSize:        project.MaxTeamSize,
+		SelfFormedTeams:    project.SelfFormedTeams,
+		PeerReviewEnabled:  project.PeerReviewEnabled,
+		PeerReviewers:      project.PeerReviewers,
+		PeerReviewDeadline: middleware.LocalTimePtr(ctx, project.PeerReviewDeadline),
+		PeerWeight:         project.PeerWeight,
+		PeerAssignedAt:     middleware.LocalTimePtr(ctx, project.PeerAssignedAt),
+		HasTestSuite:       project.TestSuiteKey != "",
+		TestTimeout:        project.TestTimeout,
+		AllowedFileTypes:   project.FileTypes(),
+		MaxFileSize:        project.MaxFileSize,
+		CreatedAt:          middleware.LocalTime(ctx, project.CreatedAt),
+		UpdatedAt:          middleware.LocalTime(ctx, project.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{
@@ -113,13 +136,34 @@ func (c *ProjectControllerImpl) GetProjects(ctx *gin.Context) {
 
 	for _, project := range projects {
 		projectResp := model.ProjectResp{
-			ProjectID:   project.ProjectID,
-			CourseID:    project.CourseID,
-			ProjectName: project.ProjectName,
-			Description: project.Description,
-			Deadline:    project.Deadline,
-			CreatedAt:   project.CreatedAt,
-			UpdatedAt:   project.UpdatedAt,
+			ProjectID:          project.ProjectID,
+			CourseID:           project.CourseID,
+			ProjectName:        project.ProjectName,
+			Description:        project.Description,
+			Deadline:           middleware.LocalTime(ctx, project.Deadline),
+			Optional:           project.Optional,
+			MaxAttempts:        project.MaxAttempts,
+			LatePolicy:         project.LatePolicy,
+			GraceHours:         project.GraceHours,
+			PenaltyPerDay:      project.PenaltyPerDay,
+			PenaltyCap:         project.PenaltyCap,
+			RubricID:           project.RubricID,
+			CategoryID:         project.CategoryID,
+			TeamProject:        project.TeamProject,
+			MinTeamSize:        project.MinTeamSize,
+			MaxTeamSize:        project.MaxTeamSize,
+			SelfFormedTeams:    project.SelfFormedTeams,
+			PeerReviewEnabled:  project.PeerReviewEnabled,
+			PeerReviewers:      project.PeerReviewers,
+			PeerReviewDeadline: middleware.LocalTimePtr(ctx, project.PeerReviewDeadline),
+			PeerWeight:         project.PeerWeight,
+			PeerAssignedAt:     middleware.LocalTimePtr(ctx, project.PeerAssignedAt),
+			HasTestSuite:       project.TestSuiteKey != "",
+			TestTimeout:        project.TestTimeout,
+			AllowedFileTypes:   project.FileTypes(),
+			MaxFileSize:        project.MaxFileSize,
+			CreatedAt:          middleware.LocalTime(ctx, project.CreatedAt),
+			UpdatedAt:          middleware.LocalTime(ctx, project.UpdatedAt),
 		}
 
 		projectResponses = append(projectResponses, projectResp)
@@ -162,13 +206,34 @@ func (c *ProjectControllerImpl) GetProjectByID(ctx *gin.Context) {
 	}
 
 	projectResp := model.ProjectResp{
-		ProjectID:   project.ProjectID,
-		CourseID:    project.CourseID,
-		ProjectName: project.ProjectName,
-		Description: project.Description,
-		Deadline:    project.Deadline,
-		CreatedAt:   project.CreatedAt,
-		UpdatedAt:   project.UpdatedAt,
+		ProjectID:          project.ProjectID,
+		CourseID:           project.CourseID,
+		ProjectName:        project.ProjectName,
+		Description:        project.Description,
+		Deadline:           middleware.LocalTime(ctx, project.Deadline),
+		Optional:           project.Optional,
+		MaxAttempts:        project.MaxAttempts,
+		LatePolicy:         project.LatePolicy,
+		GraceHours:         project.GraceHours,
+		PenaltyPerDay:      project.PenaltyPerDay,
+		PenaltyCap:         project.PenaltyCap,
+		RubricID:           project.RubricID,
+		CategoryID:         project.CategoryID,
+		TeamProject:        project.TeamProject,
+		MinTeamSize:        project.MinTeamSize,
+		MaxTeamSize:        project.MaxTeamSize,
+		SelfFormedTeams:    project.SelfFormedTeams,
+		PeerReviewEnabled:  project.PeerReviewEnabled,
+		PeerReviewers:      project.PeerReviewers,
+		PeerReviewDeadline: middleware.LocalTimePtr(ctx, project.PeerReviewDeadline),
+		PeerWeight:         project.PeerWeight,
+		PeerAssignedAt:     middleware.LocalTimePtr(ctx, project.PeerAssignedAt),
+		HasTestSuite:       project.TestSuiteKey != "",
+		TestTimeout:        project.TestTimeout,
+		AllowedFileTypes:   project.FileTypes(),
+		MaxFileSize:        project.MaxFileSize,
+		CreatedAt:          middleware.LocalTime(ctx, project.CreatedAt),
+		UpdatedAt:          middleware.LocalTime(ctx, project.UpdatedAt),
 	}
 
 	// succeed response
@@ -220,13 +285,34 @@ func (c *ProjectControllerImpl) UpdateProjectByID(ctx *gin.Context) {
 
 	// success response
 	projectResp := model.ProjectResp{
-		ProjectID:   project.ProjectID,
-		CourseID:    project.CourseID,
-		ProjectName: project.ProjectName,
-		Description: project.Description,
-		Deadline:    project.Deadline,
-		CreatedAt:   project.CreatedAt,
-		UpdatedAt:   project.UpdatedAt,
+		ProjectID:          project.ProjectID,
+		CourseID:           project.CourseID,
+		ProjectName:        project.ProjectName,
+		Description:        project.Description,
+		Deadline:           middleware.LocalTime(ctx, project.Deadline),
+		Optional:           project.Optional,
+		MaxAttempts:        project.MaxAttempts,
+		LatePolicy:         project.LatePolicy,
+		GraceHours:         project.GraceHours,
+		PenaltyPerDay:      project.PenaltyPerDay,
+		PenaltyCap:         project.PenaltyCap,
+		RubricID:           project.RubricID,
+		CategoryID:         project.CategoryID,
+		TeamProject:        project.TeamProject,
+		MinTeamSize:        project.MinTeamSize,
+		MaxTeamSize:        project.MaxTeamSize,
+		SelfFormedTeams:    project.SelfFormedTeams,
+		PeerReviewEnabled:  project.PeerReviewEnabled,
+		PeerReviewers:      project.PeerReviewers,
+		PeerReviewDeadline: middleware.LocalTimePtr(ctx, project.PeerReviewDeadline),
+		PeerWeight:         project.PeerWeight,
+		PeerAssignedAt:     middleware.LocalTimePtr(ctx, project.PeerAssignedAt),
+		HasTestSuite:       project.TestSuiteKey != "",
+		TestTimeout:        project.TestTimeout,
+		AllowedFileTypes:   project.FileTypes(),
+		MaxFileSize:        project.MaxFileSize,
+		CreatedAt:          middleware.LocalTime(ctx, project.CreatedAt),
+		UpdatedAt:          middleware.LocalTime(ctx, project.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
@@ -266,3 +352,91 @@ func (c *ProjectControllerImpl) DeleteProjectByID(ctx *gin.Context) {
 		"code":    http.StatusOK,
 	})
 }
+
+// attach hidden go test suite zip (admin & mentor)
+func (c *ProjectControllerImpl) UploadTestSuite(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "You have to sign in to upload a test suite",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	courseID := ctx.Param("course_id")
+
+	projectID := ctx.Param("project_id")
+
+	// retrieve file from req input
+	file, err := ctx.FormFile("test_suite")
+	if err != nil {
+		formFileError(ctx, err)
+		return
+	}
+
+	openFile, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "failed to read file",
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+	defer openFile.Close()
+
+	upload := model.FileUpload{
+		File: openFile,
+		Name: file.Filename,
+		Size: file.Size,
+	}
+
+	project, err := c.projectService.SetTestSuite(userClaims, courseID, projectID, upload)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// succeed response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Test suite of ProjectID %d uploaded successfully", project.ProjectID),
+		"code":    http.StatusOK,
+	})
+}
+
+// detach test suite (admin & mentor)
+func (c *ProjectControllerImpl) RemoveTestSuite(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "You have to sign in to remove a test suite",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	courseID := ctx.Param("course_id")
+
+	projectID := ctx.Param("project_id")
+
+	if _, err := c.projectService.RemoveTestSuite(userClaims, courseID, projectID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// succeed response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Test suite has been removed",
+		"code":    http.StatusOK,
+	})
+}
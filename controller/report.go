@@ -0,0 +1,255 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type ReportController interface {
+	GetStudentAttendReports(ctx *gin.Context)
+	GetAtRiskStudents(ctx *gin.Context)
+	GetClassTurnoutReports(ctx *gin.Context)
+	GetAttendTrendReports(ctx *gin.Context)
+}
+
+type ReportControllerImpl struct {
+	reportService service.ReportService
+}
+
+func NewReportController(reportService service.ReportService) ReportController {
+	return &ReportControllerImpl{
+		reportService: reportService,
+	}
+}
+
+var studentAttendReportColumns = []middleware.ExportColumn{
+	middleware.NumberColumn("student_id"),
+	middleware.TextColumn("username"),
+	middleware.TextColumn("email"),
+	middleware.NumberColumn("total_classes"),
+	middleware.NumberColumn("present"),
+	middleware.NumberColumn("late"),
+	middleware.NumberColumn("excused"),
+	middleware.NumberColumn("absent"),
+	middleware.NumberColumn("attendance_rate"),
+}
+
+func studentAttendReportRows(reports []model.StudentAttendReport) [][]string {
+	var rows [][]string
+
+	for _, report := range reports {
+		rows = append(rows, []string{
+			fmt.Sprint(report.StudentID),
+			report.Username,
+			report.Email,
+			fmt.Sprint(report.TotalClasses),
+			fmt.Sprint(report.Present),
+			fmt.Sprint(report.Late),
+			fmt.Sprint(report.Excused),
+			fmt.Sprint(report.Absent),
+			fmt.Sprint(report.AttendanceRate),
+		})
+	}
+
+	return rows
+}
+
+// respond with json, or export file when format query given
+func writeReport(ctx *gin.Context, fileName, message string, data any, columns []middleware.ExportColumn, rows [][]string) {
+	if format := ctx.Query("format"); format != "" {
+		if err := middleware.WriteExport(ctx, format, fileName, columns, rows); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+				"code":  http.StatusBadRequest,
+			})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"code":    http.StatusOK,
+		"data":    data,
+	})
+}
+
+// per student attendance percentage (admin & mentor)
+func (c *ReportControllerImpl) GetStudentAttendReports(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get course reports",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	reports, err := c.reportService.GetStudentAttendReports(userClaims, courseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	writeReport(ctx, fmt.Sprintf("course-%s-student-attendance", courseID), "Student attendance report fetch successfully", reports, studentAttendReportColumns, studentAttendReportRows(reports))
+}
+
+// students below attendance threshold (admin & mentor)
+func (c *ReportControllerImpl) GetAtRiskStudents(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get course reports",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// get threshold query, in percent
+	threshold := model.DefaultAtRiskThreshold
+	if thresholdStr := ctx.Query("threshold"); thresholdStr != "" {
+		value, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "threshold must be a number",
+				"code":  http.StatusBadRequest,
+			})
+			return
+		}
+
+		threshold = value
+	}
+
+	reports, err := c.reportService.GetAtRiskStudents(userClaims, courseID, threshold)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	writeReport(ctx, fmt.Sprintf("course-%s-at-risk-students", courseID), fmt.Sprintf("Students below %.2f%% attendance fetch successfully", threshold), reports, studentAttendReportColumns, studentAttendReportRows(reports))
+}
+
+// per class turnout (admin & mentor)
+func (c *ReportControllerImpl) GetClassTurnoutReports(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get course reports",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	reports, err := c.reportService.GetClassTurnoutReports(userClaims, courseID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// render in user timezone
+	loc := middleware.UserLocation(ctx)
+
+	var rows [][]string
+	for i := range reports {
+		reports[i].StartDate = reports[i].StartDate.In(loc)
+
+		rows = append(rows, []string{
+			fmt.Sprint(reports[i].ClassID),
+			reports[i].ClassName,
+			reports[i].StartDate.Format(time.RFC3339),
+			fmt.Sprint(reports[i].Enrolled),
+			fmt.Sprint(reports[i].Present),
+			fmt.Sprint(reports[i].Late),
+			fmt.Sprint(reports[i].Excused),
+			fmt.Sprint(reports[i].Absent),
+			fmt.Sprint(reports[i].TurnoutRate),
+		})
+	}
+
+	columns := []middleware.ExportColumn{
+		middleware.NumberColumn("class_id"),
+		middleware.TextColumn("class_name"),
+		middleware.TextColumn("start_date"),
+		middleware.NumberColumn("enrolled"),
+		middleware.NumberColumn("present"),
+		middleware.NumberColumn("late"),
+		middleware.NumberColumn("excused"),
+		middleware.NumberColumn("absent"),
+		middleware.NumberColumn("turnout_rate"),
+	}
+	writeReport(ctx, fmt.Sprintf("course-%s-class-turnout", courseID), "Class turnout report fetch successfully", reports, columns, rows)
+}
+
+// attendance trend grouped by day, week or month (admin & mentor)
+func (c *ReportControllerImpl) GetAttendTrendReports(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get course reports",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID param
+	courseID := ctx.Param("course_id")
+
+	// period grouped by user timezone
+	loc := middleware.UserLocation(ctx)
+
+	reports, err := c.reportService.GetAttendTrendReports(userClaims, courseID, ctx.Query("interval"), loc)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	var rows [][]string
+	for i := range reports {
+		reports[i].Period = reports[i].Period.In(loc)
+
+		rows = append(rows, []string{
+			reports[i].Period.Format(time.RFC3339),
+			fmt.Sprint(reports[i].Classes),
+			fmt.Sprint(reports[i].Attended),
+			fmt.Sprint(reports[i].AverageTurnout),
+		})
+	}
+
+	columns := []middleware.ExportColumn{middleware.TextColumn("period"), middleware.NumberColumn("classes"), middleware.NumberColumn("attended"), middleware.NumberColumn("average_turnout")}
+	writeReport(ctx, fmt.Sprintf("course-%s-attendance-trend", courseID), "Attendance trend report fetch successfully", reports, columns, rows)
+}
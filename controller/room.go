@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type RoomController interface {
+	CreateRoom(ctx *gin.Context)
+	GetRooms(ctx *gin.Context)
+	GetRoomByID(ctx *gin.Context)
+	UpdateRoomByID(ctx *gin.Context)
+	DeleteRoomByID(ctx *gin.Context)
+}
+
+type RoomControllerImpl struct {
+	roomService service.RoomService
+}
+
+func NewRoomController(roomService service.RoomService) RoomController {
+	return &RoomControllerImpl{
+		roomService: roomService,
+	}
+}
+
+func roomResponse(ctx *gin.Context, room *entity.Room) model.RoomResp {
+	return model.RoomResp{
+		RoomID:    room.RoomID,
+		RoomName:  room.RoomName,
+		Location:  room.Location,
+		Capacity:  room.Capacity,
+		CreatedAt: middleware.LocalTime(ctx, room.CreatedAt),
+		UpdatedAt: middleware.LocalTime(ctx, room.UpdatedAt),
+	}
+}
+
+// create room (admin only)
+func (c *RoomControllerImpl) CreateRoom(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to create a new room",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// bind json body with model
+	var roomReq model.RoomReq
+
+	if err := ctx.ShouldBindJSON(&roomReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	room, err := c.roomService.CreateRoom(userClaims, roomReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("Room %s created successfully", room.RoomName),
+		"data":    roomResponse(ctx, room),
+	})
+}
+
+// get all rooms (for all)
+func (c *RoomControllerImpl) GetRooms(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	_, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get rooms",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	rooms, err := c.roomService.GetRooms()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// create response
+	var roomResponses []model.RoomResp
+
+	for _, room := range rooms {
+		roomResponses = append(roomResponses, roomResponse(ctx, &room))
+	}
+
+	// succeed response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Rooms fetch successfully",
+		"code":    http.StatusOK,
+		"data":    roomResponses,
+	})
+}
+
+// get room by id (for all)
+func (c *RoomControllerImpl) GetRoomByID(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	_, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get a room",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get roomID param
+	roomID := ctx.Param("room_id")
+
+	room, err := c.roomService.GetRoomByID(roomID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	// succeed response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Room fetch successfully",
+		"code":    http.StatusOK,
+		"data":    roomResponse(ctx, room),
+	})
+}
+
+// update room (admin only)
+func (c *RoomControllerImpl) UpdateRoomByID(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to update a room",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get roomID param
+	roomID := ctx.Param("room_id")
+
+	// get room body input
+	var roomReq model.RoomReq
+
+	if err := ctx.ShouldBindJSON(&roomReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	room, err := c.roomService.UpdateRoomByID(userClaims, roomID, roomReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// succeed response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("RoomID %s updated successfully", roomID),
+		"code":    http.StatusOK,
+		"data":    roomResponse(ctx, room),
+	})
+}
+
+// delete room (admin only)
+func (c *RoomControllerImpl) DeleteRoomByID(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to delete a room",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get roomID param
+	roomID := ctx.Param("room_id")
+
+	if err := c.roomService.DeleteRoomByID(userClaims, roomID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// succeed response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("RoomID %s has been deleted", roomID),
+		"code":    http.StatusOK,
+	})
+}
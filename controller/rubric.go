@@ -0,0 +1,264 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type RubricController interface {
+	CreateRubric(ctx *gin.Context)
+	GetRubrics(ctx *gin.Context)
+	GetRubricByID(ctx *gin.Context)
+	UpdateRubric(ctx *gin.Context)
+	DeleteRubric(ctx *gin.Context)
+	GetProjectRubric(ctx *gin.Context)
+}
+
+type RubricControllerImpl struct {
+	rubricService service.RubricService
+}
+
+func NewRubricController(rubricService service.RubricService) RubricController {
+	return &RubricControllerImpl{
+		rubricService: rubricService,
+	}
+}
+
+func rubricResponse(ctx *gin.Context, rubric *entity.Rubric) model.RubricResp {
+	criteria := make([]model.RubricCriterionResp, 0, len(rubric.Criteria))
+	for _, criterion := range rubric.Criteria {
+		levels := make([]model.RubricLevelResp, 0, len(criterion.Levels))
+		for _, level := range criterion.Levels {
+			levels = append(levels, model.RubricLevelResp{
+				LevelID:    level.LevelID,
+				Points:     level.Points,
+				Descriptor: level.Descriptor,
+			})
+		}
+
+		criteria = append(criteria, model.RubricCriterionResp{
+			CriterionID: criterion.CriterionID,
+			Title:       criterion.Title,
+			Description: criterion.Description,
+			Position:    criterion.Position,
+			Levels:      levels,
+		})
+	}
+
+	return model.RubricResp{
+		RubricID:    rubric.RubricID,
+		Title:       rubric.Title,
+		Description: rubric.Description,
+		CreatedBy:   rubric.CreatedBy,
+		MaxPoints:   rubric.MaxPoints(),
+		Criteria:    criteria,
+		CreatedAt:   middleware.LocalTime(ctx, rubric.CreatedAt),
+		UpdatedAt:   middleware.LocalTime(ctx, rubric.UpdatedAt),
+	}
+}
+
+// create reusable rubric (admin & mentor)
+func (c *RubricControllerImpl) CreateRubric(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to create a rubric",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	var rubricReq model.RubricReq
+	if err := ctx.ShouldBindJSON(&rubricReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	rubric, err := c.rubricService.CreateRubric(userClaims, rubricReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("RubricID %d created successfully", rubric.RubricID),
+		"data":    rubricResponse(ctx, rubric),
+	})
+}
+
+// get every rubric (admin & mentor)
+func (c *RubricControllerImpl) GetRubrics(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get rubrics",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	rubrics, err := c.rubricService.GetRubrics(userClaims)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	rubricsResp := make([]model.RubricResp, 0, len(rubrics))
+	for i := range rubrics {
+		rubricsResp = append(rubricsResp, rubricResponse(ctx, &rubrics[i]))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Rubrics fetch successfully",
+		"code":    http.StatusOK,
+		"data":    rubricsResp,
+	})
+}
+
+// get rubric (admin & mentor)
+func (c *RubricControllerImpl) GetRubricByID(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get a rubric",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get rubricID param
+	rubricID := ctx.Param("rubric_id")
+
+	rubric, err := c.rubricService.GetRubricByID(userClaims, rubricID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Rubric fetch successfully",
+		"code":    http.StatusOK,
+		"data":    rubricResponse(ctx, rubric),
+	})
+}
+
+// replace rubric details & criteria (creator & admin)
+func (c *RubricControllerImpl) UpdateRubric(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to update a rubric",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get rubricID param
+	rubricID := ctx.Param("rubric_id")
+
+	var rubricReq model.RubricReq
+	if err := ctx.ShouldBindJSON(&rubricReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	rubric, err := c.rubricService.UpdateRubric(userClaims, rubricID, rubricReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("RubricID %s updated successfully", rubricID),
+		"data":    rubricResponse(ctx, rubric),
+	})
+}
+
+// delete rubric that no project uses (creator & admin)
+func (c *RubricControllerImpl) DeleteRubric(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to delete a rubric",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get rubricID param
+	rubricID := ctx.Param("rubric_id")
+
+	if err := c.rubricService.DeleteRubric(userClaims, rubricID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("RubricID %s has been deleted", rubricID),
+	})
+}
+
+// get rubric of a project (for all)
+func (c *RubricControllerImpl) GetProjectRubric(ctx *gin.Context) {
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	rubric, err := c.rubricService.GetProjectRubric(courseID, projectID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Project rubric fetch successfully",
+		"code":    http.StatusOK,
+		"data":    rubricResponse(ctx, rubric),
+	})
+}
@@ -0,0 +1,58 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type ScanController interface {
+	RescanSubmission(ctx *gin.Context)
+}
+
+type ScanControllerImpl struct {
+	scanService service.ScanService
+}
+
+func NewScanController(scanService service.ScanService) ScanController {
+	return &ScanControllerImpl{
+		scanService: scanService,
+	}
+}
+
+// quarantine a submission file until it's scanned again (admin only)
+func (c *ScanControllerImpl) RescanSubmission(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to rescan a submission",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & projectSubID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	projectSubID := ctx.Param("project_sub_id")
+
+	projectSub, err := c.scanService.RescanSubmission(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response, the scan happens in background
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": fmt.Sprintf("ProjectSubID %d queued for malware scan", projectSub.ProjectSubID),
+		"code":    http.StatusAccepted,
+	})
+}
@@ -0,0 +1,184 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type SimilarityController interface {
+	AnalyzeProject(ctx *gin.Context)
+	GetReports(ctx *gin.Context)
+	GetReportByID(ctx *gin.Context)
+}
+
+type SimilarityControllerImpl struct {
+	similarityService service.SimilarityService
+}
+
+func NewSimilarityController(similarityService service.SimilarityService) SimilarityController {
+	return &SimilarityControllerImpl{
+		similarityService: similarityService,
+	}
+}
+
+func similarityReportResponse(ctx *gin.Context, report *entity.SimilarityReport) model.SimilarityReportResp {
+	var pairs []model.SimilarityPairResp
+	for _, pair := range report.Pairs {
+		segments := make([]model.SimilaritySegmentResp, 0, len(pair.Segments))
+		for _, segment := range pair.Segments {
+			segments = append(segments, model.SimilaritySegmentResp{
+				Text:  segment.Text,
+				Words: segment.Words,
+			})
+		}
+
+		pairs = append(pairs, model.SimilarityPairResp{
+			ProjectSubID:   pair.ProjectSubID,
+			StudentID:      pair.StudentID,
+			MatchSubID:     pair.MatchSubID,
+			MatchStudentID: pair.MatchStudentID,
+			ExactDuplicate: pair.ExactDuplicate,
+			Similarity:     pair.Similarity,
+			Segments:       segments,
+		})
+	}
+
+	return model.SimilarityReportResp{
+		ReportID:    report.ReportID,
+		ProjectID:   report.ProjectID,
+		Status:      report.Status,
+		Threshold:   report.Threshold,
+		Analyzed:    report.Analyzed,
+		Skipped:     report.Skipped,
+		Error:       report.Error,
+		RequestedBy: report.RequestedBy,
+		CreatedAt:   middleware.LocalTime(ctx, report.CreatedAt),
+		CompletedAt: middleware.LocalTimePtr(ctx, report.CompletedAt),
+		Pairs:       pairs,
+	}
+}
+
+// start similarity analysis of the latest submissions (admin & mentor)
+func (c *SimilarityControllerImpl) AnalyzeProject(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to analyze submissions",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	var analyzeReq model.SimilarityReq
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&analyzeReq); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+				"code":  http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	report, err := c.similarityService.AnalyzeProject(userClaims, courseID, projectID, analyzeReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response, analysis keeps running in background
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"message": fmt.Sprintf("Similarity analysis started as ReportID %d", report.ReportID),
+		"code":    http.StatusAccepted,
+		"data":    similarityReportResponse(ctx, report),
+	})
+}
+
+// get similarity reports of a project without their pairs (admin & mentor)
+func (c *SimilarityControllerImpl) GetReports(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get similarity reports",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	reports, err := c.similarityService.GetReports(userClaims, courseID, projectID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	reportsResp := make([]model.SimilarityReportResp, 0, len(reports))
+	for i := range reports {
+		reportsResp = append(reportsResp, similarityReportResponse(ctx, &reports[i]))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Similarity reports fetch successfully",
+		"code":    http.StatusOK,
+		"data":    reportsResp,
+	})
+}
+
+// get similarity report with suspicious pairs & their matching segments (admin & mentor)
+func (c *SimilarityControllerImpl) GetReportByID(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get a similarity report",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & reportID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	reportID := ctx.Param("report_id")
+
+	report, err := c.similarityService.GetReportByID(userClaims, courseID, projectID, reportID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("ReportID %s fetch successfully", reportID),
+		"code":    http.StatusOK,
+		"data":    similarityReportResponse(ctx, report),
+	})
+}
@@ -0,0 +1,400 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/service"
+)
+
+type TeamController interface {
+	CreateTeam(ctx *gin.Context)
+	GenerateTeams(ctx *gin.Context)
+	GetTeams(ctx *gin.Context)
+	GetTeamByID(ctx *gin.Context)
+	JoinTeam(ctx *gin.Context)
+	LeaveTeam(ctx *gin.Context)
+	AdjustMember(ctx *gin.Context)
+	RemoveMember(ctx *gin.Context)
+	DeleteTeam(ctx *gin.Context)
+}
+
+type TeamControllerImpl struct {
+	teamService service.TeamService
+}
+
+func NewTeamController(teamService service.TeamService) TeamController {
+	return &TeamControllerImpl{
+		teamService: teamService,
+	}
+}
+
+func teamMemberResponse(ctx *gin.Context, member *entity.TeamMember) model.TeamMemberResp {
+	return model.TeamMemberResp{
+		StudentID:  member.StudentID,
+		Adjustment: member.Adjustment,
+		JoinedAt:   middleware.LocalTime(ctx, member.CreatedAt),
+	}
+}
+
+func teamResponse(ctx *gin.Context, team *entity.Team) model.TeamResp {
+	members := make([]model.TeamMemberResp, 0, len(team.Members))
+	for i := range team.Members {
+		members = append(members, teamMemberResponse(ctx, &team.Members[i]))
+	}
+
+	return model.TeamResp{
+		TeamID:    team.TeamID,
+		ProjectID: team.ProjectID,
+		Name:      team.Name,
+		CreatedBy: team.CreatedBy,
+		Members:   members,
+		CreatedAt: middleware.LocalTime(ctx, team.CreatedAt),
+	}
+}
+
+// mentor picks the members, student creates a team with themselves (for all)
+func (c *TeamControllerImpl) CreateTeam(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to create a team",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	var teamReq model.TeamReq
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&teamReq); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+				"code":  http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	team, err := c.teamService.CreateTeam(userClaims, courseID, projectID, teamReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("%s created successfully", team.Name),
+		"data":    teamResponse(ctx, team),
+	})
+}
+
+// split students without a team into teams (admin & mentor)
+func (c *TeamControllerImpl) GenerateTeams(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to generate teams",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	var generateReq model.GenerateTeamsReq
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&generateReq); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+				"code":  http.StatusBadRequest,
+			})
+			return
+		}
+	}
+
+	teams, err := c.teamService.GenerateTeams(userClaims, courseID, projectID, generateReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	teamsResp := make([]model.TeamResp, 0, len(teams))
+	for i := range teams {
+		teamsResp = append(teamsResp, teamResponse(ctx, &teams[i]))
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": fmt.Sprintf("%d teams generated successfully", len(teams)),
+		"data":    teamsResp,
+	})
+}
+
+// get project teams (for all)
+func (c *TeamControllerImpl) GetTeams(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get teams",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID & projectID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+
+	teams, err := c.teamService.GetTeams(userClaims, courseID, projectID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	teamsResp := make([]model.TeamResp, 0, len(teams))
+	for i := range teams {
+		teamsResp = append(teamsResp, teamResponse(ctx, &teams[i]))
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Teams fetch successfully",
+		"code":    http.StatusOK,
+		"data":    teamsResp,
+	})
+}
+
+// get team (for all)
+func (c *TeamControllerImpl) GetTeamByID(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to get a team",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & teamID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	teamID := ctx.Param("team_id")
+
+	team, err := c.teamService.GetTeamByID(userClaims, courseID, projectID, teamID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusNotFound,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Team fetch successfully",
+		"code":    http.StatusOK,
+		"data":    teamResponse(ctx, team),
+	})
+}
+
+// join a self formed team (student only)
+func (c *TeamControllerImpl) JoinTeam(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to join a team",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & teamID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	teamID := ctx.Param("team_id")
+
+	team, err := c.teamService.JoinTeam(userClaims, courseID, projectID, teamID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Joined %s successfully", team.Name),
+		"data":    teamResponse(ctx, team),
+	})
+}
+
+// leave a self formed team (student only)
+func (c *TeamControllerImpl) LeaveTeam(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to leave a team",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & teamID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	teamID := ctx.Param("team_id")
+
+	if err := c.teamService.LeaveTeam(userClaims, courseID, projectID, teamID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Left TeamID %s successfully", teamID),
+	})
+}
+
+// adjust member share of the team grade (admin & mentor)
+func (c *TeamControllerImpl) AdjustMember(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to adjust member grade",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID, teamID & studentID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	teamID := ctx.Param("team_id")
+	studentID := ctx.Param("student_id")
+
+	var memberReq model.TeamMemberReq
+	if err := ctx.ShouldBindJSON(&memberReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	member, err := c.teamService.AdjustMember(userClaims, courseID, projectID, teamID, studentID, memberReq)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("Grade adjustment of StudentID %s updated", studentID),
+		"data":    teamMemberResponse(ctx, member),
+	})
+}
+
+// remove member from a team (admin & mentor)
+func (c *TeamControllerImpl) RemoveMember(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to remove a member",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID, teamID & studentID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	teamID := ctx.Param("team_id")
+	studentID := ctx.Param("student_id")
+
+	if err := c.teamService.RemoveMember(userClaims, courseID, projectID, teamID, studentID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("StudentID %s removed from TeamID %s", studentID, teamID),
+	})
+}
+
+// delete team without submissions (admin & mentor)
+func (c *TeamControllerImpl) DeleteTeam(ctx *gin.Context) {
+	// make sure user has signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "User must sign in to delete a team",
+			"code":  http.StatusUnauthorized,
+		})
+		return
+	}
+
+	// get courseID, projectID & teamID param
+	courseID := ctx.Param("course_id")
+	projectID := ctx.Param("project_id")
+	teamID := ctx.Param("team_id")
+
+	if err := c.teamService.DeleteTeam(userClaims, courseID, projectID, teamID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	// success response
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": fmt.Sprintf("TeamID %s has been deleted", teamID),
+	})
+}
@@ -17,6 +17,7 @@ type UserController interface {
 	GetUserByID(ctx *gin.Context)
 	UpdateUserRoleByID(ctx *gin.Context)
 	DeleteUserByID(ctx *gin.Context)
+	UpdateUserTimezone(ctx *gin.Context)
 }
 
 type UserControllerImpl struct {
@@ -101,8 +102,9 @@ func (c *UserControllerImpl) GetUserByID(ctx *gin.Context) {
 		Username:  user.Username,
 		Email:     user.Email,
 		Role:      string(user.Role),
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		Timezone:  user.Timezone,
+		CreatedAt: middleware.LocalTime(ctx, user.CreatedAt),
+		UpdatedAt: middleware.LocalTime(ctx, user.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
@@ -155,8 +157,9 @@ func (c *UserControllerImpl) UpdateUserRoleByID(ctx *gin.Context) {
 		Username:  userUpdate.Username,
 		Email:     userUpdate.Email,
 		Role:      string(userUpdate.Role),
-		CreatedAt: userUpdate.CreatedAt,
-		UpdatedAt: userUpdate.UpdatedAt,
+		Timezone:  userUpdate.Timezone,
+		CreatedAt: middleware.LocalTime(ctx, userUpdate.CreatedAt),
+		UpdatedAt: middleware.LocalTime(ctx, userUpdate.UpdatedAt),
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
@@ -194,3 +197,80 @@ func (c *UserControllerImpl) DeleteUserByID(ctx *gin.Context) {
 		"message": fmt.Sprintf("UserID %s has been deleted", userID),
 	})
 }
+
+// update signed in user timezone (for all)
+func (c *UserControllerImpl) UpdateUserTimezone(ctx *gin.Context) {
+	// check if the user is signed in
+	claims, _ := ctx.Get("currentUser")
+	userClaims, ok := claims.(*middleware.UserClaims)
+	if !ok {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"message": "User must sign in to update timezone",
+			"code":    http.StatusForbidden,
+		})
+		return
+	}
+
+	// validate timezone input
+	var timezoneReq model.UserTimezone
+
+	if err := ctx.ShouldBindJSON(&timezoneReq); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"code":  http.StatusBadRequest,
+		})
+		return
+	}
+
+	userUpdate, err := c.userService.UpdateUserTimezone(userClaims, timezoneReq.Timezone)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+			"code":    http.StatusBadRequest,
+		})
+		return
+	}
+
+	// refresh jwt token so new timezone is used right away
+	token, err := middleware.GenerateJWT(userUpdate.Username, userUpdate.Role, userUpdate.UserID, userUpdate.Timezone)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"message": "unable to refresh token",
+			"code":    http.StatusInternalServerError,
+		})
+		return
+	}
+
+	http.SetCookie(ctx.Writer, &http.Cookie{
+		Name:     "auth_token",
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		MaxAge:   3600,
+	})
+
+	// render response in the new timezone
+	ctx.Set("currentUser", &middleware.UserClaims{
+		UserID:   userUpdate.UserID,
+		Role:     userUpdate.Role,
+		Timezone: userUpdate.Timezone,
+	})
+
+	// succeed response
+	user := model.UserResponse{
+		UserID:    userUpdate.UserID,
+		Username:  userUpdate.Username,
+		Email:     userUpdate.Email,
+		Role:      string(userUpdate.Role),
+		Timezone:  userUpdate.Timezone,
+		CreatedAt: middleware.LocalTime(ctx, userUpdate.CreatedAt),
+		UpdatedAt: middleware.LocalTime(ctx, userUpdate.UpdatedAt),
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User timezone updated successfully",
+		"data":    user,
+	})
+}
@@ -2,17 +2,41 @@ package entity
 
 import "time"
 
+type AttendStatus string
+
+const (
+	Present AttendStatus = "present"
+	Late    AttendStatus = "late"
+	Excused AttendStatus = "excused"
+	Absent  AttendStatus = "absent"
+)
+
+// present & late count as attended
+func (s AttendStatus) Attended() bool {
+	return s == Present || s == Late
+}
+
+func (s AttendStatus) IsValid() bool {
+	switch s {
+	case Present, Late, Excused, Absent:
+		return true
+	}
+
+	return false
+}
+
 type Attendance struct {
 	AttendID uint `json:"attend_id" gorm:"primaryKey;autoIncrement"`
 
-	StudentID uint `json:"student_id" gorm:"notNull"`
+	StudentID uint `json:"student_id" gorm:"uniqueIndex:idx_attendance_student_class;notNull"`
 	Student   User `gorm:"foreignKey:StudentID"`
 
-	ClassID  uint `json:"class_id" gorm:"index;notNull"`
+	ClassID  uint `json:"class_id" gorm:"index;uniqueIndex:idx_attendance_student_class;notNull"`
 	CourseID uint `json:"course_id" gorm:"index;notNull"`
 	// CheckoutDate time.Time `json:"checkout_date"`
-	Attended bool      `json:"attended" gorm:"default:false"`
-	AttendAt time.Time `json:"join_date"`
+	Attended bool         `json:"attended" gorm:"default:false"`
+	Status   AttendStatus `json:"status" gorm:"index;default:present"`
+	AttendAt time.Time    `json:"join_date"`
 	// CreatedAt    time.Time `json:"created_at"`
 	// UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type AutoGradeStatus string
+
+const (
+	AutoGradePending AutoGradeStatus = "pending"
+	AutoGradeRunning AutoGradeStatus = "running"
+	AutoGradeDone    AutoGradeStatus = "done"
+	AutoGradeError   AutoGradeStatus = "error"
+)
+
+// outcome of one top level test of the hidden test suite
+type TestResult struct {
+	ResultID     uint    `json:"result_id" gorm:"primaryKey;autoIncrement"`
+	ProjectSubID uint    `json:"project_sub_id" gorm:"index;notNull"`
+	Package      string  `json:"package" gorm:"notNull"`
+	Test         string  `json:"test" gorm:"notNull"`
+	Status       string  `json:"status" gorm:"size:8;notNull"`
+	Elapsed      float64 `json:"elapsed" gorm:"default:0"`
+	Output       string  `json:"output" gorm:"omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
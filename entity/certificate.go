@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// names are kept as issued so the certificate doesn't change with later edits
+type Certificate struct {
+	CertificateID uint   `json:"certificate_id" gorm:"primaryKey;autoIncrement"`
+	Serial        string `json:"serial" gorm:"size:32;unique;notNull"`
+
+	EnrollmentID uint `json:"enrollment_id" gorm:"uniqueIndex;notNull"`
+	StudentID    uint `json:"student_id" gorm:"index;notNull"`
+	CourseID     uint `json:"course_id" gorm:"index;notNull"`
+
+	StudentName string    `json:"student_name" gorm:"notNull"`
+	CourseName  string    `json:"course_name" gorm:"notNull"`
+	MentorName  string    `json:"mentor_name" gorm:"notNull"`
+	CompletedAt time.Time `json:"completed_at" gorm:"notNull"`
+
+	RevokedAt    *time.Time `json:"revoked_at"`
+	RevokeReason string     `json:"revoke_reason" gorm:"omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+type CheckinCode struct {
+	CheckinCodeID uint `json:"checkin_code_id" gorm:"primaryKey;autoIncrement"`
+
+	ClassID  uint `json:"class_id" gorm:"index;notNull"`
+	CourseID uint `json:"course_id" gorm:"index;notNull"`
+
+	Code      string    `json:"code" gorm:"size:6;notNull"`
+	CreatedBy uint      `json:"created_by" gorm:"notNull"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index;notNull"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// check-in codes tried by a student for a class, capped so the 6 digit code can't be guessed
+type CheckinAttempt struct {
+	CheckinAttemptID uint `json:"checkin_attempt_id" gorm:"primaryKey;autoIncrement"`
+
+	ClassID   uint `json:"class_id" gorm:"uniqueIndex:idx_checkin_attempt_student_class;notNull"`
+	StudentID uint `json:"student_id" gorm:"uniqueIndex:idx_checkin_attempt_student_class;notNull"`
+
+	Attempts  int       `json:"attempts" gorm:"default:0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -11,6 +11,16 @@ type Class struct {
 	// MentorID uint `json:"mentor_id" gorm:"notNull"`
 	// Mentor   User `gorm:"foreignKey:MentorID"`
 
+	// physical room, empty for online only class
+	RoomID *uint `json:"room_id" gorm:"index"`
+
+	// online meeting link, only revealed to enrolled students before class start
+	MeetingURL      string `json:"-" gorm:"omitempty"`
+	MeetingProvider string `json:"meeting_provider" gorm:"omitempty"`
+
+	// set once students without attendance have been marked absent
+	AttendanceClosed bool `json:"-" gorm:"default:false"`
+
 	StartDate time.Time `json:"start_date"`
 	EndDate   time.Time `json:"end_date"`
 	CreatedAt time.Time `json:"created_at"`
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// course completion criteria, a zero value criterion is not checked
+type CompletionRule struct {
+	RuleID   uint `json:"rule_id" gorm:"primaryKey;autoIncrement"`
+	CourseID uint `json:"course_id" gorm:"uniqueIndex;notNull"`
+
+	MinAttendanceRate  float64 `json:"min_attendance_rate"`
+	MinAverageScore    float64 `json:"min_average_score"`
+	RequireAllProjects bool    `json:"require_all_projects"`
+
+	// set once the course end evaluation has run
+	EvaluatedAt *time.Time `json:"evaluated_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
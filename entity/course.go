@@ -10,6 +10,12 @@ type Course struct {
 	MentorID uint `json:"mentor_id" gorm:"notNull"`
 	Mentor   User `gorm:"foreignKey:MentorID"`
 
+	// student checking in later than this after class start is marked late
+	LateThresholdMinutes int `json:"late_threshold_minutes"`
+
+	// student can drop on their own until this many days after start date
+	DropDeadlineDays int `json:"drop_deadline_days"`
+
 	StartDate time.Time `json:"start_date"`
 	EndDate   time.Time `json:"end_date"`
 	CreatedAt time.Time `json:"created_at"`
@@ -21,3 +27,7 @@ type Course struct {
 	Projects    []Project    `gorm:"foreignKey:CourseID"`
 	// Tests       []Test       `gorm:"foreignKey:CourseID"`
 }
+
+func (c Course) DropDeadline() time.Time {
+	return c.StartDate.AddDate(0, 0, c.DropDeadlineDays)
+}
@@ -14,6 +14,36 @@ const (
 	Cancel   Status = "cancel"
 )
 
+// allowed enrollment transitions with the roles that may perform them,
+// mentor here means the mentor of the course
+var enrollTransitions = map[Status]map[Status][]Role{
+	Pending: {
+		Enroll: {Admin},
+		Cancel: {Admin, Student},
+	},
+	Enroll: {
+		Complete: {Admin, Mentor},
+		Failed:   {Admin, Mentor},
+		Cancel:   {Admin, Student},
+	},
+	Failed: {
+		Enroll: {Admin},
+	},
+}
+
+func (s Status) IsValid() bool {
+	switch s {
+	case Pending, Enroll, Complete, Failed, Cancel:
+		return true
+	}
+	return false
+}
+
+// roles allowed to move an enrollment from s to next, nil if the move is illegal
+func (s Status) TransitionRoles(next Status) []Role {
+	return enrollTransitions[s][next]
+}
+
 type Enrollment struct {
 	EnrollmentID uint `json:"enrollment_id" gorm:"primaryKey;autoIncrement"`
 
@@ -24,8 +54,15 @@ type Enrollment struct {
 	CourseID       uint      `json:"course_id" gorm:"index;notNull"`
 	EnrollmentDate time.Time `json:"enrollment_date"`
 	EnrollStatus   Status    `json:"completion_status" gorm:"default:pending"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+
+	// set when student asks to drop after the drop deadline
+	DropRequestedAt *time.Time `json:"drop_requested_at"`
+	DropReason      string     `json:"drop_reason" gorm:"omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Histories []EnrollmentHistory `json:"-" gorm:"foreignKey:EnrollmentID;constraint:OnDelete:CASCADE"`
 }
 
 // enrollment statusnya ada pending, enroll, passed, unfinished
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// actor role of transitions made by background jobs
+const SystemActor Role = "system"
+
+type EnrollmentHistory struct {
+	HistoryID    uint `json:"history_id" gorm:"primaryKey;autoIncrement"`
+	EnrollmentID uint `json:"enrollment_id" gorm:"index;notNull"`
+
+	// empty from status means enrollment was created
+	FromStatus Status    `json:"from_status"`
+	ToStatus   Status    `json:"to_status" gorm:"notNull"`
+	ActorID    uint      `json:"actor_id" gorm:"notNull"`
+	ActorRole  Role      `json:"actor_role" gorm:"notNull"`
+	Reason     string    `json:"reason" gorm:"omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (EnrollmentHistory) TableName() string {
+	return "enrollment_history"
+}
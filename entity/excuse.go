@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+type ExcuseStatus string
+
+const (
+	ExcusePending  ExcuseStatus = "pending"
+	ExcuseApproved ExcuseStatus = "approved"
+	ExcuseRejected ExcuseStatus = "rejected"
+)
+
+type Excuse struct {
+	ExcuseID uint `json:"excuse_id" gorm:"primaryKey;autoIncrement"`
+
+	StudentID uint `json:"student_id" gorm:"index;notNull"`
+	Student   User `gorm:"foreignKey:StudentID"`
+
+	ClassID  uint `json:"class_id" gorm:"index;notNull"`
+	CourseID uint `json:"course_id" gorm:"index;notNull"`
+
+	Reason string       `json:"reason" gorm:"notNull"`
+	Status ExcuseStatus `json:"status" gorm:"default:pending"`
+
+	// original file name of the supporting document, the file itself is stored under DocumentKey
+	DocumentPath string `json:"document_path" gorm:"omitempty"`
+	DocumentKey  string `json:"-" gorm:"index"`
+	ContentType  string `json:"content_type" gorm:"omitempty"`
+
+	// malware scan, the document is blocked until it's clean
+	ScanStatus    ScanStatus `json:"scan_status" gorm:"size:16;index;default:''"`
+	ScanSignature string     `json:"scan_signature" gorm:"omitempty"`
+	ScannedAt     *time.Time `json:"scanned_at"`
+
+	ReviewerID *uint      `json:"reviewer_id"`
+	ReviewNote string     `json:"review_note" gorm:"omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
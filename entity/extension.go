@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+// per-student deadline of a project granted by mentor or admin
+type DeadlineExtension struct {
+	ExtensionID uint      `json:"extension_id" gorm:"primaryKey;autoIncrement"`
+	ProjectID   uint      `json:"project_id" gorm:"uniqueIndex:idx_extension_project_student;notNull"`
+	StudentID   uint      `json:"student_id" gorm:"uniqueIndex:idx_extension_project_student;notNull"`
+	Deadline    time.Time `json:"deadline" gorm:"notNull"`
+	Reason      string    `json:"reason" gorm:"omitempty"`
+	GrantedBy   uint      `json:"granted_by" gorm:"notNull"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
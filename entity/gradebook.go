@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"sort"
+	"time"
+)
+
+// weighted group of course projects, lowest scores of a student can be dropped
+type GradeCategory struct {
+	CategoryID uint    `json:"category_id" gorm:"primaryKey;autoIncrement"`
+	CourseID   uint    `json:"course_id" gorm:"index;notNull"`
+	Name       string  `json:"name" gorm:"notNull"`
+	Weight     float64 `json:"weight" gorm:"notNull"`
+	DropLowest int     `json:"drop_lowest" gorm:"default:0"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// project left out of a student's grade
+type GradeExcusal struct {
+	ExcusalID uint      `json:"excusal_id" gorm:"primaryKey;autoIncrement"`
+	ProjectID uint      `json:"project_id" gorm:"uniqueIndex:idx_excusal_project_student;notNull"`
+	StudentID uint      `json:"student_id" gorm:"uniqueIndex:idx_excusal_project_student;notNull"`
+	Reason    string    `json:"reason" gorm:"omitempty"`
+	ExcusedBy uint      `json:"excused_by" gorm:"notNull"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// lowest total percentage that earns the letter
+type GradeLetter struct {
+	LetterID uint    `json:"letter_id" gorm:"primaryKey;autoIncrement"`
+	CourseID uint    `json:"course_id" gorm:"index;notNull"`
+	Letter   string  `json:"letter" gorm:"size:8;notNull"`
+	MinScore float64 `json:"min_score" gorm:"notNull"`
+}
+
+// used when course hasn't configured its own scale
+var DefaultGradeScale = []GradeLetter{
+	{Letter: "A", MinScore: 90},
+	{Letter: "B", MinScore: 80},
+	{Letter: "C", MinScore: 70},
+	{Letter: "D", MinScore: 60},
+	{Letter: "E", MinScore: 0},
+}
+
+// letter of the highest threshold the total reaches
+func LetterGrade(scale []GradeLetter, total float64) string {
+	letters := append([]GradeLetter(nil), scale...)
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].MinScore > letters[j].MinScore
+	})
+
+	for _, letter := range letters {
+		if total >= letter.MinScore {
+			return letter.Letter
+		}
+	}
+	return ""
+}
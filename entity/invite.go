@@ -0,0 +1,52 @@
+package entity
+
+import "time"
+
+type CourseInvite struct {
+	InviteID uint `json:"invite_id" gorm:"primaryKey;autoIncrement"`
+	CourseID uint `json:"course_id" gorm:"index;notNull"`
+
+	Code      string `json:"code" gorm:"size:16;unique;notNull"`
+	CreatedBy uint   `json:"created_by" gorm:"notNull"`
+
+	// nil expiry never expires & zero max uses is unlimited
+	ExpiresAt   *time.Time `json:"expires_at"`
+	MaxUses     int        `json:"max_uses" gorm:"default:0"`
+	UsedCount   int        `json:"used_count" gorm:"default:0"`
+	EmailDomain string     `json:"email_domain" gorm:"omitempty"`
+
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	Redemptions []InviteRedemption `gorm:"foreignKey:InviteID;constraint:OnDelete:CASCADE"`
+}
+
+type InviteRedemption struct {
+	RedemptionID uint      `json:"redemption_id" gorm:"primaryKey;autoIncrement"`
+	InviteID     uint      `json:"invite_id" gorm:"index;notNull"`
+	StudentID    uint      `json:"student_id" gorm:"notNull"`
+	EnrollmentID uint      `json:"enrollment_id" gorm:"notNull"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type InviteStatus string
+
+const (
+	InviteActive    InviteStatus = "active"
+	InviteExpired   InviteStatus = "expired"
+	InviteExhausted InviteStatus = "exhausted"
+	InviteRevoked   InviteStatus = "revoked"
+)
+
+func (i CourseInvite) Status(now time.Time) InviteStatus {
+	switch {
+	case i.RevokedAt != nil:
+		return InviteRevoked
+	case i.ExpiresAt != nil && !now.Before(*i.ExpiresAt):
+		return InviteExpired
+	case i.MaxUses > 0 && i.UsedCount >= i.MaxUses:
+		return InviteExhausted
+	}
+	return InviteActive
+}
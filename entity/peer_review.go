@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// anonymous review of a submission by another student of the course
+type PeerReview struct {
+	ReviewID     uint `json:"review_id" gorm:"primaryKey;autoIncrement"`
+	ProjectID    uint `json:"project_id" gorm:"index;notNull"`
+	ProjectSubID uint `json:"project_sub_id" gorm:"uniqueIndex:idx_review_sub_reviewer;notNull"`
+	ReviewerID   uint `json:"reviewer_id" gorm:"uniqueIndex:idx_review_sub_reviewer;notNull"`
+
+	// score out of 100, from the rubric when project has one
+	Score       *float64   `json:"score"`
+	Comment     string     `json:"comment" gorm:"omitempty"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+
+	// review quality rated by mentor, 1-5
+	Quality     *int   `json:"quality"`
+	QualityNote string `json:"quality_note" gorm:"omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Grades []PeerCriterionGrade `gorm:"foreignKey:ReviewID;constraint:OnDelete:CASCADE"`
+}
+
+// level picked by reviewer for one rubric criterion
+type PeerCriterionGrade struct {
+	GradeID     uint   `json:"grade_id" gorm:"primaryKey;autoIncrement"`
+	ReviewID    uint   `json:"review_id" gorm:"uniqueIndex:idx_peer_grade_review_criterion;notNull"`
+	CriterionID uint   `json:"criterion_id" gorm:"uniqueIndex:idx_peer_grade_review_criterion;notNull"`
+	LevelID     uint   `json:"level_id" gorm:"notNull"`
+	Points      int    `json:"points" gorm:"notNull"`
+	Comment     string `json:"comment" gorm:"omitempty"`
+}
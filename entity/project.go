@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"math"
+	"strings"
+	"time"
+)
 
 type Project struct {
 	ProjectID   uint      `json:"project_id" gorm:"primaryKey;autoIncrement"`
@@ -8,21 +12,182 @@ type Project struct {
 	ProjectName string    `json:"project_name" gorm:"notNull"`
 	Description string    `json:"description" gorm:"omitempty"`
 	Deadline    time.Time `json:"deadline" gorm:"notNull"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
 
-	// one-to-one
-	ProjectSub ProjectSub `gorm:"constraint:OnDelete:CASCADE;"`
+	// optional project isn't required for course completion
+	Optional bool `json:"optional" gorm:"default:false"`
+
+	// attempts a student may submit, 0 means unlimited
+	MaxAttempts int `json:"max_attempts" gorm:"default:0"`
+
+	// how submissions after the deadline are handled, penalty is a percentage of the score
+	LatePolicy    LatePolicy `json:"late_policy" gorm:"size:16;default:cutoff"`
+	GraceHours    int        `json:"grace_hours" gorm:"default:0"`
+	PenaltyPerDay float64    `json:"penalty_per_day" gorm:"default:0"`
+	PenaltyCap    float64    `json:"penalty_cap" gorm:"default:100"`
+
+	// score is computed from the rubric when set
+	RubricID *uint `json:"rubric_id" gorm:"index"`
+
+	// gradebook category, project isn't weighted when course has categories but project has none
+	CategoryID *uint `json:"category_id" gorm:"index"`
+
+	// team project is submitted once per team, students may form their own teams when allowed
+	TeamProject     bool `json:"team_project" gorm:"default:false"`
+	MinTeamSize     int  `json:"min_team_size" gorm:"default:1"`
+	MaxTeamSize     int  `json:"max_team_size" gorm:"default:0"`
+	SelfFormedTeams bool `json:"self_formed_teams" gorm:"default:false"`
+
+	// storage key of the hidden go test suite zip, submissions are zips of go source graded by it in a sandbox
+	TestSuiteKey string `json:"-" gorm:"omitempty"`
+	TestTimeout  int    `json:"test_timeout" gorm:"default:120"`
+
+	// comma separated extensions a submission may have & its max size in mb
+	AllowedFileTypes string `json:"allowed_file_types" gorm:"default:'.jpeg,.jpg,.pdf,.png,.zip'"`
+	MaxFileSize      int    `json:"max_file_size" gorm:"default:10"`
+
+	// after the deadline every submission is reviewed by peer_reviewers students,
+	// peer weight is the percentage of the score taken from the peer review average
+	PeerReviewEnabled  bool       `json:"peer_review_enabled" gorm:"default:false"`
+	PeerReviewers      int        `json:"peer_reviewers" gorm:"default:0"`
+	PeerReviewDeadline *time.Time `json:"peer_review_deadline"`
+	PeerWeight         float64    `json:"peer_weight" gorm:"default:0"`
+	PeerAssignedAt     *time.Time `json:"peer_assigned_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// one-to-many, every attempt of every student
+	ProjectSubs []ProjectSub `gorm:"constraint:OnDelete:CASCADE;"`
 }
 
 type ProjectSub struct {
-	ProjectSubID   uint      `json:"project_sub_id" gorm:"primaryKey;autoIncrement"`
-	ProjectID      uint      `json:"project_id" gorm:"index;notNull"`
-	StudentID      uint      `json:"student_id" gorm:"index;notNull"`
+	ProjectSubID uint `json:"project_sub_id" gorm:"primaryKey;autoIncrement"`
+	ProjectID    uint `json:"project_id" gorm:"index;notNull"`
+	StudentID    uint `json:"student_id" gorm:"index;notNull"`
+
+	// team submission, StudentID is the member who submitted
+	TeamID *uint `json:"team_id" gorm:"index"`
+
+	// attempt number per project & student (or team), only the latest attempt is graded
+	Attempt  int  `json:"attempt" gorm:"index;default:0"`
+	IsLatest bool `json:"is_latest" gorm:"index;default:false"`
+
+	// late penalty percentage is kept apart from the raw score given by mentor
+	DaysLate    int     `json:"days_late" gorm:"default:0"`
+	LatePenalty float64 `json:"late_penalty" gorm:"default:0"`
+
+	// per criterion grading when project has a rubric
+	Grades []CriterionGrade `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+
+	// reviews by other students & the mentor score before peer score was blended in
+	PeerReviews []PeerReview `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	MentorScore *int         `json:"mentor_score"`
+	PeerScore   *float64     `json:"peer_score"`
+
 	SubmissionDate time.Time `json:"submission_date" gorm:"notNull"`
 	Score          int       `json:"score" gorm:"default:0"`
 	Description    string    `json:"description" gorm:"omitempty"`
-	ProjectPath    string    `json:"project_path" gorm:"notNull"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+
+	// set once the mentor has scored the attempt, auto & peer scores alone don't grade it
+	GradedAt *time.Time `json:"graded_at" gorm:"index"`
+
+	// original file name shown to users, the file itself is stored under FileKey
+	ProjectPath string `json:"project_path" gorm:"notNull"`
+	FileKey     string `json:"-" gorm:"index"`
+	FileSize    int64  `json:"file_size" gorm:"default:0"`
+	ContentType string `json:"content_type" gorm:"omitempty"`
+
+	// malware scan, the file is blocked until it's clean
+	ScanStatus    ScanStatus `json:"scan_status" gorm:"size:16;index;default:''"`
+	ScanSignature string     `json:"scan_signature" gorm:"omitempty"`
+	ScannedAt     *time.Time `json:"scanned_at"`
+
+	// sha256 of the submitted file, the similarity analyzer fills it for older submissions
+	FileHash string `json:"file_hash" gorm:"size:64;index"`
+
+	// test suite run, auto score becomes the score until mentor overrides it
+	AutoStatus     AutoGradeStatus `json:"auto_status" gorm:"size:16;index;default:''"`
+	AutoScore      *int            `json:"auto_score"`
+	AutoFeedback   string          `json:"auto_feedback" gorm:"omitempty"`
+	AutoStartedAt  *time.Time      `json:"auto_started_at"`
+	AutoGradedAt   *time.Time      `json:"auto_graded_at"`
+	AutoOverridden bool            `json:"auto_overridden" gorm:"default:false"`
+	TestResults    []TestResult    `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LatePolicy string
+
+const (
+	// no submission after the deadline
+	LateCutoff LatePolicy = "cutoff"
+	// accepted without penalty until grace hours pass
+	LateGrace LatePolicy = "grace"
+	// accepted with penalty per started day late, up to the cap
+	LatePenalty LatePolicy = "penalty"
+)
+
+func (p LatePolicy) IsValid() bool {
+	switch p {
+	case LateCutoff, LateGrace, LatePenalty:
+		return true
+	}
+	return false
+}
+
+// days late & penalty percentage of a submission, ok is false when it's no longer accepted
+func (p Project) LateOutcome(deadline, submittedAt time.Time) (daysLate int, penalty float64, ok bool) {
+	if !submittedAt.After(deadline) {
+		return 0, 0, true
+	}
+
+	late := submittedAt.Sub(deadline)
+	daysLate = int(math.Ceil(late.Hours() / 24))
+
+	switch p.LatePolicy {
+	case LateGrace:
+		if late > time.Duration(p.GraceHours)*time.Hour {
+			return daysLate, 0, false
+		}
+		return daysLate, 0, true
+	case LatePenalty:
+		return daysLate, math.Min(float64(daysLate)*p.PenaltyPerDay, p.PenaltyCap), true
+	}
+	return daysLate, 0, false
+}
+
+// peer reviews are assigned once no more submission is accepted, granted extensions push it back
+func (p Project) PeerReviewStart(extensions ...DeadlineExtension) time.Time {
+	deadline := p.Deadline
+	for _, extension := range extensions {
+		if extension.Deadline.After(deadline) {
+			deadline = extension.Deadline
+		}
+	}
+
+	switch p.LatePolicy {
+	case LateGrace:
+		return deadline.Add(time.Duration(p.GraceHours) * time.Hour)
+	case LatePenalty:
+		// late submissions stay open until the penalty reaches the cap
+		if p.PenaltyPerDay > 0 {
+			days := math.Ceil(p.PenaltyCap / p.PenaltyPerDay)
+			return deadline.Add(time.Duration(days) * 24 * time.Hour)
+		}
+	}
+	return deadline
+}
+
+func (p Project) FileTypes() []string {
+	if p.AllowedFileTypes == "" {
+		return nil
+	}
+	return strings.Split(p.AllowedFileTypes, ",")
+}
+
+// raw score with late penalty applied
+func (s ProjectSub) FinalScore() float64 {
+	return math.Round(float64(s.Score)*(100-s.LatePenalty)) / 100
 }
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeerReviewStart(t *testing.T) {
+	deadline := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name       string
+		project    Project
+		extensions []DeadlineExtension
+		want       time.Time
+	}{
+		{"cutoff", Project{Deadline: deadline, LatePolicy: LateCutoff}, nil, deadline},
+		{"grace", Project{Deadline: deadline, LatePolicy: LateGrace, GraceHours: 6}, nil, deadline.Add(6 * time.Hour)},
+		{"penalty reaches cap", Project{Deadline: deadline, LatePolicy: LatePenalty, PenaltyPerDay: 10, PenaltyCap: 30}, nil, deadline.Add(3 * day)},
+		{"penalty cap between days", Project{Deadline: deadline, LatePolicy: LatePenalty, PenaltyPerDay: 20, PenaltyCap: 50}, nil, deadline.Add(3 * day)},
+		{"penalty without cap", Project{Deadline: deadline, LatePolicy: LatePenalty, PenaltyPerDay: 25, PenaltyCap: 100}, nil, deadline.Add(4 * day)},
+		{"extension", Project{Deadline: deadline, LatePolicy: LateCutoff}, []DeadlineExtension{{Deadline: deadline.Add(day)}, {Deadline: deadline.Add(-day)}}, deadline.Add(day)},
+		{"extension with penalty", Project{Deadline: deadline, LatePolicy: LatePenalty, PenaltyPerDay: 50, PenaltyCap: 100}, []DeadlineExtension{{Deadline: deadline.Add(day)}}, deadline.Add(3 * day)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := tt.project.PeerReviewStart(tt.extensions...)
+			if !start.Equal(tt.want) {
+				t.Fatalf("got %v, want %v", start, tt.want)
+			}
+
+			// penalty submissions are open until the penalty is capped
+			if tt.project.LatePolicy == LatePenalty && len(tt.extensions) == 0 {
+				if _, penalty, _ := tt.project.LateOutcome(tt.project.Deadline, start); penalty != tt.project.PenaltyCap {
+					t.Fatalf("got penalty %v at peer review start, want the cap %v", penalty, tt.project.PenaltyCap)
+				}
+				if _, penalty, _ := tt.project.LateOutcome(tt.project.Deadline, start.Add(-24*time.Hour)); penalty >= tt.project.PenaltyCap {
+					t.Fatalf("penalty is capped a day before peer review starts")
+				}
+			}
+		})
+	}
+}
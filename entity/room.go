@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type Room struct {
+	RoomID    uint      `json:"room_id" gorm:"primaryKey;autoIncrement"`
+	RoomName  string    `json:"room_name" gorm:"unique;notNull"`
+	Location  string    `json:"location" gorm:"omitempty"`
+	Capacity  int       `json:"capacity" gorm:"notNull"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Classes []Class `gorm:"foreignKey:RoomID"`
+}
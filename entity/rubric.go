@@ -0,0 +1,60 @@
+package entity
+
+import "time"
+
+// reusable grading rubric, any project of any course can use it
+type Rubric struct {
+	RubricID    uint      `json:"rubric_id" gorm:"primaryKey;autoIncrement"`
+	Title       string    `json:"title" gorm:"notNull"`
+	Description string    `json:"description" gorm:"omitempty"`
+	CreatedBy   uint      `json:"created_by" gorm:"index;notNull"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Criteria []RubricCriterion `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+type RubricCriterion struct {
+	CriterionID uint   `json:"criterion_id" gorm:"primaryKey;autoIncrement"`
+	RubricID    uint   `json:"rubric_id" gorm:"index;notNull"`
+	Title       string `json:"title" gorm:"notNull"`
+	Description string `json:"description" gorm:"omitempty"`
+	Position    int    `json:"position" gorm:"notNull"`
+
+	Levels []RubricLevel `gorm:"foreignKey:CriterionID;constraint:OnDelete:CASCADE"`
+}
+
+// point level of a criterion with what the work looks like at that level
+type RubricLevel struct {
+	LevelID     uint   `json:"level_id" gorm:"primaryKey;autoIncrement"`
+	CriterionID uint   `json:"criterion_id" gorm:"index;notNull"`
+	Points      int    `json:"points" gorm:"notNull"`
+	Descriptor  string `json:"descriptor" gorm:"notNull"`
+}
+
+// highest points a submission can get from the rubric
+func (r Rubric) MaxPoints() int {
+	total := 0
+	for _, criterion := range r.Criteria {
+		best := 0
+		for _, level := range criterion.Levels {
+			if level.Points > best {
+				best = level.Points
+			}
+		}
+		total += best
+	}
+	return total
+}
+
+// level picked by mentor for one criterion of a submission
+type CriterionGrade struct {
+	GradeID      uint      `json:"grade_id" gorm:"primaryKey;autoIncrement"`
+	ProjectSubID uint      `json:"project_sub_id" gorm:"uniqueIndex:idx_grade_sub_criterion;notNull"`
+	CriterionID  uint      `json:"criterion_id" gorm:"uniqueIndex:idx_grade_sub_criterion;notNull"`
+	LevelID      uint      `json:"level_id" gorm:"notNull"`
+	Points       int       `json:"points" gorm:"notNull"`
+	Comment      string    `json:"comment" gorm:"omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+// malware scan of an uploaded file, empty when the file was never queued for a scan
+type ScanStatus string
+
+const (
+	// quarantined until the scanner has seen it
+	ScanPending  ScanStatus = "pending"
+	ScanClean    ScanStatus = "clean"
+	ScanInfected ScanStatus = "infected"
+	// scanner couldn't check the file, it stays blocked
+	ScanError ScanStatus = "error"
+)
+
+// file may be downloaded & processed
+func (s ScanStatus) Released() bool {
+	return s == "" || s == ScanClean
+}
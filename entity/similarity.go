@@ -0,0 +1,56 @@
+package entity
+
+import "time"
+
+type ReportStatus string
+
+const (
+	ReportRunning ReportStatus = "running"
+	ReportDone    ReportStatus = "done"
+	ReportFailed  ReportStatus = "failed"
+)
+
+// similarity analysis of the latest submissions of a project, only course staff can see it
+type SimilarityReport struct {
+	ReportID  uint         `json:"report_id" gorm:"primaryKey;autoIncrement"`
+	ProjectID uint         `json:"project_id" gorm:"index;notNull"`
+	Status    ReportStatus `json:"status" gorm:"size:16;notNull"`
+
+	// pairs at or above the threshold are reported, exact duplicates always are
+	Threshold float64 `json:"threshold" gorm:"notNull"`
+
+	// submissions compared, skipped ones have a missing file or no readable text,
+	// a file without text is still checked for exact duplicates
+	Analyzed int    `json:"analyzed" gorm:"default:0"`
+	Skipped  int    `json:"skipped" gorm:"default:0"`
+	Error    string `json:"error" gorm:"omitempty"`
+
+	// 0 when started by the background job
+	RequestedBy uint       `json:"requested_by"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Pairs []SimilarityPair `gorm:"foreignKey:ReportID;constraint:OnDelete:CASCADE"`
+}
+
+// two suspiciously similar submissions of a report
+type SimilarityPair struct {
+	PairID         uint    `json:"pair_id" gorm:"primaryKey;autoIncrement"`
+	ReportID       uint    `json:"report_id" gorm:"index;notNull"`
+	ProjectSubID   uint    `json:"project_sub_id" gorm:"notNull"`
+	StudentID      uint    `json:"student_id" gorm:"notNull"`
+	MatchSubID     uint    `json:"match_sub_id" gorm:"notNull"`
+	MatchStudentID uint    `json:"match_student_id" gorm:"notNull"`
+	ExactDuplicate bool    `json:"exact_duplicate" gorm:"default:false"`
+	Similarity     float64 `json:"similarity" gorm:"notNull"`
+
+	Segments []SimilaritySegment `gorm:"foreignKey:PairID;constraint:OnDelete:CASCADE"`
+}
+
+// text found in both submissions of a pair
+type SimilaritySegment struct {
+	SegmentID uint   `json:"segment_id" gorm:"primaryKey;autoIncrement"`
+	PairID    uint   `json:"pair_id" gorm:"index;notNull"`
+	Text      string `json:"text" gorm:"notNull"`
+	Words     int    `json:"words" gorm:"notNull"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// group of students working on one team project
+type Team struct {
+	TeamID    uint      `json:"team_id" gorm:"primaryKey;autoIncrement"`
+	ProjectID uint      `json:"project_id" gorm:"index;notNull"`
+	Name      string    `json:"name" gorm:"notNull"`
+	CreatedBy uint      `json:"created_by" gorm:"notNull"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Members []TeamMember `gorm:"constraint:OnDelete:CASCADE"`
+}
+
+// a student joins at most one team per project
+type TeamMember struct {
+	MemberID  uint `json:"member_id" gorm:"primaryKey;autoIncrement"`
+	TeamID    uint `json:"team_id" gorm:"index;notNull"`
+	ProjectID uint `json:"project_id" gorm:"uniqueIndex:idx_member_project_student;notNull"`
+	StudentID uint `json:"student_id" gorm:"uniqueIndex:idx_member_project_student;notNull"`
+
+	// points added to or taken from the team score for this member
+	Adjustment float64   `json:"adjustment" gorm:"default:0"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// team score with member adjustment, kept within 0-100
+func (m TeamMember) AdjustedScore(score float64) float64 {
+	return math.Max(0, math.Min(100, math.Round((score+m.Adjustment)*100)/100))
+}
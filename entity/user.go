@@ -18,7 +18,13 @@ type User struct {
 	Email     string    `json:"email" gorm:"notNull;unique"`
 	Password  string    `json:"password" gorm:"notNull"`
 	Role      Role      `json:"role" gorm:"default:student"`
+	Timezone  string    `json:"timezone" gorm:"omitempty"`
 	CreatedAt time.Time `json:"created_at"`
+
+	// single use set password link of imported accounts, only the sha256 of the token is kept
+	PasswordTokenHash      string     `json:"-" gorm:"size:64;index"`
+	PasswordTokenExpiresAt *time.Time `json:"-"`
+
 	UpdatedAt time.Time `json:"updated_at"`
 
 	Enrollments []Enrollment `gorm:"foreignKey:StudentID;references:UserID;constraint:OnUpdate:CASCADE"`
@@ -0,0 +1,20 @@
+package job
+
+import (
+	"log"
+	"time"
+)
+
+// run task every interval in background, errors are logged and the next tick retries
+func Schedule(name string, interval time.Duration, task func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := task(); err != nil {
+				log.Printf("Job %s failed: %v", name, err)
+			}
+		}
+	}()
+}
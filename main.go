@@ -2,13 +2,19 @@ package main
 
 import (
 	"log"
+	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nadyafa/go-learn/autograde"
 	"github.com/nadyafa/go-learn/config/db"
 	"github.com/nadyafa/go-learn/controller"
+	"github.com/nadyafa/go-learn/job"
+	"github.com/nadyafa/go-learn/malware"
 	"github.com/nadyafa/go-learn/middleware"
 	"github.com/nadyafa/go-learn/repository"
 	"github.com/nadyafa/go-learn/service"
+	"github.com/nadyafa/go-learn/storage"
 )
 
 func main() {
@@ -31,7 +37,21 @@ func main() {
 	}
 
 	// db migration
-	db.RunMigration(dbInit)
+	if err := db.RunMigration(dbInit); err != nil {
+		log.Fatalf("Unable migrating DB: %v", err)
+	}
+
+	// file storage of submissions & test suites
+	store, err := storage.NewStorage(middleware.AppURL())
+	if err != nil {
+		log.Fatalf("Unable initializing storage: %v", err)
+	}
+
+	// malware scanner of uploaded submissions, nil when scanning is disabled
+	scanner, err := malware.NewScanner()
+	if err != nil {
+		log.Fatalf("Unable initializing malware scanner: %v", err)
+	}
 
 	// setup route
 	r := gin.Default()
@@ -49,12 +69,20 @@ func main() {
 	couserService := service.NewCourseService(courseRepo)
 	courseController := controller.NewCourseController(couserService)
 
+	inviteRepo := repository.NewInviteRepo(dbInit)
+	inviteService := service.NewInviteService(inviteRepo, courseRepo)
+	inviteController := controller.NewInviteController(inviteService)
+
 	enrollRepo := repository.NewEnrollRepo(dbInit)
-	enrollService := service.NewEnrollService(courseRepo, enrollRepo, userRepo)
+	enrollService := service.NewEnrollService(courseRepo, enrollRepo, userRepo, inviteRepo, store)
 	enrollController := controller.NewEnrollController(enrollService)
 
+	roomRepo := repository.NewRoomRepo(dbInit)
+	roomService := service.NewRoomService(roomRepo)
+	roomController := controller.NewRoomController(roomService)
+
 	classRepo := repository.NewClassRepo(dbInit)
-	classService := service.NewClassService(classRepo, courseRepo)
+	classService := service.NewClassService(classRepo, courseRepo, roomRepo, enrollRepo, middleware.NewMeetingProvider())
 	classController := controller.NewClassController(classService)
 
 	attendRepo := repository.NewAttendRepo(dbInit)
@@ -62,18 +90,102 @@ func main() {
 	attendanceController := controller.NewAttendController(attendService)
 
 	projectRepo := repository.NewProjectRepo(dbInit)
-	projectService := service.NewProjectService(projectRepo, courseRepo)
+	rubricRepo := repository.NewRubricRepo(dbInit)
+	gradebookRepo := repository.NewGradebookRepo(dbInit)
+	rubricService := service.NewRubricService(rubricRepo, projectRepo)
+	rubricController := controller.NewRubricController(rubricService)
+
+	projectService := service.NewProjectService(projectRepo, courseRepo, rubricRepo, gradebookRepo, store)
 	projectController := controller.NewProjectController(projectService)
 
-	projectSubController := controller.NewProjectSubController(dbInit)
+	extensionRepo := repository.NewExtensionRepo(dbInit)
+	extensionService := service.NewExtensionService(extensionRepo, projectRepo, courseRepo, enrollRepo)
+	extensionController := controller.NewExtensionController(extensionService)
+
+	teamRepo := repository.NewTeamRepo(dbInit)
+	teamService := service.NewTeamService(teamRepo, projectRepo, courseRepo, enrollRepo)
+	teamController := controller.NewTeamController(teamService)
+
+	peerReviewRepo := repository.NewPeerReviewRepo(dbInit)
+
+	projectSubRepo := repository.NewProjectSubRepo(dbInit)
+	projectSubService := service.NewProjectSubService(projectSubRepo, projectRepo, courseRepo, enrollRepo, extensionRepo, rubricRepo, teamRepo, peerReviewRepo, store, scanner)
+	projectSubController := controller.NewProjectSubController(projectSubService)
+
+	peerReviewService := service.NewPeerReviewService(peerReviewRepo, projectSubRepo, projectRepo, courseRepo, enrollRepo, teamRepo, rubricRepo, extensionRepo, store)
+	peerReviewController := controller.NewPeerReviewController(peerReviewService)
+
+	similarityRepo := repository.NewSimilarityRepo(dbInit)
+	similarityService := service.NewSimilarityService(similarityRepo, projectSubRepo, projectRepo, courseRepo, store)
+	similarityController := controller.NewSimilarityController(similarityService)
+
+	autogradeRepo := repository.NewAutogradeRepo(dbInit)
+	autogradeService := service.NewAutogradeService(autogradeRepo, projectSubRepo, projectRepo, courseRepo, autograde.NewSandbox(), store)
+	autogradeController := controller.NewAutogradeController(autogradeService)
+
+	scanRepo := repository.NewScanRepo(dbInit)
+	scanService := service.NewScanService(scanRepo, projectSubRepo, projectRepo, store, scanner)
+	scanController := controller.NewScanController(scanService)
+
+	excuseRepo := repository.NewExcuseRepo(dbInit)
+	excuseService := service.NewExcuseService(excuseRepo, courseRepo, classRepo, enrollRepo, userRepo, store, scanner)
+	excuseController := controller.NewExcuseController(excuseService)
+
+	reportRepo := repository.NewReportRepo(dbInit)
+	reportService := service.NewReportService(reportRepo, courseRepo)
+	reportController := controller.NewReportController(reportService)
+
+	certificateRepo := repository.NewCertificateRepo(dbInit)
+	certificateService := service.NewCertificateService(certificateRepo, courseRepo, enrollRepo, userRepo)
+	certificateController := controller.NewCertificateController(certificateService)
+
+	completionRepo := repository.NewCompletionRepo(dbInit)
+	completionService := service.NewCompletionService(completionRepo, courseRepo, enrollRepo)
+	completionController := controller.NewCompletionController(completionService)
+
+	gradebookService := service.NewGradebookService(gradebookRepo, courseRepo, projectRepo, enrollRepo)
+	gradebookController := controller.NewGradebookController(gradebookService)
+
+	fileService := service.NewFileService(store)
+	fileController := controller.NewFileController(fileService)
+
+	// background jobs
+	job.Schedule("mark absent students", 10*time.Minute, func() error {
+		_, err := attendService.MarkAbsentStudents()
+		return err
+	})
+	job.Schedule("evaluate course completion", time.Hour, func() error {
+		_, err := completionService.EvaluateEndedCourses()
+		return err
+	})
+	job.Schedule("assign peer reviews", time.Hour, func() error {
+		_, err := peerReviewService.AssignDueReviews()
+		return err
+	})
+	job.Schedule("analyze submission similarity", 6*time.Hour, func() error {
+		_, err := similarityService.AnalyzeDueProjects()
+		return err
+	})
+	job.Schedule("grade go submissions", time.Minute, func() error {
+		_, err := autogradeService.RunPending()
+		return err
+	})
+	if scanner != nil {
+		job.Schedule("scan uploaded files", time.Minute, func() error {
+			_, err := scanService.RunPending()
+			return err
+		})
+	}
 
 	// auth
 	r.POST("/signup", authController.UserSignup)
 	r.POST("/signin", authController.UserSignin)
 	r.POST("/signout", authController.UserSignout)
+	r.POST("/set-password", authController.SetPassword)
 
 	// user
 	userController.GenerateAdmin()
+	r.PUT("/me/timezone", middleware.AuthMiddleware, userController.UpdateUserTimezone)
 	// admin only
 	r.GET("/users", middleware.AuthMiddleware, userController.GetUsers)
 	r.PUT("/users/:user_id", middleware.AuthMiddleware, userController.UpdateUserRoleByID)
@@ -88,35 +200,144 @@ func main() {
 	r.PUT("/courses/:course_id", middleware.AuthMiddleware, courseController.UpdateCourseByID)    //admin & mentor
 	r.DELETE("/courses/:course_id", middleware.AuthMiddleware, courseController.DeleteCourseByID) //admin only
 
+	// room
+	r.POST("/rooms", middleware.AuthMiddleware, roomController.CreateRoom) //admin only
+	r.GET("/rooms", middleware.AuthMiddleware, roomController.GetRooms)
+	r.GET("/rooms/:room_id", middleware.AuthMiddleware, roomController.GetRoomByID)
+	r.PUT("/rooms/:room_id", middleware.AuthMiddleware, roomController.UpdateRoomByID)    //admin only
+	r.DELETE("/rooms/:room_id", middleware.AuthMiddleware, roomController.DeleteRoomByID) //admin only
+
 	// class
 	r.POST("/:course_id/classes", middleware.AuthMiddleware, classController.CreateClass) //admin & mentor
 	r.GET("/:course_id/classes", middleware.AuthMiddleware, classController.GetClasses)
 	r.GET("/:course_id/classes/:class_id", middleware.AuthMiddleware, classController.GetClassByID)
 	r.PUT("/:course_id/classes/:class_id", middleware.AuthMiddleware, classController.UpdateClassByID)    //admin & mentor
 	r.DELETE("/:course_id/classes/:class_id", middleware.AuthMiddleware, classController.DeleteClassByID) //admin & mentor
+	r.GET("/:course_id/classes/:class_id/meeting", middleware.AuthMiddleware, classController.GetClassMeeting)
 
 	// project
 	r.POST("/:course_id/projects", middleware.AuthMiddleware, projectController.CreateProject) //admin & mentor
 	r.GET("/:course_id/projects", middleware.AuthMiddleware, projectController.GetProjects)
 	r.GET("/:course_id/projects/:project_id", middleware.AuthMiddleware, projectController.GetProjectByID)
-	r.PUT("/:course_id/projects/:project_id", middleware.AuthMiddleware, projectController.UpdateProjectByID)    //admin & mentor
-	r.DELETE("/:course_id/projects/:project_id", middleware.AuthMiddleware, projectController.DeleteProjectByID) //admin & mentor
+	r.PUT("/:course_id/projects/:project_id", middleware.AuthMiddleware, projectController.UpdateProjectByID)                                  //admin & mentor
+	r.DELETE("/:course_id/projects/:project_id", middleware.AuthMiddleware, projectController.DeleteProjectByID)                               //admin & mentor
+	r.PUT("/:course_id/projects/:project_id/test-suite", middleware.AuthMiddleware, middleware.UploadLimit, projectController.UploadTestSuite) //admin & mentor
+	r.DELETE("/:course_id/projects/:project_id/test-suite", middleware.AuthMiddleware, projectController.RemoveTestSuite)                      //admin & mentor
+	r.POST("/:course_id/projects/:project_id/autograde", middleware.AuthMiddleware, autogradeController.RegradeProject)                        //admin & mentor
+	r.GET("/:course_id/projects/:project_id/rubric", middleware.AuthMiddleware, rubricController.GetProjectRubric)
+
+	// rubric
+	r.POST("/rubrics", middleware.AuthMiddleware, rubricController.CreateRubric)              //admin & mentor
+	r.GET("/rubrics", middleware.AuthMiddleware, rubricController.GetRubrics)                 //admin & mentor
+	r.GET("/rubrics/:rubric_id", middleware.AuthMiddleware, rubricController.GetRubricByID)   //admin & mentor
+	r.PUT("/rubrics/:rubric_id", middleware.AuthMiddleware, rubricController.UpdateRubric)    //creator & admin
+	r.DELETE("/rubrics/:rubric_id", middleware.AuthMiddleware, rubricController.DeleteRubric) //creator & admin
 
 	// projectSub
-	r.POST("/:course_id/projects/:project_id/submission", middleware.AuthMiddleware, projectSubController.StudentSubmitProject)             //student only
-	r.PUT("/:course_id/projects/:project_id/submission/:project_sub_id", middleware.AuthMiddleware, projectSubController.MentorSubmitScore) //admin & mentor
-	// r.GET("/:course_id/projects/:project_id/submission", middleware.AuthMiddleware, projectSubController.GetProjectSubmissions) //for all
-	// r.GET("/:course_id/projects/:project_id/submission/:project_sub_id", middleware.AuthMiddleware, projectSubController.GetProjectSubmissionByID) //for all
-	// r.DELETE("/:course_id/projects/:project_id/submission/:project_sub_id", middleware.AuthMiddleware, projectSubController.DeleteProjectSubmissionByID) //admin only
+	r.POST("/:course_id/projects/:project_id/submission", middleware.AuthMiddleware, middleware.UploadLimit, projectSubController.StudentSubmitProject) //student only
+	r.PUT("/:course_id/projects/:project_id/submission/:project_sub_id", middleware.AuthMiddleware, projectSubController.MentorSubmitScore)             //admin & mentor
+	r.GET("/:course_id/projects/:project_id/submission", middleware.AuthMiddleware, projectSubController.GetProjectSubmissions)                         //for all
+	r.GET("/:course_id/projects/:project_id/submission/:project_sub_id", middleware.AuthMiddleware, projectSubController.GetProjectSubmissionByID)      //for all
+	r.GET("/:course_id/projects/:project_id/submission/:project_sub_id/history", middleware.AuthMiddleware, projectSubController.GetProjectSubmissionHistory)
+	r.GET("/:course_id/projects/:project_id/submission/:project_sub_id/file", middleware.AuthMiddleware, projectSubController.DownloadProjectSubmission) //for all
+	r.DELETE("/:course_id/projects/:project_id/submission/:project_sub_id", middleware.AuthMiddleware, projectSubController.DeleteProjectSubmissionByID) //admin only
+	r.GET("/:course_id/projects/:project_id/submission/:project_sub_id/peer-reviews", middleware.AuthMiddleware, projectSubController.GetSubmissionPeerReviews)
+	r.GET("/:course_id/projects/:project_id/submission/:project_sub_id/tests", middleware.AuthMiddleware, projectSubController.GetSubmissionTestResults)
+	r.POST("/:course_id/projects/:project_id/submission/:project_sub_id/autograde", middleware.AuthMiddleware, autogradeController.RegradeSubmission) //admin & mentor
+	r.POST("/:course_id/projects/:project_id/submission/:project_sub_id/scan", middleware.AuthMiddleware, scanController.RescanSubmission)            //admin only
+
+	// peer review
+	r.POST("/:course_id/projects/:project_id/peer-reviews/assign", middleware.AuthMiddleware, peerReviewController.AssignReviews) //admin & mentor
+	r.GET("/:course_id/projects/:project_id/peer-reviews", middleware.AuthMiddleware, peerReviewController.GetReviews)
+	r.PUT("/:course_id/projects/:project_id/peer-reviews/:review_id", middleware.AuthMiddleware, peerReviewController.SubmitReview)       //student only
+	r.PUT("/:course_id/projects/:project_id/peer-reviews/:review_id/quality", middleware.AuthMiddleware, peerReviewController.RateReview) //admin & mentor
+	r.GET("/:course_id/projects/:project_id/peer-reviews/:review_id/file", middleware.AuthMiddleware, peerReviewController.DownloadReviewFile)
+
+	// similarity
+	r.POST("/:course_id/projects/:project_id/similarity", middleware.AuthMiddleware, similarityController.AnalyzeProject)          //admin & mentor
+	r.GET("/:course_id/projects/:project_id/similarity", middleware.AuthMiddleware, similarityController.GetReports)               //admin & mentor
+	r.GET("/:course_id/projects/:project_id/similarity/:report_id", middleware.AuthMiddleware, similarityController.GetReportByID) //admin & mentor
+
+	// team
+	r.POST("/:course_id/projects/:project_id/teams", middleware.AuthMiddleware, teamController.CreateTeam)             //for all, student when self formed teams allowed
+	r.POST("/:course_id/projects/:project_id/teams/generate", middleware.AuthMiddleware, teamController.GenerateTeams) //admin & mentor
+	r.GET("/:course_id/projects/:project_id/teams", middleware.AuthMiddleware, teamController.GetTeams)
+	r.GET("/:course_id/projects/:project_id/teams/:team_id", middleware.AuthMiddleware, teamController.GetTeamByID)
+	r.DELETE("/:course_id/projects/:project_id/teams/:team_id", middleware.AuthMiddleware, teamController.DeleteTeam)                       //admin & mentor
+	r.POST("/:course_id/projects/:project_id/teams/:team_id/join", middleware.AuthMiddleware, teamController.JoinTeam)                      //student only
+	r.POST("/:course_id/projects/:project_id/teams/:team_id/leave", middleware.AuthMiddleware, teamController.LeaveTeam)                    //student only
+	r.PUT("/:course_id/projects/:project_id/teams/:team_id/members/:student_id", middleware.AuthMiddleware, teamController.AdjustMember)    //admin & mentor
+	r.DELETE("/:course_id/projects/:project_id/teams/:team_id/members/:student_id", middleware.AuthMiddleware, teamController.RemoveMember) //admin & mentor
+
+	// deadline extension
+	r.POST("/:course_id/projects/:project_id/extensions", middleware.AuthMiddleware, extensionController.GrantExtension)                  //admin & mentor
+	r.GET("/:course_id/projects/:project_id/extensions", middleware.AuthMiddleware, extensionController.GetExtensions)                    //for all
+	r.DELETE("/:course_id/projects/:project_id/extensions/:extension_id", middleware.AuthMiddleware, extensionController.RevokeExtension) //admin & mentor
 
 	// attendance
-	r.POST("/:course_id/classes/:class_id/attendances", middleware.AuthMiddleware, attendanceController.StudentAttendClass)                    //admin & student
+	r.POST("/:course_id/classes/:class_id/attendances", middleware.AuthMiddleware, attendanceController.StudentAttendClass)                    //admin & mentor
 	r.GET("/:course_id/classes/:class_id/attendances", middleware.AuthMiddleware, attendanceController.GetClassAttendances)                    //admin & mentor
+	r.PUT("/:course_id/classes/:class_id/attendances", middleware.AuthMiddleware, attendanceController.BulkUpdateAttendances)                  //admin & mentor
 	r.DELETE("/:course_id/classes/:class_id/attendances/:attendance_id", middleware.AuthMiddleware, attendanceController.DeleteAttendanceByID) //admin
+	r.GET("/:course_id/classes/:class_id/checkin-code", middleware.AuthMiddleware, attendanceController.GetCheckinCode)                        //admin & mentor
+	r.GET("/:course_id/classes/:class_id/checkin-code/qr", middleware.AuthMiddleware, attendanceController.GetCheckinQR)                       //admin & mentor
+	r.POST("/:course_id/classes/:class_id/checkin", middleware.AuthMiddleware, attendanceController.StudentCheckin)                            //student only
+
+	// excuse
+	r.POST("/:course_id/classes/:class_id/excuses", middleware.AuthMiddleware, middleware.UploadLimit, excuseController.SubmitExcuse) //student only
+	r.GET("/:course_id/classes/:class_id/excuses", middleware.AuthMiddleware, excuseController.GetClassExcuses)
+	r.PUT("/:course_id/classes/:class_id/excuses/:excuse_id", middleware.AuthMiddleware, excuseController.ReviewExcuse) //admin & mentor
+	r.GET("/:course_id/classes/:class_id/excuses/:excuse_id/document", middleware.AuthMiddleware, excuseController.DownloadExcuseDocument)
+
+	// report, add ?format=csv or ?format=xlsx to export
+	r.GET("/:course_id/reports/attendances/students", middleware.AuthMiddleware, reportController.GetStudentAttendReports) //admin & mentor
+	r.GET("/:course_id/reports/attendances/classes", middleware.AuthMiddleware, reportController.GetClassTurnoutReports)   //admin & mentor
+	r.GET("/:course_id/reports/attendances/trends", middleware.AuthMiddleware, reportController.GetAttendTrendReports)     //admin & mentor
+	r.GET("/:course_id/reports/attendances/at-risk", middleware.AuthMiddleware, reportController.GetAtRiskStudents)        //admin & mentor
+
+	// enrollment, add ?format=csv to export listing
+	r.GET("/me/enrollments", middleware.AuthMiddleware, enrollController.GetMyEnrolls)
+	r.GET("/enrollments", middleware.AuthMiddleware, enrollController.SearchEnrolls)                                            //admin only
+	r.POST("/:course_id/enrollments", middleware.AuthMiddleware, enrollController.StudentEnroll)                                //student & mentor
+	r.PUT("/:course_id/enrollments/:enroll_id", middleware.AuthMiddleware, enrollController.UpdateStudentEnroll)                //admin, mentor & student by transition
+	r.POST("/:course_id/enrollments/import", middleware.AuthMiddleware, middleware.UploadLimit, enrollController.ImportEnrolls) //admin only, add dry_run=true to validate only
+	r.GET("/:course_id/enrollments", middleware.AuthMiddleware, enrollController.GetCourseEnrolls)                              //admin & mentor
+	r.POST("/:course_id/enrollments/:enroll_id/drop", middleware.AuthMiddleware, enrollController.DropEnroll)                   //student only
+	r.PUT("/:course_id/enrollments/:enroll_id/drop", middleware.AuthMiddleware, enrollController.ReviewDropEnroll)              //admin only
+	r.GET("/:course_id/enrollments/:enroll_id/history", middleware.AuthMiddleware, enrollController.GetEnrollHistories)         //admin, mentor & student
+
+	// completion
+	r.GET("/:course_id/completion-rules", middleware.AuthMiddleware, completionController.GetCompletionRule)
+	r.PUT("/:course_id/completion-rules", middleware.AuthMiddleware, completionController.SetCompletionRule)      //admin & mentor
+	r.GET("/:course_id/completion/preview", middleware.AuthMiddleware, completionController.PreviewCompletion)    //admin & mentor
+	r.POST("/:course_id/completion/evaluate", middleware.AuthMiddleware, completionController.EvaluateCompletion) //admin & mentor
+
+	// gradebook, add ?format=csv or ?format=xlsx to export the grid
+	r.POST("/:course_id/grade-categories", middleware.AuthMiddleware, gradebookController.CreateCategory) //admin & mentor
+	r.GET("/:course_id/grade-categories", middleware.AuthMiddleware, gradebookController.GetCategories)
+	r.PUT("/:course_id/grade-categories/:category_id", middleware.AuthMiddleware, gradebookController.UpdateCategory)    //admin & mentor
+	r.DELETE("/:course_id/grade-categories/:category_id", middleware.AuthMiddleware, gradebookController.DeleteCategory) //admin & mentor
+	r.GET("/:course_id/grade-scale", middleware.AuthMiddleware, gradebookController.GetGradeScale)
+	r.PUT("/:course_id/grade-scale", middleware.AuthMiddleware, gradebookController.SaveGradeScale)                                   //admin & mentor
+	r.POST("/:course_id/projects/:project_id/excusals", middleware.AuthMiddleware, gradebookController.ExcuseProject)                 //admin & mentor
+	r.DELETE("/:course_id/projects/:project_id/excusals/:student_id", middleware.AuthMiddleware, gradebookController.UnexcuseProject) //admin & mentor
+	r.GET("/:course_id/gradebook", middleware.AuthMiddleware, gradebookController.GetGradebook)                                       //admin & mentor
+	r.GET("/:course_id/gradebook/me", middleware.AuthMiddleware, gradebookController.GetMyGrades)                                     //student only
+
+	// certificate
+	r.GET("/certificates/:serial/verify", certificateController.VerifyCertificate)
+	r.PUT("/certificates/:serial/revoke", middleware.AuthMiddleware, certificateController.RevokeCertificate)                   //admin only
+	r.GET("/:course_id/enrollments/:enroll_id/certificate", middleware.AuthMiddleware, certificateController.GetCertificatePDF) //admin, mentor & student
+
+	// signed download links of stored files
+	r.GET("/files", fileController.DownloadFile)
 
-	// enrollment
-	r.POST("/:course_id/enrollments", middleware.AuthMiddleware, enrollController.StudentEnroll)                 //student & mentor
-	r.PUT("/:course_id/enrollments/:enroll_id", middleware.AuthMiddleware, enrollController.UpdateStudentEnroll) //admin
+	// invite code, student joins with the code link or invite_code on POST /:course_id/enrollments
+	r.POST("/invites/:code/join", middleware.AuthMiddleware, enrollController.JoinByInvite)              //student only
+	r.POST("/:course_id/invites", middleware.AuthMiddleware, inviteController.CreateInvite)              //admin & mentor
+	r.GET("/:course_id/invites", middleware.AuthMiddleware, inviteController.GetCourseInvites)           //admin & mentor
+	r.GET("/:course_id/invites/:invite_id", middleware.AuthMiddleware, inviteController.GetInviteByID)   //admin & mentor
+	r.DELETE("/:course_id/invites/:invite_id", middleware.AuthMiddleware, inviteController.RevokeInvite) //admin & mentor
 
 	r.Run()
 }
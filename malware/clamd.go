@@ -0,0 +1,117 @@
+package malware
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// file being scanned couldn't be read
+var errRead = errors.New("failed to read file")
+
+// bytes sent per INSTREAM chunk, well below the clamd StreamMaxLength
+const chunkSize = 64 * 1024
+
+// clamd compatible daemon over tcp or a unix socket, files are sent with the INSTREAM command
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	network, address, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ClamdScanner{network: network, address: address, timeout: timeout}, nil
+}
+
+func (s *ClamdScanner) Name() string {
+	return "clamd"
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return nil, err
+	}
+
+	if err := s.stream(conn, r); err != nil {
+		if errors.Is(err, errRead) {
+			return nil, err
+		}
+
+		// clamd stops reading once the stream is too long, its reply says why
+		result, replyErr := readReply(conn)
+		if !errors.Is(replyErr, ErrUnavailable) {
+			return result, replyErr
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+
+	return readReply(conn)
+}
+
+// zINSTREAM, then chunks prefixed by their length in network byte order, ended by a zero length chunk
+func (s *ClamdScanner) stream(conn net.Conn, r io.Reader) error {
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, 4+chunkSize)
+	for {
+		n, err := io.ReadFull(r, chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk[:4], uint32(n))
+			if _, err := conn.Write(chunk[:4+n]); err != nil {
+				return err
+			}
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", errRead, err)
+		}
+	}
+
+	_, err := conn.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// reply is "stream: OK", "stream: <signature> FOUND" or "<message> ERROR", ended by a null byte
+func readReply(conn net.Conn) (*Result, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return nil, fmt.Errorf("%w: no reply from clamd", ErrUnavailable)
+	}
+
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case reply == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{Infected: true, Signature: strings.TrimSuffix(reply, " FOUND")}, nil
+	case strings.HasSuffix(reply, " ERROR"):
+		return nil, fmt.Errorf("clamd: %s", strings.TrimSuffix(reply, " ERROR"))
+	default:
+		return nil, fmt.Errorf("clamd: unexpected reply %q", reply)
+	}
+}
@@ -0,0 +1,285 @@
+package malware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// in process clamd speaking INSTREAM, streams longer than limit are refused like StreamMaxLength does
+type fakeClamd struct {
+	listener net.Listener
+	limit    int
+	// sizes of the chunks of the last stream
+	chunks chan []int
+}
+
+func startFakeClamd(t *testing.T, network, address string, limit int) *fakeClamd {
+	t.Helper()
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	clamd := &fakeClamd{listener: listener, limit: limit, chunks: make(chan []int, 16)}
+	go clamd.serve()
+	return clamd
+}
+
+func (c *fakeClamd) address() string {
+	return c.listener.Addr().Network() + "://" + c.listener.Addr().String()
+}
+
+func (c *fakeClamd) serve() {
+	for {
+		conn, err := c.listener.Accept()
+		if err != nil {
+			return
+		}
+		go c.handle(conn)
+	}
+}
+
+func (c *fakeClamd) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	command, err := reader.ReadString(0)
+	if err != nil || command != "zINSTREAM\x00" {
+		conn.Write([]byte("UNKNOWN COMMAND\x00"))
+		return
+	}
+
+	var content bytes.Buffer
+	var chunks []int
+	for {
+		var size uint32
+		if err := binary.Read(reader, binary.BigEndian, &size); err != nil {
+			return
+		}
+		if size == 0 {
+			break
+		}
+
+		chunks = append(chunks, int(size))
+		if content.Len()+int(size) > c.limit {
+			conn.Write([]byte("INSTREAM size limit exceeded. ERROR\x00"))
+			return
+		}
+
+		if _, err := io.CopyN(&content, reader, int64(size)); err != nil {
+			return
+		}
+	}
+	c.chunks <- chunks
+
+	if bytes.Contains(content.Bytes(), []byte(EICAR)) {
+		conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		return
+	}
+	conn.Write([]byte("stream: OK\x00"))
+}
+
+func TestClamdScanner(t *testing.T) {
+	clamd := startFakeClamd(t, "tcp", "127.0.0.1:0", 256*1024)
+
+	tests := []struct {
+		name      string
+		content   io.Reader
+		infected  bool
+		signature string
+		chunks    []int
+		wantErr   error
+	}{
+		{"clean", strings.NewReader("hello"), false, "", []int{5}, nil},
+		{"empty", strings.NewReader(""), false, "", nil, nil},
+		{"infected", strings.NewReader("prefix " + EICAR), true, "Eicar-Test-Signature", []int{len(EICAR) + 7}, nil},
+		{"split into chunks", bytes.NewReader(make([]byte, 2*chunkSize+10)), false, "", []int{chunkSize, chunkSize, 10}, nil},
+		{"over the size limit", bytes.NewReader(make([]byte, 5*chunkSize)), false, "", nil, errors.New("clamd: INSTREAM size limit exceeded.")},
+		{"unreadable file", iotest.ErrReader(errors.New("disk gone")), false, "", nil, errRead},
+	}
+
+	scanner, err := NewClamdScanner(clamd.address(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := scanner.Scan(context.Background(), tt.content)
+
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatalf("got result %+v, want error %v", result, tt.wantErr)
+				}
+				if errors.Is(err, ErrUnavailable) {
+					t.Fatalf("file error %v must not be reported as unavailable", err)
+				}
+				if !errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error() {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Infected != tt.infected || result.Signature != tt.signature {
+				t.Fatalf("got %+v, want infected %v signature %q", result, tt.infected, tt.signature)
+			}
+
+			chunks := <-clamd.chunks
+			if len(chunks) != len(tt.chunks) {
+				t.Fatalf("got chunks %v, want %v", chunks, tt.chunks)
+			}
+			for i := range chunks {
+				if chunks[i] != tt.chunks[i] {
+					t.Fatalf("got chunks %v, want %v", chunks, tt.chunks)
+				}
+			}
+		})
+	}
+}
+
+func TestClamdScannerUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "clamd.sock")
+	clamd := startFakeClamd(t, "unix", socket, 1024)
+
+	scanner, err := NewClamdScanner(socket, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader(EICAR))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Infected {
+		t.Fatalf("got %+v, want infected", result)
+	}
+	<-clamd.chunks
+}
+
+func TestClamdScannerUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	scanner, err := NewClamdScanner(address, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := scanner.Scan(context.Background(), strings.NewReader("hello")); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("got %v, want ErrUnavailable", err)
+	}
+}
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name      string
+		reply     string
+		infected  bool
+		signature string
+		wantErr   string
+		// daemon went away, scan should be retried
+		unavailable bool
+	}{
+		{name: "clean", reply: "stream: OK\x00"},
+		{name: "clean without prefix", reply: "OK\x00"},
+		{name: "found", reply: "stream: Win.Test.EICAR_HDB-1 FOUND\x00", infected: true, signature: "Win.Test.EICAR_HDB-1"},
+		{name: "found with newline", reply: "stream: Eicar-Test-Signature FOUND\n\x00", infected: true, signature: "Eicar-Test-Signature"},
+		{name: "error", reply: "INSTREAM size limit exceeded. ERROR\x00", wantErr: "clamd: INSTREAM size limit exceeded."},
+		{name: "unexpected", reply: "PONG\x00", wantErr: `clamd: unexpected reply "PONG"`},
+		{name: "reply without terminator", reply: "stream: OK", wantErr: ""},
+		{name: "no reply", reply: "", unavailable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := net.Pipe()
+			go func() {
+				server.Write([]byte(tt.reply))
+				server.Close()
+			}()
+			defer client.Close()
+
+			result, err := readReply(client)
+
+			switch {
+			case tt.unavailable:
+				if !errors.Is(err, ErrUnavailable) {
+					t.Fatalf("got %v, want ErrUnavailable", err)
+				}
+			case tt.wantErr != "":
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got %v, want %s", err, tt.wantErr)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case result.Infected != tt.infected || result.Signature != tt.signature:
+				t.Fatalf("got %+v, want infected %v signature %q", result, tt.infected, tt.signature)
+			}
+		})
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		network string
+		target  string
+		wantErr bool
+	}{
+		{"tcp://clamav:3310", "tcp", "clamav:3310", false},
+		{"clamav:3310", "tcp", "clamav:3310", false},
+		{"unix:///run/clamav/clamd.sock", "unix", "/run/clamav/clamd.sock", false},
+		{"/run/clamav/clamd.sock", "unix", "/run/clamav/clamd.sock", false},
+		{"", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			network, target, err := parseAddress(tt.address)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if network != tt.network || target != tt.target {
+				t.Fatalf("got %s %s, want %s %s", network, target, tt.network, tt.target)
+			}
+		})
+	}
+}
+
+func TestFakeScanner(t *testing.T) {
+	scanner := NewFakeScanner()
+
+	result, err := scanner.Scan(context.Background(), strings.NewReader("clean file"))
+	if err != nil || result.Infected {
+		t.Fatalf("got %+v %v, want clean", result, err)
+	}
+
+	result, err = scanner.Scan(context.Background(), strings.NewReader(EICAR))
+	if err != nil || !result.Infected || result.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("got %+v %v, want eicar", result, err)
+	}
+
+	scanner.Err = ErrUnavailable
+	if _, err := scanner.Scan(context.Background(), strings.NewReader("")); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("got %v, want ErrUnavailable", err)
+	}
+}
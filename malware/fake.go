@@ -0,0 +1,45 @@
+package malware
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// standard antivirus test file, harmless but detected by every scanner
+const EICAR = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// in process scanner for tests, a file is infected when it contains one of the signatures
+type FakeScanner struct {
+	// content to look for mapped to the reported signature
+	Signatures map[string]string
+	// returned instead of a result to simulate a failing scanner
+	Err error
+}
+
+func NewFakeScanner() *FakeScanner {
+	return &FakeScanner{Signatures: map[string]string{EICAR: "Eicar-Test-Signature"}}
+}
+
+func (s *FakeScanner) Name() string {
+	return "fake"
+}
+
+func (s *FakeScanner) Scan(ctx context.Context, r io.Reader) (*Result, error) {
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for pattern, signature := range s.Signatures {
+		if bytes.Contains(content, []byte(pattern)) {
+			return &Result{Infected: true, Signature: signature}, nil
+		}
+	}
+
+	return &Result{}, nil
+}
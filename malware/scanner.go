@@ -0,0 +1,72 @@
+package malware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scanner couldn't be reached, the file should be scanned again later
+var ErrUnavailable = errors.New("scanner is unavailable")
+
+type Result struct {
+	Infected bool
+	// name of the detected malware
+	Signature string
+}
+
+// checks a file streamed from r, a file that couldn't be scanned is an error
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, r io.Reader) (*Result, error)
+}
+
+// choose scanner from SCANNER env, clamd talks to CLAMD_ADDRESS (tcp://host:3310 or unix:///path/clamd.sock),
+// fake is for tests & local development, nil means uploads aren't scanned
+func NewScanner() (Scanner, error) {
+	scanner := os.Getenv("SCANNER")
+	if scanner == "" && os.Getenv("CLAMD_ADDRESS") != "" {
+		scanner = "clamd"
+	}
+
+	switch scanner {
+	case "":
+		return nil, nil
+	case "fake":
+		return NewFakeScanner(), nil
+	case "clamd":
+		timeout := 60 * time.Second
+		if seconds, err := strconv.Atoi(os.Getenv("CLAMD_TIMEOUT")); err == nil && seconds > 0 {
+			timeout = time.Duration(seconds) * time.Second
+		}
+
+		clamd, err := NewClamdScanner(os.Getenv("CLAMD_ADDRESS"), timeout)
+		if err != nil {
+			return nil, err
+		}
+		return clamd, nil
+	default:
+		return nil, fmt.Errorf("unknown scanner %q", scanner)
+	}
+}
+
+// network & address to dial from a clamd address, a bare path is a unix socket
+func parseAddress(address string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(address, "tcp://"):
+		return "tcp", strings.TrimPrefix(address, "tcp://"), nil
+	case strings.HasPrefix(address, "unix://"):
+		return "unix", strings.TrimPrefix(address, "unix://"), nil
+	case strings.HasPrefix(address, "/"):
+		return "unix", address, nil
+	case address != "":
+		return "tcp", address, nil
+	default:
+		return "", "", fmt.Errorf("CLAMD_ADDRESS is required for the clamd scanner")
+	}
+}
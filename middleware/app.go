@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"os"
+	"strings"
+)
+
+// public base url of the api used in shared links, APP_URL defaults to local server
+func AppURL() string {
+	baseURL := os.Getenv("APP_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+
+	return strings.TrimRight(baseURL, "/")
+}
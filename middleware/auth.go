@@ -15,15 +15,17 @@ import (
 var sercretKey = []byte(os.Getenv("JWT_SECRET_KEY"))
 
 type UserClaims struct {
-	UserID uint        `json:"user_id"`
-	Role   entity.Role `json:"role"`
+	UserID   uint        `json:"user_id"`
+	Role     entity.Role `json:"role"`
+	Timezone string      `json:"timezone,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(username string, role entity.Role, userID uint) (string, error) {
+func GenerateJWT(username string, role entity.Role, userID uint, timezone string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, UserClaims{
-		UserID: userID,
-		Role:   role,
+		UserID:   userID,
+		Role:     role,
+		Timezone: timezone,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(1 * time.Hour)),
 		},
@@ -62,8 +64,9 @@ func ParseJWT(tokenStr string) (*entity.User, error) {
 
 	// create user object based on claims
 	user := &entity.User{
-		UserID: claims.UserID,
-		Role:   claims.Role,
+		UserID:   claims.UserID,
+		Role:     claims.Role,
+		Timezone: claims.Timezone,
 	}
 	// var user entity.User
 	// if err := c.db.First(&user, claims.UserID).Error; err != nil {
@@ -111,8 +114,9 @@ func AuthMiddleware(ctx *gin.Context) {
 
 	// set user info in context
 	ctx.Set("currentUser", &UserClaims{
-		UserID: user.UserID,
-		Role:   user.Role,
+		UserID:   user.UserID,
+		Role:     user.Role,
+		Timezone: user.Timezone,
 	})
 	ctx.Next()
 }
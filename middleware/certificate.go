@@ -0,0 +1,196 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// certificate text, body & footer are text/template over CertificateData
+type CertificateTemplate struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	Body     string `json:"body"`
+	Footer   string `json:"footer"`
+	Issuer   string `json:"issuer"`
+}
+
+type CertificateData struct {
+	Serial      string
+	StudentName string
+	CourseName  string
+	MentorName  string
+	CompletedAt time.Time
+	VerifyURL   string
+}
+
+var defaultCertificateTemplate = CertificateTemplate{
+	Title:    "Certificate of Completion",
+	Subtitle: "This is to certify that",
+	Body:     "has successfully completed the course {{.CourseName}} under the mentorship of {{.MentorName}} on {{.CompletedAt.Format \"02 January 2006\"}}.",
+	Footer:   "Serial {{.Serial}} - verify at {{.VerifyURL}}",
+	Issuer:   "Go-Learn",
+}
+
+// template from json file at CERTIFICATE_TEMPLATE, missing fields fall back to default
+func LoadCertificateTemplate() (CertificateTemplate, error) {
+	tmpl := defaultCertificateTemplate
+
+	path := os.Getenv("CERTIFICATE_TEMPLATE")
+	if path == "" {
+		return tmpl, nil
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return tmpl, fmt.Errorf("failed to read certificate template")
+	}
+
+	var custom CertificateTemplate
+	if err := json.Unmarshal(file, &custom); err != nil {
+		return tmpl, fmt.Errorf("invalid certificate template")
+	}
+
+	if custom.Title != "" {
+		tmpl.Title = custom.Title
+	}
+	if custom.Subtitle != "" {
+		tmpl.Subtitle = custom.Subtitle
+	}
+	if custom.Body != "" {
+		tmpl.Body = custom.Body
+	}
+	if custom.Footer != "" {
+		tmpl.Footer = custom.Footer
+	}
+	if custom.Issuer != "" {
+		tmpl.Issuer = custom.Issuer
+	}
+
+	return tmpl, nil
+}
+
+const serialChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// serial like GL-ABCD-EFGH-JKLM
+func GenerateCertificateSerial() (string, error) {
+	groups := make([]string, 3)
+	for i := range groups {
+		group := make([]byte, 4)
+		for j := range group {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(serialChars))))
+			if err != nil {
+				return "", fmt.Errorf("failed to generate certificate serial")
+			}
+			group[j] = serialChars[n.Int64()]
+		}
+		groups[i] = string(group)
+	}
+
+	return "GL-" + strings.Join(groups, "-"), nil
+}
+
+func CertificateVerifyURL(serial string) string {
+	return fmt.Sprintf("%s/certificates/%s/verify", AppURL(), serial)
+}
+
+func executeText(text string, data CertificateData) (string, error) {
+	tmpl, err := template.New("certificate").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid certificate template: %v", err)
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return "", fmt.Errorf("invalid certificate template: %v", err)
+	}
+
+	return buffer.String(), nil
+}
+
+// render landscape A4 certificate with verification QR code
+func RenderCertificatePDF(tmpl CertificateTemplate, data CertificateData) ([]byte, error) {
+	body, err := executeText(tmpl.Body, data)
+	if err != nil {
+		return nil, err
+	}
+
+	footer, err := executeText(tmpl.Footer, data)
+	if err != nil {
+		return nil, err
+	}
+
+	qrCode, err := GenerateQRCode(data.VerifyURL, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle(tmpl.Title, true)
+	pdf.SetAuthor(tmpl.Issuer, true)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	// core fonts are cp1252
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	width, height := pdf.GetPageSize()
+
+	// border
+	pdf.SetDrawColor(40, 70, 120)
+	pdf.SetLineWidth(1.5)
+	pdf.Rect(10, 10, width-20, height-20, "D")
+	pdf.SetLineWidth(0.4)
+	pdf.Rect(14, 14, width-28, height-28, "D")
+
+	pdf.SetTextColor(40, 70, 120)
+	pdf.SetFont("Helvetica", "B", 34)
+	pdf.SetXY(20, 38)
+	pdf.CellFormat(width-40, 16, tr(tmpl.Title), "", 1, "C", false, 0, "")
+
+	pdf.SetTextColor(60, 60, 60)
+	pdf.SetFont("Helvetica", "", 16)
+	pdf.SetXY(20, 66)
+	pdf.CellFormat(width-40, 10, tr(tmpl.Subtitle), "", 1, "C", false, 0, "")
+
+	pdf.SetTextColor(20, 20, 20)
+	pdf.SetFont("Helvetica", "B", 28)
+	pdf.SetXY(20, 82)
+	pdf.CellFormat(width-40, 14, tr(data.StudentName), "", 1, "C", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 15)
+	pdf.SetXY(40, 104)
+	pdf.MultiCell(width-80, 8, tr(body), "", "C", false)
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.SetXY(30, height-52)
+	pdf.CellFormat(100, 8, tr(tmpl.Issuer), "T", 0, "C", false, 0, "")
+
+	// verification qr code
+	pdf.RegisterImageOptionsReader("qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qrCode))
+	pdf.ImageOptions("qr", width-70, height-75, 40, 40, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+	pdf.SetTextColor(100, 100, 100)
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetXY(20, height-28)
+	pdf.CellFormat(width-40, 6, tr(footer), "", 0, "C", false, 0, "")
+
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		return nil, fmt.Errorf("failed to render certificate")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// authenticated download link sent to student on completion
+func CertificateDownloadURL(courseID, enrollID uint) string {
+	return fmt.Sprintf("%s/%d/enrollments/%d/certificate", AppURL(), courseID, enrollID)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// check-in code rotates every minute
+const CheckinCodeTTL = 1 * time.Minute
+
+// student can still check in shortly after class end
+const CheckinGracePeriod = 15 * time.Minute
+
+// codes a student can try per class, afterwards mentor has to record the attendance
+const MaxCheckinAttempts = 5
+
+// generate random 6 digit check-in code
+func GenerateCheckinCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate check-in code")
+	}
+
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// encode content into QR code PNG
+func GenerateQRCode(content string, size int) ([]byte, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate qr code")
+	}
+
+	return png, nil
+}
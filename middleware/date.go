@@ -1,21 +1,108 @@
 package middleware
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 	"time"
+
+	"github.com/gin-gonic/gin"
 )
 
+// legacy input layout without zone, dd-mm-yyyy hour:minute
+const DateLayout = "02-01-2006 15:04"
+
 type CustomTime struct {
 	time.Time
 }
 
+// accept RFC 3339 or legacy layout, always stored as UTC
 func (c *CustomTime) UnmarshalJSON(b []byte) error {
-	layout := "02-01-2006 15:04" //dd-mm-yyyy hour:minute
-	parseTime, err := time.Parse(fmt.Sprintf("\"%s\"", layout), string(b))
+	str := strings.Trim(string(b), "\"")
+	if str == "null" {
+		c.Time = time.Time{}
+		return nil
+	}
+
+	if parseTime, err := time.Parse(time.RFC3339, str); err == nil {
+		c.Time = parseTime.UTC()
+		return nil
+	}
+
+	// legacy layout has no zone, read it in default app timezone
+	parseTime, err := time.ParseInLocation(DateLayout, str, DefaultLocation())
 	if err != nil {
-		return fmt.Errorf("date time format input invalid. expected format: %s", layout)
+		return fmt.Errorf("date time format input invalid. expected format: %s or %s", DateLayout, time.RFC3339)
 	}
 
-	c.Time = parseTime
+	c.Time = parseTime.UTC()
 	return nil
 }
+
+// render as RFC 3339 in the time own location so it can be sent back as input, unset time is null
+func (c CustomTime) MarshalJSON() ([]byte, error) {
+	if c.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(c.Time.Format(time.RFC3339))
+}
+
+// app timezone from APP_TIMEZONE env, default to Asia/Jakarta
+func DefaultLocation() *time.Location {
+	if loc, err := LoadTimezone(os.Getenv("APP_TIMEZONE")); err == nil {
+		return loc
+	}
+
+	if loc, err := time.LoadLocation("Asia/Jakarta"); err == nil {
+		return loc
+	}
+
+	return time.UTC
+}
+
+// validate IANA timezone name, e.g. Asia/Jakarta
+func LoadTimezone(timezone string) (*time.Location, error) {
+	if strings.TrimSpace(timezone) == "" {
+		return nil, fmt.Errorf("timezone cannot be empty")
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %s", timezone)
+	}
+
+	return loc, nil
+}
+
+// user timezone from X-Timezone header, then user profile, then app default
+func UserLocation(ctx *gin.Context) *time.Location {
+	if loc, err := LoadTimezone(ctx.GetHeader("X-Timezone")); err == nil {
+		return loc
+	}
+
+	claims, _ := ctx.Get("currentUser")
+	if userClaims, ok := claims.(*UserClaims); ok {
+		if loc, err := LoadTimezone(userClaims.Timezone); err == nil {
+			return loc
+		}
+	}
+
+	return DefaultLocation()
+}
+
+// convert stored UTC time to user timezone for response
+func LocalTime(ctx *gin.Context, t time.Time) CustomTime {
+	return CustomTime{t.In(UserLocation(ctx))}
+}
+
+// same as LocalTime for optional time, nil stays nil
+func LocalTimePtr(ctx *gin.Context, t *time.Time) *CustomTime {
+	if t == nil {
+		return nil
+	}
+
+	local := LocalTime(ctx, *t)
+	return &local
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCustomTimeJSON(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+
+	tests := []struct {
+		name string
+		time CustomTime
+		want string
+	}{
+		{"zero time is null", CustomTime{}, "null"},
+		{"keeps location offset", CustomTime{time.Date(2024, 5, 1, 9, 30, 0, 0, jakarta)}, `"2024-05-01T09:30:00+07:00"`},
+		{"utc", CustomTime{time.Date(2024, 5, 1, 2, 30, 0, 0, time.UTC)}, `"2024-05-01T02:30:00Z"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.time)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(got) != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+
+			var back CustomTime
+			if err := json.Unmarshal(got, &back); err != nil {
+				t.Fatal(err)
+			}
+
+			if !back.Equal(tt.time.Time) {
+				t.Fatalf("round trip got %v, want %v", back, tt.time)
+			}
+		})
+	}
+}
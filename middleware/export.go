@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+// leading characters that make a spreadsheet read a cell as formula
+const formulaChars = "=+-@\t\r"
+
+// column of an export, the column decides the cell type, never the value
+type ExportColumn struct {
+	Name    string
+	Numeric bool
+}
+
+func TextColumn(name string) ExportColumn {
+	return ExportColumn{Name: name}
+}
+
+func NumberColumn(name string) ExportColumn {
+	return ExportColumn{Name: name, Numeric: true}
+}
+
+// number of a numeric column, anything else stays text & is escaped so it can't run as formula
+func exportCell(column ExportColumn, value string) (float64, string, bool) {
+	if column.Numeric {
+		if number, err := strconv.ParseFloat(value, 64); err == nil {
+			return number, value, true
+		}
+	}
+
+	if value != "" && strings.ContainsRune(formulaChars, rune(value[0])) {
+		value = "'" + value
+	}
+
+	return 0, value, false
+}
+
+// write report rows as csv or xlsx attachment
+func WriteExport(ctx *gin.Context, format, fileName string, columns []ExportColumn, rows [][]string) error {
+	// header row is text whatever its column holds
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	rows = append([][]string{header}, rows...)
+
+	column := func(row, col int) ExportColumn {
+		if row == 0 || col >= len(columns) {
+			return ExportColumn{}
+		}
+		return columns[col]
+	}
+
+	var buffer bytes.Buffer
+	var contentType string
+
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(&buffer)
+
+		for i, row := range rows {
+			record := make([]string, len(row))
+			for j, value := range row {
+				_, record[j], _ = exportCell(column(i, j), value)
+			}
+
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("failed to write csv")
+			}
+		}
+
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write csv")
+		}
+
+		contentType = "text/csv"
+	case "xlsx":
+		file := excelize.NewFile()
+		defer file.Close()
+
+		sheet := file.GetSheetName(0)
+		for i, row := range rows {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+
+			// numeric columns stay numbers so they can be calculated in spreadsheet
+			values := make([]interface{}, len(row))
+			for j, value := range row {
+				if number, text, ok := exportCell(column(i, j), value); ok {
+					values[j] = number
+				} else {
+					values[j] = text
+				}
+			}
+
+			if err := file.SetSheetRow(sheet, cell, &values); err != nil {
+				return fmt.Errorf("failed to write xlsx")
+			}
+		}
+
+		if err := file.Write(&buffer); err != nil {
+			return fmt.Errorf("failed to write xlsx")
+		}
+
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return fmt.Errorf("export format must be csv or xlsx")
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", fileName, format))
+	ctx.Data(http.StatusOK, contentType, buffer.Bytes())
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExportCell(t *testing.T) {
+	tests := []struct {
+		name    string
+		column  ExportColumn
+		value   string
+		number  float64
+		text    string
+		numeric bool
+	}{
+		{"number column", NumberColumn("score"), "87.5", 87.5, "87.5", true},
+		{"negative number", NumberColumn("score"), "-3", -3, "-3", true},
+		{"number column with text", NumberColumn("score"), "pending", 0, "pending", false},
+		{"numeric looking username", TextColumn("username"), "007", 0, "007", false},
+		{"formula", TextColumn("username"), "=HYPERLINK(\"x\")", 0, "'=HYPERLINK(\"x\")", false},
+		{"plus", TextColumn("email"), "+62812", 0, "'+62812", false},
+		{"minus", TextColumn("email"), "-1+1", 0, "'-1+1", false},
+		{"at", TextColumn("email"), "@SUM(A1)", 0, "'@SUM(A1)", false},
+		{"tab", TextColumn("email"), "\t=1", 0, "'\t=1", false},
+		{"formula in number column", NumberColumn("score"), "=1+1", 0, "'=1+1", false},
+		{"empty", TextColumn("email"), "", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, text, numeric := exportCell(tt.column, tt.value)
+			if number != tt.number || text != tt.text || numeric != tt.numeric {
+				t.Fatalf("got (%v, %q, %v), want (%v, %q, %v)", number, text, numeric, tt.number, tt.text, tt.numeric)
+			}
+		})
+	}
+}
+
+func TestWriteExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	columns := []ExportColumn{NumberColumn("student_id"), TextColumn("username"), TextColumn("=header")}
+	rows := [][]string{{"1", "007", "=cmd"}}
+
+	t.Run("csv", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+
+		if err := WriteExport(ctx, "csv", "report", columns, rows); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "student_id,username,'=header\n1,007,'=cmd\n"
+		if got := recorder.Body.String(); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(recorder)
+
+		if err := WriteExport(ctx, "xlsx", "report", columns, rows); err != nil {
+			t.Fatal(err)
+		}
+
+		file, err := excelize.OpenReader(bytes.NewReader(recorder.Body.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		sheet := file.GetSheetName(0)
+		// numbers are written without a type attribute
+		for cell, want := range map[string]excelize.CellType{"A2": excelize.CellTypeUnset, "B2": excelize.CellTypeSharedString, "C2": excelize.CellTypeSharedString} {
+			got, err := file.GetCellType(sheet, cell)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("%s type got %v, want %v", cell, got, want)
+			}
+		}
+
+		for cell, want := range map[string]string{"B2": "007", "C1": "'=header", "C2": "'=cmd"} {
+			got, err := file.GetCellValue(sheet, cell)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Fatalf("%s got %q, want %q", cell, got, want)
+			}
+		}
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+		if err := WriteExport(ctx, "pdf", "report", columns, rows); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
@@ -1,58 +1,261 @@
 package middleware
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"mime/multipart"
+	"hash"
+	"io"
+	"net/http"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/gin-gonic/gin"
 )
 
-// max file size 10mb
+// default max file size 10mb
 const MaxFileSize = 10 * 1024 * 1024
 
-var allowedFileTypes = map[string]string{
-	"image/jpeg":      ".jpg",
-	"image/png":       ".png",
-	"application/pdf": ".pdf",
+// no request body may be larger, whatever a project allows
+const MaxUploadSize = 100 * 1024 * 1024
+
+// bytes read to sniff the file type, same as the mimetype default
+const sniffSize = 3072
+
+var ErrFileTooLarge = errors.New("file is too large")
+
+type fileType struct {
+	// sniffed content must be this type or a subtype of it
+	sniffed string
+	// content type the file is stored & served with
+	contentType string
+}
+
+var textFile = fileType{sniffed: "text/plain", contentType: "text/plain; charset=utf-8"}
+
+// extensions that can be allowed on upload
+var fileTypes = map[string]fileType{
+	".pdf":  {sniffed: "application/pdf", contentType: "application/pdf"},
+	".jpg":  {sniffed: "image/jpeg", contentType: "image/jpeg"},
+	".jpeg": {sniffed: "image/jpeg", contentType: "image/jpeg"},
+	".png":  {sniffed: "image/png", contentType: "image/png"},
+	".zip":  {sniffed: "application/zip", contentType: "application/zip"},
+	// docx is a zip container, the document part isn't always within the sniffed bytes
+	".docx": {sniffed: "application/zip", contentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+
+	// source files & plain text
+	".go":    textFile,
+	".py":    textFile,
+	".java":  textFile,
+	".c":     textFile,
+	".h":     textFile,
+	".cpp":   textFile,
+	".cs":    textFile,
+	".js":    textFile,
+	".ts":    textFile,
+	".rs":    textFile,
+	".rb":    textFile,
+	".php":   textFile,
+	".kt":    textFile,
+	".swift": textFile,
+	".sql":   textFile,
+	".html":  textFile,
+	".css":   textFile,
+	".json":  textFile,
+	".yaml":  textFile,
+	".yml":   textFile,
+	".md":    textFile,
+	".txt":   textFile,
 }
 
-func CheckFileSize(file multipart.File) error {
-	// read file into a buffer & determine its size
-	buffer := make([]byte, MaxFileSize)
-	_, err := file.Read(buffer)
-	if err != nil && err.Error() != "EOF" {
-		return fmt.Errorf("failed to read file")
+// extensions accepted when nothing else is configured
+var DefaultFileTypes = []string{".pdf", ".jpg", ".jpeg", ".png", ".zip"}
+
+// allowed extensions & max size of an upload
+type FilePolicy struct {
+	FileTypes []string
+	MaxSize   int64
+}
+
+var DefaultFilePolicy = FilePolicy{FileTypes: DefaultFileTypes, MaxSize: MaxFileSize}
+
+func (p FilePolicy) allows(ext string) bool {
+	for _, fileType := range p.FileTypes {
+		if fileType == ext {
+			return true
+		}
 	}
+	return false
+}
+
+// lowercase known extensions with a leading dot, sorted without duplicates
+func NormalizeFileTypes(fileTypes []string) ([]string, error) {
+	seen := map[string]bool{}
+	var normalized []string
+
+	for _, fileType := range fileTypes {
+		ext := strings.ToLower(strings.TrimSpace(fileType))
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+
+		if _, ok := SupportedFileType(ext); !ok {
+			return nil, fmt.Errorf("file type %q isn't supported", fileType)
+		}
 
-	// check file size limit
-	if len(buffer) > MaxFileSize {
-		return fmt.Errorf("file size must be less than 10 mb")
+		if !seen[ext] {
+			seen[ext] = true
+			normalized = append(normalized, ext)
+		}
 	}
 
-	return nil
+	sort.Strings(normalized)
+	return normalized, nil
 }
 
-func CheckMimeType(ctx *gin.Context, file multipart.File) error {
-	buffer := make([]byte, 512)
-	_, err := file.Read(buffer)
-	if err != nil {
-		return fmt.Errorf("failed to read file")
+func SupportedFileType(ext string) (string, bool) {
+	fileType, ok := fileTypes[ext]
+	return fileType.contentType, ok
+}
+
+// limit the request body before the multipart form is parsed, oversized bodies fail while being read
+func UploadLimit(ctx *gin.Context) {
+	// multipart headers & other form fields come on top of the file
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, MaxUploadSize+1024*1024)
+	ctx.Next()
+}
+
+// request body went over UploadLimit
+func IsUploadTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// upload checked against a policy, the file is sniffed once & hashed while it's read
+type UploadReader struct {
+	Name        string
+	Ext         string
+	ContentType string
+
+	reader  io.Reader
+	hash    hash.Hash
+	size    int64
+	maxSize int64
+}
+
+func NewUploadReader(file io.Reader, fileName string, policy FilePolicy) (*UploadReader, error) {
+	name := SanitizeFileName(fileName)
+	ext := strings.ToLower(filepath.Ext(name))
+
+	fileType, ok := fileTypes[ext]
+	if !ok || !policy.allows(ext) {
+		return nil, fmt.Errorf("file type %s isn't allowed, allowed types: %s", ext, strings.Join(policy.FileTypes, ", "))
+	}
+
+	buffered := bufio.NewReaderSize(file, sniffSize)
+	head, err := buffered.Peek(sniffSize)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, fmt.Errorf("failed to read file")
+	}
+
+	if len(head) == 0 {
+		return nil, fmt.Errorf("file is empty")
 	}
 
-	// detect MIME type
-	mimeType := mimetype.Detect(buffer)
+	if !isType(mimetype.Detect(head), fileType.sniffed) {
+		return nil, fmt.Errorf("file content doesn't match its %s extension", ext)
+	}
+
+	upload := &UploadReader{
+		Name:        name,
+		Ext:         ext,
+		ContentType: fileType.contentType,
+		hash:        sha256.New(),
+		maxSize:     policy.MaxSize,
+	}
+	upload.reader = io.TeeReader(buffered, upload.hash)
+
+	return upload, nil
+}
+
+// sniffed type or one of its parents matches
+func isType(detected *mimetype.MIME, expected string) bool {
+	for mimeType := detected; mimeType != nil; mimeType = mimeType.Parent() {
+		if mimeType.Is(expected) {
+			return true
+		}
+	}
+	return false
+}
+
+func (u *UploadReader) Read(p []byte) (int, error) {
+	n, err := u.reader.Read(p)
+	u.size += int64(n)
+
+	if u.maxSize > 0 && u.size > u.maxSize {
+		return n, fmt.Errorf("%w, max size is %s", ErrFileTooLarge, FormatSize(u.maxSize))
+	}
+
+	return n, err
+}
+
+// bytes read so far, the file size once it's fully read
+func (u *UploadReader) Size() int64 {
+	return u.size
+}
+
+// hex sha256 of the bytes read so far
+func (u *UploadReader) Checksum() string {
+	return hex.EncodeToString(u.hash.Sum(nil))
+}
+
+func FormatSize(size int64) string {
+	if size%(1024*1024) == 0 {
+		return fmt.Sprintf("%d mb", size/(1024*1024))
+	}
+	return fmt.Sprintf("%.1f mb", float64(size)/(1024*1024))
+}
+
+// base name only, letters, digits, dot, dash & underscore are kept, everything else becomes an underscore
+func SanitizeFileName(fileName string) string {
+	// clients on windows may send the full path
+	fileName = fileName[strings.LastIndexAny(fileName, `/\`)+1:]
+
+	var b strings.Builder
+	for _, r := range fileName {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	name := strings.TrimLeft(b.String(), "._")
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	if len(ext) > 16 {
+		ext = ""
+	}
+
+	// keep names short enough for any file system or header
+	runes := []rune(base)
+	if len(runes) > 100 {
+		base = string(runes[:100])
+	}
 
-	// check if MIME type allowed
-	if ext, ok := allowedFileTypes[mimeType.String()]; ok {
-		ctx.Header("File-Extension", ext)
-		return nil
+	if base == "" {
+		base = "file"
 	}
 
-	// if MIME type not allowed
-	return fmt.Errorf("invalid file type: %s", mimeType)
+	return base + ext
 }
 
 func GenerateFileName(projectName, originalFileName string) string {
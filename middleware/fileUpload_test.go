@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var (
+	pdfContent  = []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n%%EOF\n")
+	pngContent  = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+	zipContent  = []byte("PK\x03\x04\x14\x00\x00\x00\x08\x00")
+	textContent = []byte("package main\n\nfunc main() {}\n")
+)
+
+func TestNewUploadReader(t *testing.T) {
+	policy := FilePolicy{FileTypes: []string{".go", ".html", ".pdf", ".png", ".zip"}, MaxSize: 1024 * 1024}
+
+	tests := []struct {
+		name        string
+		fileName    string
+		content     []byte
+		policy      FilePolicy
+		contentType string
+		wantErr     string
+	}{
+		{name: "pdf", fileName: "report.pdf", content: pdfContent, policy: policy, contentType: "application/pdf"},
+		{name: "uppercase extension", fileName: "REPORT.PDF", content: pdfContent, policy: policy, contentType: "application/pdf"},
+		{name: "png", fileName: "diagram.png", content: pngContent, policy: policy, contentType: "image/png"},
+		{name: "zip", fileName: "project.zip", content: zipContent, policy: policy, contentType: "application/zip"},
+		{name: "source file", fileName: "main.go", content: textContent, policy: policy, contentType: "text/plain; charset=utf-8"},
+		{name: "html is served as text", fileName: "page.html", content: []byte("<script>alert(1)</script>"), policy: policy, contentType: "text/plain; charset=utf-8"},
+		{name: "png renamed to pdf", fileName: "report.pdf", content: pngContent, policy: policy, wantErr: "file content doesn't match its .pdf extension"},
+		{name: "pdf renamed to zip", fileName: "project.zip", content: pdfContent, policy: policy, wantErr: "file content doesn't match its .zip extension"},
+		{name: "binary renamed to source", fileName: "main.go", content: pngContent, policy: policy, wantErr: "file content doesn't match its .go extension"},
+		{name: "type not in policy", fileName: "photo.jpg", content: []byte("\xff\xd8\xff\xe0"), policy: policy, wantErr: "file type .jpg isn't allowed"},
+		{name: "unknown type", fileName: "evil.php.exe", content: []byte("MZ"), policy: policy, wantErr: "file type .exe isn't allowed"},
+		{name: "no extension", fileName: "report", content: pdfContent, policy: policy, wantErr: "file type  isn't allowed"},
+		{name: "default policy", fileName: "main.go", content: textContent, policy: DefaultFilePolicy, wantErr: "file type .go isn't allowed"},
+		{name: "empty", fileName: "report.pdf", content: nil, policy: policy, wantErr: "file is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upload, err := NewUploadReader(bytes.NewReader(tt.content), tt.fileName, tt.policy)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if upload.ContentType != tt.contentType {
+				t.Fatalf("got content type %q, want %q", upload.ContentType, tt.contentType)
+			}
+
+			content, err := io.ReadAll(upload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// sniffed bytes are part of what's read
+			if !bytes.Equal(content, tt.content) || upload.Size() != int64(len(tt.content)) {
+				t.Fatalf("got %d bytes, want %d", len(content), len(tt.content))
+			}
+		})
+	}
+}
+
+func TestUploadReaderSize(t *testing.T) {
+	// larger than the sniffed head so the limit is hit while streaming
+	large := append(append([]byte{}, pdfContent...), bytes.Repeat([]byte("0"), 2*sniffSize)...)
+
+	tests := []struct {
+		name    string
+		maxSize int64
+		wantErr error
+	}{
+		{"within limit", int64(len(large)) + 1, nil},
+		{"exactly the limit", int64(len(large)), nil},
+		{"one byte over", int64(len(large)) - 1, ErrFileTooLarge},
+		{"far over", int64(len(pdfContent)), ErrFileTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upload, err := NewUploadReader(bytes.NewReader(large), "report.pdf", FilePolicy{FileTypes: []string{".pdf"}, MaxSize: tt.maxSize})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = io.Copy(io.Discard, upload)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			hash := sha256.Sum256(large)
+			if upload.Checksum() != hex.EncodeToString(hash[:]) {
+				t.Fatalf("got checksum %s, want sha256 of the file", upload.Checksum())
+			}
+			if upload.Size() != int64(len(large)) {
+				t.Fatalf("got size %d, want %d", upload.Size(), len(large))
+			}
+		})
+	}
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     string
+	}{
+		{"report.pdf", "report.pdf"},
+		{`..\..\evil.php`, "evil.php"},
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\student\final report.pdf`, "final_report.pdf"},
+		{"/var/www/shell.php", "shell.php"},
+		{".htaccess", "htaccess"},
+		{"...", "file"},
+		{"uploads/", "file"},
+		{"", "file"},
+		{"evil.php\x00.pdf", "evil.php_.pdf"},
+		{"<script>alert(1)</script>.html", "script_.html"},
+		{"résumé 2024.pdf", "résumé_2024.pdf"},
+		{"name.averyveryverylongextension", "name"},
+		{strings.Repeat("a", 150) + ".pdf", strings.Repeat("a", 100) + ".pdf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			if got := SanitizeFileName(tt.fileName); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const InviteCodeLength = 8
+
+// no look-alike characters so code can be typed by hand
+const inviteCodeChars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+func GenerateInviteCode() (string, error) {
+	code := make([]byte, InviteCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(inviteCodeChars))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate invite code")
+		}
+		code[i] = inviteCodeChars[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+// codes are matched case insensitive
+func NormalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// self-join link shared to students
+func InviteLink(code string) string {
+	return fmt.Sprintf("%s/invites/%s/join", AppURL(), code)
+}
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// meeting link is revealed to enrolled students 15 minutes before class start
+const MeetingRevealWindow = 15 * time.Minute
+
+type MeetingProvider interface {
+	Name() string
+	CreateMeeting(title string, startDate, endDate time.Time) (string, error)
+}
+
+// jitsi meet rooms are created on first join, so the link only needs an unguessable name
+type JitsiProvider struct {
+	BaseURL string
+}
+
+func (p *JitsiProvider) Name() string {
+	return "jitsi"
+}
+
+func (p *JitsiProvider) CreateMeeting(title string, startDate, endDate time.Time) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate meeting room name")
+	}
+
+	roomName := fmt.Sprintf("golearn-%s-%s", slugify(title), hex.EncodeToString(suffix))
+
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(p.BaseURL, "/"), roomName), nil
+}
+
+// local provider returns predictable links without calling any external service, used for tests
+type LocalMeetingProvider struct {
+	// classes are created from concurrent requests
+	created atomic.Int64
+}
+
+func (p *LocalMeetingProvider) Name() string {
+	return "local"
+}
+
+func (p *LocalMeetingProvider) CreateMeeting(title string, startDate, endDate time.Time) (string, error) {
+	created := p.created.Add(1)
+
+	return fmt.Sprintf("http://localhost/meetings/%d-%s", created, slugify(title)), nil
+}
+
+// choose meeting provider from MEETING_PROVIDER env, default to jitsi
+func NewMeetingProvider() MeetingProvider {
+	switch os.Getenv("MEETING_PROVIDER") {
+	case "local":
+		return &LocalMeetingProvider{}
+	default:
+		baseURL := os.Getenv("JITSI_BASE_URL")
+		if baseURL == "" {
+			baseURL = "https://meet.jit.si"
+		}
+
+		return &JitsiProvider{BaseURL: baseURL}
+	}
+}
+
+func slugify(title string) string {
+	var b strings.Builder
+
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			b.WriteRune('-')
+		}
+	}
+
+	return strings.Trim(b.String(), "-")
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLocalMeetingProviderConcurrent(t *testing.T) {
+	provider := &LocalMeetingProvider{}
+
+	var wg sync.WaitGroup
+	links := make(chan string, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			link, err := provider.CreateMeeting("Go Basics", time.Now(), time.Now().Add(time.Hour))
+			if err != nil {
+				t.Error(err)
+			}
+			links <- link
+		}()
+	}
+	wg.Wait()
+	close(links)
+
+	seen := map[string]bool{}
+	for link := range links {
+		if seen[link] {
+			t.Fatalf("duplicate meeting link %s", link)
+		}
+		seen[link] = true
+	}
+
+	if len(seen) != 50 {
+		t.Fatalf("got %d links, want 50", len(seen))
+	}
+}
+
+func TestJitsiProvider(t *testing.T) {
+	provider := &JitsiProvider{BaseURL: "https://meet.example.com/"}
+
+	link, err := provider.CreateMeeting("Intro to Go!", time.Now(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(link, "https://meet.example.com/golearn-intro-to-go-") {
+		t.Fatalf("unexpected link %s", link)
+	}
+}
@@ -1,6 +1,13 @@
 package middleware
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+
 	"github.com/go-playground/validator/v10"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -50,3 +57,40 @@ func ValidateUserInput(input interface{}, isSignup bool) map[string]string {
 
 	return errorMessage
 }
+
+const passwordChars = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// generate random alphanumeric password for invited user
+func GeneratePassword(length int) (string, error) {
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordChars))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate password")
+		}
+		password[i] = passwordChars[n.Int64()]
+	}
+
+	return string(password), nil
+}
+
+// random token of a set password link & the hash stored in place of the token
+func GeneratePasswordToken() (string, string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", "", fmt.Errorf("failed to generate password token")
+	}
+
+	plain := hex.EncodeToString(token)
+	return plain, HashPasswordToken(plain), nil
+}
+
+func HashPasswordToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// link sent to imported user to choose their password
+func SetPasswordURL(token string) string {
+	return fmt.Sprintf("%s/set-password?token=%s", AppURL(), url.QueryEscape(token))
+}
@@ -1,19 +1,44 @@
 package model
 
-import "time"
+import "github.com/nadyafa/go-learn/middleware"
 
 type AttendReq struct {
 	StudentID uint `json:"student_id" validate:"required"`
 	// ClassID   uint      `json:"class_id" validate:"required"`
 	Attended bool `json:"attended" validate:"required"`
+	// present, late, excused or absent, derived from attended when empty
+	Status string `json:"status"`
 	// AttendAt  time.Time `json:"attend_at"`
 }
 
 type AttendResp struct {
-	AttendID  uint      `json:"attend_id"`
-	StudentID uint      `json:"student_id"`
-	ClassID   uint      `json:"class_id"`
-	CourseID  uint      `json:"course_id"`
-	Attended  bool      `json:"attended"`
-	AttendAt  time.Time `json:"attend_at"`
+	AttendID  uint                  `json:"attend_id"`
+	StudentID uint                  `json:"student_id"`
+	ClassID   uint                  `json:"class_id"`
+	CourseID  uint                  `json:"course_id"`
+	Attended  bool                  `json:"attended"`
+	Status    string                `json:"status"`
+	AttendAt  middleware.CustomTime `json:"attend_at"`
+}
+
+type CheckinReq struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type CheckinCodeResp struct {
+	ClassID   uint                  `json:"class_id"`
+	CourseID  uint                  `json:"course_id"`
+	Code      string                `json:"code"`
+	ExpiresAt middleware.CustomTime `json:"expires_at"`
+}
+
+type BulkAttendItem struct {
+	StudentID uint   `json:"student_id" validate:"required"`
+	Status    string `json:"status" validate:"required"`
+}
+
+// status is applied to every enrolled student, students override it per student
+type BulkAttendReq struct {
+	Status   string           `json:"status"`
+	Students []BulkAttendItem `json:"students"`
 }
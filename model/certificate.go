@@ -0,0 +1,20 @@
+package model
+
+import "github.com/nadyafa/go-learn/middleware"
+
+type RevokeCertificateReq struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// public verification result
+type CertificateVerifyResp struct {
+	Serial       string                 `json:"serial"`
+	Valid        bool                   `json:"valid"`
+	StudentName  string                 `json:"student_name"`
+	CourseName   string                 `json:"course_name"`
+	MentorName   string                 `json:"mentor_name"`
+	CompletedAt  middleware.CustomTime  `json:"completed_at"`
+	IssuedAt     middleware.CustomTime  `json:"issued_at"`
+	RevokedAt    *middleware.CustomTime `json:"revoked_at"`
+	RevokeReason string                 `json:"revoke_reason,omitempty"`
+}
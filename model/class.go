@@ -1,8 +1,6 @@
 package model
 
 import (
-	"time"
-
 	"github.com/nadyafa/go-learn/middleware"
 )
 
@@ -10,6 +8,8 @@ type CreateClass struct {
 	ClassName   string `json:"class_name" validate:"required"`
 	Description string `json:"description"`
 	// MentorID    uint                  `json:"mentor_id" validate:"required"`
+	RoomID    uint                  `json:"room_id"`
+	Online    bool                  `json:"online"`
 	StartDate middleware.CustomTime `json:"start_date" validate:"required"`
 	EndDate   middleware.CustomTime `json:"end_date" validate:"required"`
 }
@@ -18,6 +18,8 @@ type UpdateClass struct {
 	ClassName   string `json:"class_name"`
 	Description string `json:"description"`
 	// MentorID    uint                  `json:"mentor_id" validate:"required"`
+	RoomID    uint                  `json:"room_id"`
+	Online    *bool                 `json:"online"`
 	StartDate middleware.CustomTime `json:"start_date"`
 	EndDate   middleware.CustomTime `json:"end_date"`
 }
@@ -28,8 +30,18 @@ type ClassResp struct {
 	ClassName   string `json:"class_name"`
 	Description string `json:"description"`
 	// MentorID    uint      `json:"mentor_id"`
-	StartDate time.Time `json:"start_date"`
-	EndDate   time.Time `json:"end_date"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	RoomID    *uint                 `json:"room_id"`
+	Online    bool                  `json:"online"`
+	StartDate middleware.CustomTime `json:"start_date"`
+	EndDate   middleware.CustomTime `json:"end_date"`
+	CreatedAt middleware.CustomTime `json:"created_at"`
+	UpdatedAt middleware.CustomTime `json:"updated_at"`
+}
+
+type ClassMeetingResp struct {
+	ClassID         uint                  `json:"class_id"`
+	MeetingProvider string                `json:"meeting_provider"`
+	MeetingURL      string                `json:"meeting_url"`
+	StartDate       middleware.CustomTime `json:"start_date"`
+	EndDate         middleware.CustomTime `json:"end_date"`
 }
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+)
+
+type CompletionRuleReq struct {
+	MinAttendanceRate  float64 `json:"min_attendance_rate" validate:"min=0,max=100"`
+	MinAverageScore    float64 `json:"min_average_score" validate:"min=0,max=100"`
+	RequireAllProjects bool    `json:"require_all_projects"`
+}
+
+type CompletionRuleResp struct {
+	CourseID           uint                   `json:"course_id"`
+	MinAttendanceRate  float64                `json:"min_attendance_rate"`
+	MinAverageScore    float64                `json:"min_average_score"`
+	RequireAllProjects bool                   `json:"require_all_projects"`
+	EvaluatedAt        *middleware.CustomTime `json:"evaluated_at"`
+	UpdatedAt          middleware.CustomTime  `json:"updated_at"`
+}
+
+// completion figures of an enrolled student
+type CompletionStats struct {
+	EnrollmentID      uint    `json:"enrollment_id"`
+	StudentID         uint    `json:"student_id"`
+	Username          string  `json:"username"`
+	Email             string  `json:"email"`
+	AttendanceRate    float64 `json:"attendance_rate"`
+	AverageScore      float64 `json:"average_score"`
+	SubmittedProjects int     `json:"submitted_projects"`
+	RequiredProjects  int     `json:"required_projects"`
+}
+
+type CompletionCriterion struct {
+	Criterion string  `json:"criterion"`
+	Required  float64 `json:"required"`
+	Actual    float64 `json:"actual"`
+	Passed    bool    `json:"passed"`
+}
+
+type CompletionOutcome struct {
+	CompletionStats
+	Result   entity.Status         `json:"result"`
+	Criteria []CompletionCriterion `json:"criteria"`
+	Reasons  []string              `json:"reasons"`
+}
+
+type CompletionResult struct {
+	CourseID  uint                `json:"course_id"`
+	Applied   bool                `json:"applied"`
+	Completed int                 `json:"completed"`
+	Failed    int                 `json:"failed"`
+	Outcomes  []CompletionOutcome `json:"outcomes"`
+}
@@ -1,26 +1,28 @@
 package model
 
 import (
-	"time"
-
 	"github.com/nadyafa/go-learn/middleware"
 )
 
 type CourseReq struct {
-	CourseName  string                `json:"course_name" validate:"required"`
-	Description string                `json:"description"`
-	MentorID    uint                  `json:"mentor_id"`
-	StartDate   middleware.CustomTime `json:"start_date" validate:"required"`
-	EndDate     middleware.CustomTime `json:"end_date" validate:"required"`
+	CourseName           string                `json:"course_name" validate:"required"`
+	Description          string                `json:"description"`
+	MentorID             uint                  `json:"mentor_id"`
+	LateThresholdMinutes *int                  `json:"late_threshold_minutes"`
+	DropDeadlineDays     *int                  `json:"drop_deadline_days"`
+	StartDate            middleware.CustomTime `json:"start_date" validate:"required"`
+	EndDate              middleware.CustomTime `json:"end_date" validate:"required"`
 }
 
 type CourseResp struct {
-	CourseID    uint      `json:"course_id"`
-	CourseName  string    `json:"course_name"`
-	Description string    `json:"description"`
-	MentorID    uint      `json:"mentor_id"`
-	StartDate   time.Time `json:"start_date"`
-	EndDate     time.Time `json:"end_date"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	CourseID             uint                  `json:"course_id"`
+	CourseName           string                `json:"course_name"`
+	Description          string                `json:"description"`
+	MentorID             uint                  `json:"mentor_id"`
+	LateThresholdMinutes int                   `json:"late_threshold_minutes"`
+	DropDeadlineDays     int                   `json:"drop_deadline_days"`
+	StartDate            middleware.CustomTime `json:"start_date"`
+	EndDate              middleware.CustomTime `json:"end_date"`
+	CreatedAt            middleware.CustomTime `json:"created_at"`
+	UpdatedAt            middleware.CustomTime `json:"updated_at"`
 }
@@ -4,15 +4,116 @@ import (
 	"time"
 
 	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
 )
 
+type EnrollReq struct {
+	StudentID  uint   `json:"student_id"`
+	InviteCode string `json:"invite_code"`
+}
+
 type EnrollResp struct {
 	EnrollmentID uint `json:"enrollment_id" validate:"required"`
 	StudentID    uint `json:"student_id" validate:"required"`
 	// UserRole       entity.Role   `json:"user_role" validate:"required"`
-	CourseID       uint          `json:"course_id" validate:"required"`
-	EnrollmentDate *time.Time    `json:"enrollment_date"`
-	EnrollStatus   entity.Status `json:"completion_status" gorm:"default:pending"`
+	CourseID        uint                   `json:"course_id" validate:"required"`
+	EnrollmentDate  *middleware.CustomTime `json:"enrollment_date"`
+	EnrollStatus    entity.Status          `json:"completion_status" gorm:"default:pending"`
+	DropRequestedAt *middleware.CustomTime `json:"drop_requested_at,omitempty"`
+	DropReason      string                 `json:"drop_reason,omitempty"`
+	CreatedAt       middleware.CustomTime  `json:"created_at"`
+	UpdatedAt       middleware.CustomTime  `json:"updated_at"`
+}
+
+type EnrollStatusReq struct {
+	EnrollStatus string `json:"enroll_status" binding:"required"`
+	Reason       string `json:"reason"`
+}
+
+type DropEnrollReq struct {
+	Reason string `json:"reason"`
+}
+
+type ReviewDropReq struct {
+	Approve *bool  `json:"approve" binding:"required"`
+	Reason  string `json:"reason"`
+}
+
+type EnrollHistoryResp struct {
+	HistoryID    uint                  `json:"history_id"`
+	EnrollmentID uint                  `json:"enrollment_id"`
+	FromStatus   entity.Status         `json:"from_status"`
+	ToStatus     entity.Status         `json:"to_status"`
+	ActorID      uint                  `json:"actor_id"`
+	ActorRole    entity.Role           `json:"actor_role"`
+	Reason       string                `json:"reason"`
+	CreatedAt    middleware.CustomTime `json:"created_at"`
+}
+
+// filter for enrollment listing, zero values are ignored
+type EnrollFilter struct {
+	CourseID  uint
+	StudentID uint
+	Status    entity.Status
+	Search    string // matches username or email
+	Page      int
+	PerPage   int // 0 means no limit, used for export
+}
+
+// enrollment joined with user & course details
+type EnrollDetail struct {
+	EnrollmentID   uint          `json:"enrollment_id"`
+	StudentID      uint          `json:"student_id"`
+	Username       string        `json:"username"`
+	Email          string        `json:"email"`
+	CourseID       uint          `json:"course_id"`
+	CourseName     string        `json:"course_name"`
+	EnrollmentDate time.Time     `json:"enrollment_date"`
+	EnrollStatus   entity.Status `json:"completion_status"`
 	CreatedAt      time.Time     `json:"created_at"`
-	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type EnrollDetailResp struct {
+	EnrollmentID   uint                   `json:"enrollment_id"`
+	StudentID      uint                   `json:"student_id"`
+	Username       string                 `json:"username"`
+	Email          string                 `json:"email"`
+	CourseID       uint                   `json:"course_id"`
+	CourseName     string                 `json:"course_name"`
+	EnrollmentDate *middleware.CustomTime `json:"enrollment_date"`
+	EnrollStatus   entity.Status          `json:"completion_status"`
+	CreatedAt      middleware.CustomTime  `json:"created_at"`
+}
+
+// max rows accepted in one enrollment import
+const MaxEnrollImportRows = 1000
+
+type EnrollImportReq struct {
+	EnrollStatus string `form:"enroll_status"`
+	DryRun       bool   `form:"dry_run"`
+}
+
+// user to enroll, user without id is created during import
+type EnrollImport struct {
+	User       *entity.User
+	Enrollment entity.Enrollment
+}
+
+type EnrollImportRow struct {
+	Row      int      `json:"row"`
+	Username string   `json:"username"`
+	Email    string   `json:"email"`
+	UserID   uint     `json:"user_id,omitempty"`
+	NewUser  bool     `json:"new_user"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+type EnrollImportResult struct {
+	DryRun       bool              `json:"dry_run"`
+	EnrollStatus entity.Status     `json:"enroll_status"`
+	TotalRows    int               `json:"total_rows"`
+	InvalidRows  int               `json:"invalid_rows"`
+	UsersCreated int               `json:"users_created"`
+	Enrolled     int               `json:"enrolled"`
+	Rows         []EnrollImportRow `json:"rows"`
 }
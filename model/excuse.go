@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+)
+
+type ExcuseReq struct {
+	Reason string `form:"reason" validate:"required"`
+}
+
+type ReviewExcuseReq struct {
+	Status     string `json:"status" validate:"required,oneof=approved rejected"`
+	ReviewNote string `json:"review_note"`
+}
+
+type ExcuseResp struct {
+	ExcuseID     uint                   `json:"excuse_id"`
+	StudentID    uint                   `json:"student_id"`
+	ClassID      uint                   `json:"class_id"`
+	CourseID     uint                   `json:"course_id"`
+	Reason       string                 `json:"reason"`
+	DocumentPath string                 `json:"document_path"`
+	ScanStatus   entity.ScanStatus      `json:"scan_status,omitempty"`
+	Status       string                 `json:"status"`
+	ReviewerID   *uint                  `json:"reviewer_id"`
+	ReviewNote   string                 `json:"review_note"`
+	ReviewedAt   *middleware.CustomTime `json:"reviewed_at"`
+	CreatedAt    middleware.CustomTime  `json:"created_at"`
+	UpdatedAt    middleware.CustomTime  `json:"updated_at"`
+}
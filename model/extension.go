@@ -0,0 +1,20 @@
+package model
+
+import "github.com/nadyafa/go-learn/middleware"
+
+type ExtensionReq struct {
+	StudentID uint                  `json:"student_id" binding:"required"`
+	Deadline  middleware.CustomTime `json:"deadline"`
+	Reason    string                `json:"reason"`
+}
+
+type ExtensionResp struct {
+	ExtensionID uint                  `json:"extension_id"`
+	ProjectID   uint                  `json:"project_id"`
+	StudentID   uint                  `json:"student_id"`
+	Deadline    middleware.CustomTime `json:"deadline"`
+	Reason      string                `json:"reason"`
+	GrantedBy   uint                  `json:"granted_by"`
+	CreatedAt   middleware.CustomTime `json:"created_at"`
+	UpdatedAt   middleware.CustomTime `json:"updated_at"`
+}
@@ -0,0 +1,103 @@
+package model
+
+import "github.com/nadyafa/go-learn/middleware"
+
+type GradeCategoryReq struct {
+	Name       string  `json:"name" binding:"required"`
+	Weight     float64 `json:"weight"`
+	DropLowest int     `json:"drop_lowest"`
+}
+
+type GradeCategoryResp struct {
+	CategoryID uint                  `json:"category_id"`
+	CourseID   uint                  `json:"course_id"`
+	Name       string                `json:"name"`
+	Weight     float64               `json:"weight"`
+	DropLowest int                   `json:"drop_lowest"`
+	CreatedAt  middleware.CustomTime `json:"created_at"`
+	UpdatedAt  middleware.CustomTime `json:"updated_at"`
+}
+
+type GradeLetterReq struct {
+	Letter   string  `json:"letter"`
+	MinScore float64 `json:"min_score"`
+}
+
+type GradeScaleReq struct {
+	Letters []GradeLetterReq `json:"letters" binding:"required"`
+}
+
+type GradeLetterResp struct {
+	Letter   string  `json:"letter"`
+	MinScore float64 `json:"min_score"`
+}
+
+type GradeExcusalReq struct {
+	StudentID uint   `json:"student_id" binding:"required"`
+	Reason    string `json:"reason"`
+}
+
+type GradeExcusalResp struct {
+	ExcusalID uint                  `json:"excusal_id"`
+	ProjectID uint                  `json:"project_id"`
+	StudentID uint                  `json:"student_id"`
+	Reason    string                `json:"reason"`
+	ExcusedBy uint                  `json:"excused_by"`
+	CreatedAt middleware.CustomTime `json:"created_at"`
+}
+
+// state of a project in a student's grade
+type GradeItemStatus string
+
+const (
+	// latest attempt has score or feedback
+	GradeGraded GradeItemStatus = "graded"
+	// submitted but not graded yet, left out of the total
+	GradePending GradeItemStatus = "pending"
+	// deadline passed without submission, counts as 0
+	GradeMissing GradeItemStatus = "missing"
+	// deadline hasn't passed yet
+	GradeNotDue GradeItemStatus = "not_due"
+	// optional project that wasn't submitted
+	GradeSkipped GradeItemStatus = "skipped"
+	GradeExcused GradeItemStatus = "excused"
+)
+
+type GradebookItem struct {
+	ProjectID  uint            `json:"project_id"`
+	CategoryID *uint           `json:"category_id"`
+	Status     GradeItemStatus `json:"status"`
+	Score      *float64        `json:"score"`
+	Dropped    bool            `json:"dropped"`
+}
+
+type GradebookCategoryScore struct {
+	CategoryID *uint    `json:"category_id"`
+	Name       string   `json:"name"`
+	Weight     float64  `json:"weight"`
+	Average    *float64 `json:"average"`
+}
+
+type GradebookStudent struct {
+	StudentID  uint                     `json:"student_id"`
+	Username   string                   `json:"username"`
+	Email      string                   `json:"email"`
+	Total      *float64                 `json:"total"`
+	Letter     string                   `json:"letter"`
+	Categories []GradebookCategoryScore `json:"categories"`
+	Items      []GradebookItem          `json:"items"`
+}
+
+type GradebookProject struct {
+	ProjectID   uint   `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	CategoryID  *uint  `json:"category_id"`
+	Optional    bool   `json:"optional"`
+}
+
+type Gradebook struct {
+	CourseID uint               `json:"course_id"`
+	Scale    []GradeLetterResp  `json:"scale"`
+	Projects []GradebookProject `json:"projects"`
+	Students []GradebookStudent `json:"students"`
+}
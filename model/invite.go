@@ -0,0 +1,37 @@
+package model
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+)
+
+type InviteReq struct {
+	ExpiresAt   *middleware.CustomTime `json:"expires_at"`
+	MaxUses     int                    `json:"max_uses"`
+	EmailDomain string                 `json:"email_domain"`
+}
+
+type InviteRedemptionResp struct {
+	StudentID    uint                  `json:"student_id"`
+	EnrollmentID uint                  `json:"enrollment_id"`
+	CreatedAt    middleware.CustomTime `json:"created_at"`
+}
+
+type InviteResp struct {
+	InviteID      uint                   `json:"invite_id"`
+	CourseID      uint                   `json:"course_id"`
+	Code          string                 `json:"code"`
+	Link          string                 `json:"link"`
+	Status        entity.InviteStatus    `json:"status"`
+	CreatedBy     uint                   `json:"created_by"`
+	ExpiresAt     *middleware.CustomTime `json:"expires_at"`
+	MaxUses       int                    `json:"max_uses"`
+	UsedCount     int                    `json:"used_count"`
+	RemainingUses *int                   `json:"remaining_uses"`
+	EmailDomain   string                 `json:"email_domain"`
+	RevokedAt     *middleware.CustomTime `json:"revoked_at"`
+	CreatedAt     middleware.CustomTime  `json:"created_at"`
+	UpdatedAt     middleware.CustomTime  `json:"updated_at"`
+
+	Redemptions []InviteRedemptionResp `json:"redemptions,omitempty"`
+}
@@ -0,0 +1,27 @@
+package model
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+)
+
+type Pagination struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int64 `json:"total_pages"`
+}
+
+func NewPagination(page, perPage int, total int64) Pagination {
+	pagination := Pagination{
+		Page:    page,
+		PerPage: perPage,
+		Total:   total,
+	}
+
+	if perPage > 0 {
+		pagination.TotalPages = (total + int64(perPage) - 1) / int64(perPage)
+	}
+
+	return pagination
+}
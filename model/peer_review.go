@@ -0,0 +1,30 @@
+package model
+
+import "github.com/nadyafa/go-learn/middleware"
+
+type PeerReviewReq struct {
+	Score   *float64 `json:"score"`
+	Comment string   `json:"comment"`
+
+	// required instead of score when project has a rubric
+	Criteria []CriterionGradeReq `json:"criteria"`
+}
+
+type PeerReviewQualityReq struct {
+	Quality int    `json:"quality" validate:"required"`
+	Note    string `json:"note"`
+}
+
+// reviewer_id is left out when the review is shown to the reviewed student
+type PeerReviewResp struct {
+	ReviewID     uint                   `json:"review_id"`
+	ProjectID    uint                   `json:"project_id"`
+	ProjectSubID uint                   `json:"project_sub_id"`
+	ReviewerID   *uint                  `json:"reviewer_id,omitempty"`
+	Score        *float64               `json:"score"`
+	Comment      string                 `json:"comment"`
+	Grades       []CriterionGradeResp   `json:"grades,omitempty"`
+	SubmittedAt  *middleware.CustomTime `json:"submitted_at"`
+	Quality      *int                   `json:"quality,omitempty"`
+	QualityNote  string                 `json:"quality_note,omitempty"`
+}
@@ -1,8 +1,7 @@
 package model
 
 import (
-	"time"
-
+	"github.com/nadyafa/go-learn/entity"
 	"github.com/nadyafa/go-learn/middleware"
 )
 
@@ -10,20 +9,102 @@ type CreateProject struct {
 	ProjectName string                `json:"project_name" validate:"required"`
 	Description string                `json:"description"`
 	Deadline    middleware.CustomTime `json:"deadline" validate:"required"`
+	Optional    bool                  `json:"optional"`
+	MaxAttempts int                   `json:"max_attempts"`
+
+	LatePolicy    entity.LatePolicy `json:"late_policy"`
+	GraceHours    int               `json:"grace_hours"`
+	PenaltyPerDay float64           `json:"penalty_per_day"`
+	PenaltyCap    *float64          `json:"penalty_cap"`
+
+	RubricID   *uint `json:"rubric_id"`
+	CategoryID *uint `json:"category_id"`
+
+	TeamProject     bool `json:"team_project"`
+	MinTeamSize     int  `json:"min_team_size"`
+	MaxTeamSize     int  `json:"max_team_size"`
+	SelfFormedTeams bool `json:"self_formed_teams"`
+
+	PeerReviewEnabled  bool                   `json:"peer_review_enabled"`
+	PeerReviewers      int                    `json:"peer_reviewers"`
+	PeerReviewDeadline *middleware.CustomTime `json:"peer_review_deadline"`
+	PeerWeight         float64                `json:"peer_weight"`
+
+	// seconds the hidden test suite may run
+	TestTimeout int `json:"test_timeout"`
+
+	// extensions like .pdf or .go & max size in mb a submission may have
+	AllowedFileTypes []string `json:"allowed_file_types"`
+	MaxFileSize      int      `json:"max_file_size"`
 }
 
 type UpdateProject struct {
 	ProjectName string                `json:"project_name"`
 	Description string                `json:"description"`
 	Deadline    middleware.CustomTime `json:"deadline"`
+	Optional    *bool                 `json:"optional"`
+	MaxAttempts *int                  `json:"max_attempts"`
+
+	LatePolicy    entity.LatePolicy `json:"late_policy"`
+	GraceHours    *int              `json:"grace_hours"`
+	PenaltyPerDay *float64          `json:"penalty_per_day"`
+	PenaltyCap    *float64          `json:"penalty_cap"`
+
+	// 0 detaches the rubric & uncategorizes the project
+	RubricID   *uint `json:"rubric_id"`
+	CategoryID *uint `json:"category_id"`
+
+	TeamProject     *bool `json:"team_project"`
+	MinTeamSize     *int  `json:"min_team_size"`
+	MaxTeamSize     *int  `json:"max_team_size"`
+	SelfFormedTeams *bool `json:"self_formed_teams"`
+
+	PeerReviewEnabled  *bool                  `json:"peer_review_enabled"`
+	PeerReviewers      *int                   `json:"peer_reviewers"`
+	PeerReviewDeadline *middleware.CustomTime `json:"peer_review_deadline"`
+	PeerWeight         *float64               `json:"peer_weight"`
+
+	TestTimeout *int `json:"test_timeout"`
+
+	// empty list resets to the default file types
+	AllowedFileTypes []string `json:"allowed_file_types"`
+	MaxFileSize      *int     `json:"max_file_size"`
 }
 
 type ProjectResp struct {
-	ProjectID   uint      `json:"project_id"`
-	CourseID    uint      `json:"course_id"`
-	ProjectName string    `json:"project_name"`
-	Description string    `json:"description"`
-	Deadline    time.Time `json:"deadline"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ProjectID   uint                  `json:"project_id"`
+	CourseID    uint                  `json:"course_id"`
+	ProjectName string                `json:"project_name"`
+	Description string                `json:"description"`
+	Deadline    middleware.CustomTime `json:"deadline"`
+	Optional    bool                  `json:"optional"`
+	MaxAttempts int                   `json:"max_attempts"`
+
+	LatePolicy    entity.LatePolicy `json:"late_policy"`
+	GraceHours    int               `json:"grace_hours"`
+	PenaltyPerDay float64           `json:"penalty_per_day"`
+	PenaltyCap    float64           `json:"penalty_cap"`
+
+	RubricID   *uint `json:"rubric_id"`
+	CategoryID *uint `json:"category_id"`
+
+	TeamProject     bool `json:"team_project"`
+	MinTeamSize     int  `json:"min_team_size"`
+	MaxTeamSize     int  `json:"max_team_size"`
+	SelfFormedTeams bool `json:"self_formed_teams"`
+
+	PeerReviewEnabled  bool                   `json:"peer_review_enabled"`
+	PeerReviewers      int                    `json:"peer_reviewers"`
+	PeerReviewDeadline *middleware.CustomTime `json:"peer_review_deadline"`
+	PeerWeight         float64                `json:"peer_weight"`
+	PeerAssignedAt     *middleware.CustomTime `json:"peer_assigned_at"`
+
+	HasTestSuite bool `json:"has_test_suite"`
+	TestTimeout  int  `json:"test_timeout"`
+
+	AllowedFileTypes []string `json:"allowed_file_types"`
+	MaxFileSize      int      `json:"max_file_size"`
+
+	CreatedAt middleware.CustomTime `json:"created_at"`
+	UpdatedAt middleware.CustomTime `json:"updated_at"`
 }
@@ -1,10 +1,18 @@
 package model
 
-import "time"
+import (
+	"io"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+)
 
 type ProjectSubMentor struct {
 	Description string `json:"description"`
 	Score       int    `json:"score" validate:"required"`
+
+	// required instead of score when project has a rubric
+	Criteria []CriterionGradeReq `json:"criteria"`
 }
 
 type ProjectSubStudent struct {
@@ -12,20 +20,75 @@ type ProjectSubStudent struct {
 	// Score       int    `json:"score"`
 }
 
+// uploaded file streamed to the storage
+type FileUpload struct {
+	File io.Reader
+	Name string
+	Size int64
+}
+
+// expiring link to download a stored file
+type FileURLResp struct {
+	URL       string                `json:"url"`
+	FileName  string                `json:"file_name"`
+	ExpiresAt middleware.CustomTime `json:"expires_at"`
+}
+
 type StudentSubmitResp struct {
-	ProjectSubID   uint      `json:"project_sub_id"`
-	ProjectID      uint      `json:"project_id"`
-	StudentID      uint      `json:"student_id"`
-	SubmissionDate time.Time `json:"submission_date"`
-	ProjectPath    string    `json:"project_path"`
+	ProjectSubID   uint                  `json:"project_sub_id"`
+	ProjectID      uint                  `json:"project_id"`
+	StudentID      uint                  `json:"student_id"`
+	TeamID         *uint                 `json:"team_id"`
+	Attempt        int                   `json:"attempt"`
+	SubmissionDate middleware.CustomTime `json:"submission_date"`
+	DaysLate       int                   `json:"days_late"`
+	LatePenalty    float64               `json:"late_penalty"`
+	ProjectPath    string                `json:"project_path"`
+	ScanStatus     entity.ScanStatus     `json:"scan_status,omitempty"`
 }
 
 type MentorSubmitResp struct {
-	ProjectSubID   uint      `json:"project_sub_id"`
-	ProjectID      uint      `json:"project_id"`
-	StudentID      uint      `json:"student_id"`
-	SubmissionDate time.Time `json:"submission_date"`
-	ProjectPath    string    `json:"project_path"`
-	Score          int       `json:"score"`
-	Description    string    `json:"description"`
+	ProjectSubID   uint                  `json:"project_sub_id"`
+	ProjectID      uint                  `json:"project_id"`
+	StudentID      uint                  `json:"student_id"`
+	TeamID         *uint                 `json:"team_id"`
+	Attempt        int                   `json:"attempt"`
+	IsLatest       bool                  `json:"is_latest"`
+	SubmissionDate middleware.CustomTime `json:"submission_date"`
+	DaysLate       int                   `json:"days_late"`
+	ProjectPath    string                `json:"project_path"`
+	Score          int                   `json:"score"`
+	MentorScore    *int                  `json:"mentor_score,omitempty"`
+	PeerScore      *float64              `json:"peer_score,omitempty"`
+	LatePenalty    float64               `json:"late_penalty"`
+	FinalScore     float64               `json:"final_score"`
+	Grades         []CriterionGradeResp  `json:"grades,omitempty"`
+	Description    string                `json:"description"`
+
+	AutoStatus     entity.AutoGradeStatus `json:"auto_status,omitempty"`
+	AutoScore      *int                   `json:"auto_score,omitempty"`
+	AutoFeedback   string                 `json:"auto_feedback,omitempty"`
+	AutoOverridden bool                   `json:"auto_overridden,omitempty"`
+
+	ScanStatus    entity.ScanStatus `json:"scan_status,omitempty"`
+	ScanSignature string            `json:"scan_signature,omitempty"`
+}
+
+// output of hidden tests is only shown to admin & mentor
+type TestResultResp struct {
+	Package string  `json:"package"`
+	Test    string  `json:"test"`
+	Status  string  `json:"status"`
+	Elapsed float64 `json:"elapsed"`
+	Output  string  `json:"output,omitempty"`
+}
+
+type AutoGradeResp struct {
+	ProjectSubID   uint                   `json:"project_sub_id"`
+	AutoStatus     entity.AutoGradeStatus `json:"auto_status"`
+	AutoScore      *int                   `json:"auto_score"`
+	AutoFeedback   string                 `json:"auto_feedback"`
+	AutoOverridden bool                   `json:"auto_overridden"`
+	AutoGradedAt   *middleware.CustomTime `json:"auto_graded_at"`
+	Tests          []TestResultResp       `json:"tests"`
 }
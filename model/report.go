@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// at risk threshold when none given, in percent
+const DefaultAtRiskThreshold = 75.0
+
+type StudentAttendReport struct {
+	StudentID      uint    `json:"student_id"`
+	Username       string  `json:"username"`
+	Email          string  `json:"email"`
+	TotalClasses   int     `json:"total_classes"`
+	Present        int     `json:"present"`
+	Late           int     `json:"late"`
+	Excused        int     `json:"excused"`
+	Absent         int     `json:"absent"`
+	AttendanceRate float64 `json:"attendance_rate"`
+}
+
+type ClassTurnoutReport struct {
+	ClassID     uint      `json:"class_id"`
+	ClassName   string    `json:"class_name"`
+	StartDate   time.Time `json:"start_date"`
+	Enrolled    int       `json:"enrolled"`
+	Present     int       `json:"present"`
+	Late        int       `json:"late"`
+	Excused     int       `json:"excused"`
+	Absent      int       `json:"absent"`
+	TurnoutRate float64   `json:"turnout_rate"`
+}
+
+type AttendTrendReport struct {
+	Period         time.Time `json:"period"`
+	Classes        int       `json:"classes"`
+	Attended       int       `json:"attended"`
+	AverageTurnout float64   `json:"average_turnout"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "github.com/nadyafa/go-learn/middleware"
+
+type RoomReq struct {
+	RoomName string `json:"room_name" validate:"required"`
+	Location string `json:"location"`
+	Capacity int    `json:"capacity" validate:"required,min=1"`
+}
+
+type RoomResp struct {
+	RoomID    uint                  `json:"room_id"`
+	RoomName  string                `json:"room_name"`
+	Location  string                `json:"location"`
+	Capacity  int                   `json:"capacity"`
+	CreatedAt middleware.CustomTime `json:"created_at"`
+	UpdatedAt middleware.CustomTime `json:"updated_at"`
+}
@@ -0,0 +1,58 @@
+package model
+
+import "github.com/nadyafa/go-learn/middleware"
+
+type RubricLevelReq struct {
+	Points     int    `json:"points"`
+	Descriptor string `json:"descriptor"`
+}
+
+type RubricCriterionReq struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Levels      []RubricLevelReq `json:"levels"`
+}
+
+type RubricReq struct {
+	Title       string               `json:"title" binding:"required"`
+	Description string               `json:"description"`
+	Criteria    []RubricCriterionReq `json:"criteria" binding:"required"`
+}
+
+type RubricLevelResp struct {
+	LevelID    uint   `json:"level_id"`
+	Points     int    `json:"points"`
+	Descriptor string `json:"descriptor"`
+}
+
+type RubricCriterionResp struct {
+	CriterionID uint              `json:"criterion_id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Position    int               `json:"position"`
+	Levels      []RubricLevelResp `json:"levels"`
+}
+
+type RubricResp struct {
+	RubricID    uint                  `json:"rubric_id"`
+	Title       string                `json:"title"`
+	Description string                `json:"description"`
+	CreatedBy   uint                  `json:"created_by"`
+	MaxPoints   int                   `json:"max_points"`
+	Criteria    []RubricCriterionResp `json:"criteria"`
+	CreatedAt   middleware.CustomTime `json:"created_at"`
+	UpdatedAt   middleware.CustomTime `json:"updated_at"`
+}
+
+type CriterionGradeReq struct {
+	CriterionID uint   `json:"criterion_id"`
+	LevelID     uint   `json:"level_id"`
+	Comment     string `json:"comment"`
+}
+
+type CriterionGradeResp struct {
+	CriterionID uint   `json:"criterion_id"`
+	LevelID     uint   `json:"level_id"`
+	Points      int    `json:"points"`
+	Comment     string `json:"comment"`
+}
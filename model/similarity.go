@@ -0,0 +1,40 @@
+package model
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+)
+
+type SimilarityReq struct {
+	// 0-1, default threshold when empty
+	Threshold *float64 `json:"threshold"`
+}
+
+type SimilarityReportResp struct {
+	ReportID    uint                   `json:"report_id"`
+	ProjectID   uint                   `json:"project_id"`
+	Status      entity.ReportStatus    `json:"status"`
+	Threshold   float64                `json:"threshold"`
+	Analyzed    int                    `json:"analyzed"`
+	Skipped     int                    `json:"skipped"`
+	Error       string                 `json:"error,omitempty"`
+	RequestedBy uint                   `json:"requested_by"`
+	CreatedAt   middleware.CustomTime  `json:"created_at"`
+	CompletedAt *middleware.CustomTime `json:"completed_at"`
+	Pairs       []SimilarityPairResp   `json:"pairs,omitempty"`
+}
+
+type SimilarityPairResp struct {
+	ProjectSubID   uint                    `json:"project_sub_id"`
+	StudentID      uint                    `json:"student_id"`
+	MatchSubID     uint                    `json:"match_sub_id"`
+	MatchStudentID uint                    `json:"match_student_id"`
+	ExactDuplicate bool                    `json:"exact_duplicate"`
+	Similarity     float64                 `json:"similarity"`
+	Segments       []SimilaritySegmentResp `json:"segments"`
+}
+
+type SimilaritySegmentResp struct {
+	Text  string `json:"text"`
+	Words int    `json:"words"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "github.com/nadyafa/go-learn/middleware"
+
+type TeamReq struct {
+	Name string `json:"name"`
+	// members picked by mentor, student creating a team joins it instead
+	StudentIDs []uint `json:"student_ids"`
+}
+
+type GenerateTeamsReq struct {
+	// defaults to project max team size
+	TeamSize int `json:"team_size"`
+}
+
+type TeamMemberReq struct {
+	Adjustment *float64 `json:"adjustment" binding:"required"`
+}
+
+type TeamMemberResp struct {
+	StudentID  uint                  `json:"student_id"`
+	Adjustment float64               `json:"adjustment"`
+	JoinedAt   middleware.CustomTime `json:"joined_at"`
+}
+
+type TeamResp struct {
+	TeamID    uint                  `json:"team_id"`
+	ProjectID uint                  `json:"project_id"`
+	Name      string                `json:"name"`
+	CreatedBy uint                  `json:"created_by"`
+	Members   []TeamMemberResp      `json:"members"`
+	CreatedAt middleware.CustomTime `json:"created_at"`
+}
@@ -1,27 +1,37 @@
-package model
-
-import (
-	"time"
-)
-
-type UserSignup struct {
-	Username string `json:"username" validate:"required,alphanum,min=6,max=100"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=8,alphanum"`
-	Role     string `json:"role" validate:"required,oneof=student mentor"`
-}
-
-type UserSignin struct {
-	Username string `json:"username" validate:"required"`
-	Email    string `json:"email" validate:"omitempty,email"`
-	Password string `json:"password" validate:"required"`
-}
-
-type UserResponse struct {
-	UserID    uint      `json:"user_id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
+package model
+
+import (
+	"github.com/nadyafa/go-learn/middleware"
+)
+
+type UserSignup struct {
+	Username string `json:"username" validate:"required,alphanum,min=6,max=100"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,alphanum"`
+	Role     string `json:"role" validate:"required,oneof=student mentor"`
+	Timezone string `json:"timezone"`
+}
+
+type UserSignin struct {
+	Username string `json:"username" validate:"required"`
+	Email    string `json:"email" validate:"omitempty,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type UserResponse struct {
+	UserID    uint                  `json:"user_id"`
+	Username  string                `json:"username"`
+	Email     string                `json:"email"`
+	Role      string                `json:"role"`
+	Timezone  string                `json:"timezone"`
+	CreatedAt middleware.CustomTime `json:"created_at"`
+	UpdatedAt middleware.CustomTime `json:"updated_at"`
+}
+
+type SetPassword struct {
+	Password string `json:"password" validate:"required,min=8,alphanum"`
+}
+
+type UserTimezone struct {
+	Timezone string `json:"timezone" validate:"required"`
+}
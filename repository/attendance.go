@@ -1,14 +1,25 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/nadyafa/go-learn/entity"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AttendRepo interface {
 	CreateAttendance(attendClass entity.Attendance) (*entity.Attendance, error)
 	GetClassAttendances(courseID, classID string) ([]entity.Attendance, error)
 	DeleteAttendanceByID(courseID, classID, attendID string) error
+	GetStudentClassAttendance(classID, studentID string) (*entity.Attendance, error)
+	UpdateAttendance(attend *entity.Attendance) error
+	CreateCheckinCode(checkinCode *entity.CheckinCode) error
+	GetActiveCheckinCode(classID string, now time.Time) (*entity.CheckinCode, error)
+	GetValidCheckinCode(classID, code string, now time.Time) (*entity.CheckinCode, error)
+	AddCheckinAttempt(classID, studentID uint) (int, error)
+	UpsertAttendances(attends []entity.Attendance) error
+	CreateMissingAttendances(attends []entity.Attendance) (int64, error)
 }
 
 type AttendRepoImpl struct {
@@ -48,3 +59,96 @@ func (r *AttendRepoImpl) DeleteAttendanceByID(courseID, classID, attendID string
 
 	return nil
 }
+
+func (r *AttendRepoImpl) GetStudentClassAttendance(classID, studentID string) (*entity.Attendance, error) {
+	var attendance entity.Attendance
+
+	if err := r.db.Where("class_id = ? AND student_id = ?", classID, studentID).First(&attendance).Error; err != nil {
+		return nil, err
+	}
+
+	return &attendance, nil
+}
+
+func (r *AttendRepoImpl) UpdateAttendance(attend *entity.Attendance) error {
+	if err := r.db.Save(attend).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *AttendRepoImpl) CreateCheckinCode(checkinCode *entity.CheckinCode) error {
+	if err := r.db.Create(checkinCode).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// latest check-in code of a class that hasn't expired yet
+func (r *AttendRepoImpl) GetActiveCheckinCode(classID string, now time.Time) (*entity.CheckinCode, error) {
+	var checkinCode entity.CheckinCode
+
+	if err := r.db.Where("class_id = ? AND expires_at > ?", classID, now).Order("expires_at DESC").First(&checkinCode).Error; err != nil {
+		return nil, err
+	}
+
+	return &checkinCode, nil
+}
+
+func (r *AttendRepoImpl) GetValidCheckinCode(classID, code string, now time.Time) (*entity.CheckinCode, error) {
+	var checkinCode entity.CheckinCode
+
+	if err := r.db.Where("class_id = ? AND code = ? AND expires_at > ?", classID, code, now).First(&checkinCode).Error; err != nil {
+		return nil, err
+	}
+
+	return &checkinCode, nil
+}
+
+// count one more code tried by the student, returns the attempts so far
+func (r *AttendRepoImpl) AddCheckinAttempt(classID, studentID uint) (int, error) {
+	attempt := entity.CheckinAttempt{
+		ClassID:   classID,
+		StudentID: studentID,
+		Attempts:  1,
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "class_id"}, {Name: "student_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"attempts":   gorm.Expr("checkin_attempts.attempts + 1"),
+			"updated_at": time.Now(),
+		}),
+	}, clause.Returning{Columns: []clause.Column{{Name: "attempts"}}}).Create(&attempt).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return attempt.Attempts, nil
+}
+
+// create or overwrite status of each student class attendance in one transaction
+func (r *AttendRepoImpl) UpsertAttendances(attends []entity.Attendance) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "student_id"}, {Name: "class_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "attended", "attend_at"}),
+		}).Create(&attends).Error
+	})
+}
+
+// create attendance only for students that have no record yet
+func (r *AttendRepoImpl) CreateMissingAttendances(attends []entity.Attendance) (int64, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "student_id"}, {Name: "class_id"}},
+		DoNothing: true,
+	}).Create(&attends)
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"fmt"
+
 	"github.com/nadyafa/go-learn/entity"
 	"gorm.io/gorm"
 )
@@ -9,6 +11,8 @@ type AuthRepo interface {
 	UserSignup(user *entity.User) error
 	FindByUsername(username string) (*entity.User, error)
 	FindByEmail(email string) (*entity.User, error)
+	FindByPasswordToken(tokenHash string) (*entity.User, error)
+	SetPassword(user *entity.User, tokenHash, hashedPassword string) error
 }
 
 type AuthRepoImpl struct {
@@ -42,3 +46,28 @@ func (r *AuthRepoImpl) FindByEmail(email string) (*entity.User, error) {
 	err := r.db.Where("email = ?", email).First(&user).Error
 	return &user, err
 }
+
+func (r *AuthRepoImpl) FindByPasswordToken(tokenHash string) (*entity.User, error) {
+	var user entity.User
+
+	err := r.db.Where("password_token_hash = ?", tokenHash).First(&user).Error
+	return &user, err
+}
+
+// token is cleared in the same update, a link used twice at once only sets the password once
+func (r *AuthRepoImpl) SetPassword(user *entity.User, tokenHash, hashedPassword string) error {
+	result := r.db.Model(user).Where("password_token_hash = ?", tokenHash).Updates(map[string]interface{}{
+		"password":                  hashedPassword,
+		"password_token_hash":       "",
+		"password_token_expires_at": nil,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("password link has already been used")
+	}
+
+	return nil
+}
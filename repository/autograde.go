@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+)
+
+type AutogradeRepo interface {
+	ResetStaleRuns(startedBefore time.Time) error
+	GetPendingSubs(limit int) ([]entity.ProjectSub, error)
+	ClaimSub(projectSubID uint, now time.Time) (bool, error)
+	GetProject(projectID uint) (*entity.Project, error)
+	SaveResult(projectSub *entity.ProjectSub, results []entity.TestResult) error
+	SaveError(projectSub *entity.ProjectSub) error
+	QueueSubs(projectID uint, projectSubID uint) (int64, error)
+}
+
+type AutogradeRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewAutogradeRepo(db *gorm.DB) AutogradeRepo {
+	return &AutogradeRepoImpl{
+		db: db,
+	}
+}
+
+// runs left over from a stopped server go back to the queue
+func (r *AutogradeRepoImpl) ResetStaleRuns(startedBefore time.Time) error {
+	return r.db.Model(&entity.ProjectSub{}).Where("auto_status = ? AND auto_started_at < ?", entity.AutoGradeRunning, startedBefore).
+		Update("auto_status", entity.AutoGradePending).Error
+}
+
+// oldest queued submissions first, files still in quarantine wait for their scan
+func (r *AutogradeRepoImpl) GetPendingSubs(limit int) ([]entity.ProjectSub, error) {
+	var projectSubs []entity.ProjectSub
+
+	if err := r.db.Where("auto_status = ? AND (scan_status IS NULL OR scan_status IN ?)", entity.AutoGradePending, []entity.ScanStatus{"", entity.ScanClean}).Order("submission_date, project_sub_id").Limit(limit).Find(&projectSubs).Error; err != nil {
+		return nil, err
+	}
+
+	return projectSubs, nil
+}
+
+// mark the submission as running, false when another worker has taken it
+func (r *AutogradeRepoImpl) ClaimSub(projectSubID uint, now time.Time) (bool, error) {
+	result := r.db.Model(&entity.ProjectSub{}).Where("project_sub_id = ? AND auto_status = ?", projectSubID, entity.AutoGradePending).
+		Updates(map[string]interface{}{"auto_status": entity.AutoGradeRunning, "auto_started_at": now})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *AutogradeRepoImpl) GetProject(projectID uint) (*entity.Project, error) {
+	var project entity.Project
+
+	if err := r.db.Where("project_id = ?", projectID).First(&project).Error; err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+// replace test results & store the auto score, score only follows it while mentor hasn't overridden it
+func (r *AutogradeRepoImpl) SaveResult(projectSub *entity.ProjectSub, results []entity.TestResult) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_sub_id = ?", projectSub.ProjectSubID).Delete(&entity.TestResult{}).Error; err != nil {
+			return err
+		}
+
+		if len(results) > 0 {
+			if err := tx.Create(&results).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&entity.ProjectSub{}).Where("project_sub_id = ?", projectSub.ProjectSubID).Updates(map[string]interface{}{
+			"auto_status":    entity.AutoGradeDone,
+			"auto_score":     projectSub.AutoScore,
+			"auto_feedback":  projectSub.AutoFeedback,
+			"auto_graded_at": projectSub.AutoGradedAt,
+			"score":          gorm.Expr("CASE WHEN auto_overridden THEN score ELSE ? END", *projectSub.AutoScore),
+		}).Error
+	})
+}
+
+// run couldn't finish, the previous auto score is kept
+func (r *AutogradeRepoImpl) SaveError(projectSub *entity.ProjectSub) error {
+	return r.db.Model(&entity.ProjectSub{}).Where("project_sub_id = ?", projectSub.ProjectSubID).Updates(map[string]interface{}{
+		"auto_status":    entity.AutoGradeError,
+		"auto_feedback":  projectSub.AutoFeedback,
+		"auto_graded_at": projectSub.AutoGradedAt,
+	}).Error
+}
+
+// queue one submission, or every latest attempt of the project when projectSubID is 0
+func (r *AutogradeRepoImpl) QueueSubs(projectID uint, projectSubID uint) (int64, error) {
+	query := r.db.Model(&entity.ProjectSub{}).Where("project_id = ? AND (auto_status IS NULL OR auto_status <> ?)", projectID, entity.AutoGradeRunning).
+		Where("scan_status IS NULL OR scan_status <> ?", entity.ScanInfected)
+	if projectSubID != 0 {
+		query = query.Where("project_sub_id = ?", projectSubID)
+	} else {
+		query = query.Where("is_latest = ?", true)
+	}
+
+	result := query.Update("auto_status", entity.AutoGradePending)
+	return result.RowsAffected, result.Error
+}
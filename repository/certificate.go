@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CertificateRepo interface {
+	CreateCertificate(certificate *entity.Certificate) error
+	GetCertificateByEnroll(enrollID uint) (*entity.Certificate, error)
+	GetCertificateBySerial(serial string) (*entity.Certificate, error)
+	UpdateCertificate(certificate *entity.Certificate) error
+}
+
+type CertificateRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewCertificateRepo(db *gorm.DB) CertificateRepo {
+	return &CertificateRepoImpl{
+		db: db,
+	}
+}
+
+// one certificate per enrollment, concurrent issue keeps the first one
+func (r *CertificateRepoImpl) CreateCertificate(certificate *entity.Certificate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "enrollment_id"}},
+		DoNothing: true,
+	}).Create(certificate).Error
+}
+
+func (r *CertificateRepoImpl) GetCertificateByEnroll(enrollID uint) (*entity.Certificate, error) {
+	var certificate entity.Certificate
+
+	if err := r.db.Where("enrollment_id = ?", enrollID).First(&certificate).Error; err != nil {
+		return nil, err
+	}
+
+	return &certificate, nil
+}
+
+func (r *CertificateRepoImpl) GetCertificateBySerial(serial string) (*entity.Certificate, error) {
+	var certificate entity.Certificate
+
+	if err := r.db.Where("serial = ?", serial).First(&certificate).Error; err != nil {
+		return nil, err
+	}
+
+	return &certificate, nil
+}
+
+func (r *CertificateRepoImpl) UpdateCertificate(certificate *entity.Certificate) error {
+	return r.db.Save(certificate).Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/nadyafa/go-learn/entity"
 	"gorm.io/gorm"
 )
@@ -11,6 +13,8 @@ type ClassRepo interface {
 	GetClassByID(courseID, classID string) (*entity.Class, error)
 	UpdateClassByID(courseID, classID string, class entity.Class) (*entity.Class, error)
 	DeleteClassByID(courseID, classID string) error
+	GetClassesToClose(before time.Time) ([]entity.Class, error)
+	CloseClassAttendance(classID uint) error
 }
 
 type ClassRepoImpl struct {
@@ -52,7 +56,8 @@ func (r *ClassRepoImpl) GetClassByID(courseID, classID string) (*entity.Class, e
 }
 
 func (r *ClassRepoImpl) UpdateClassByID(courseID, classID string, class entity.Class) (*entity.Class, error) {
-	if err := r.db.Where("course_id = ? AND class_id = ?", courseID, classID).Updates(class).Error; err != nil {
+	// select all columns so room & meeting link can be cleared
+	if err := r.db.Model(&entity.Class{}).Where("course_id = ? AND class_id = ?", courseID, classID).Select("*").Omit("class_id", "created_at").Updates(class).Error; err != nil {
 		return nil, err
 	}
 
@@ -68,3 +73,22 @@ func (r *ClassRepoImpl) DeleteClassByID(courseID, classID string) error {
 
 	return nil
 }
+
+// ended classes that haven't had absent students marked yet
+func (r *ClassRepoImpl) GetClassesToClose(before time.Time) ([]entity.Class, error) {
+	var classes []entity.Class
+
+	if err := r.db.Where("attendance_closed = ? AND end_date < ?", false, before).Find(&classes).Error; err != nil {
+		return nil, err
+	}
+
+	return classes, nil
+}
+
+func (r *ClassRepoImpl) CloseClassAttendance(classID uint) error {
+	if err := r.db.Model(&entity.Class{}).Where("class_id = ?", classID).Update("attendance_closed", true).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CompletionRepo interface {
+	GetCompletionRule(courseID string) (*entity.CompletionRule, error)
+	SaveCompletionRule(rule *entity.CompletionRule) error
+	GetRulesToEvaluate(now time.Time) ([]entity.CompletionRule, error)
+	GetCompletionStats(courseID string, now time.Time) ([]model.CompletionStats, error)
+}
+
+type CompletionRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewCompletionRepo(db *gorm.DB) CompletionRepo {
+	return &CompletionRepoImpl{
+		db: db,
+	}
+}
+
+func (r *CompletionRepoImpl) GetCompletionRule(courseID string) (*entity.CompletionRule, error) {
+	var rule entity.CompletionRule
+
+	if err := r.db.Where("course_id = ?", courseID).First(&rule).Error; err != nil {
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// create or replace the course rule
+func (r *CompletionRepoImpl) SaveCompletionRule(rule *entity.CompletionRule) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "course_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"min_attendance_rate", "min_average_score", "require_all_projects", "evaluated_at", "updated_at"}),
+	}).Create(rule).Error
+}
+
+// rules of ended courses that haven't been evaluated, a course waits until the mentor has graded every latest attempt
+func (r *CompletionRepoImpl) GetRulesToEvaluate(now time.Time) ([]entity.CompletionRule, error) {
+	var rules []entity.CompletionRule
+
+	ungraded := r.db.Model(&entity.ProjectSub{}).Select("1").
+		Joins("JOIN projects ON projects.project_id = project_subs.project_id").
+		Where("projects.course_id = courses.course_id AND project_subs.is_latest AND project_subs.graded_at IS NULL")
+
+	if err := r.db.Joins("JOIN courses ON courses.course_id = completion_rules.course_id").
+		Where("completion_rules.evaluated_at IS NULL AND courses.end_date < ?", now).
+		Where("NOT EXISTS (?)", ungraded).
+		Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// latest attempt per project counts with late penalty applied, team submission counts for every member with their adjustment,
+// average only covers projects the mentor has graded
+const completionStatsQuery = `
+, course_projects AS (
+	SELECT project_id, optional FROM projects WHERE course_id = @course_id
+), latest AS (
+	SELECT ps.project_id, COALESCE(tm.student_id, ps.student_id) AS student_id,
+		LEAST(GREATEST(ps.score * (100 - ps.late_penalty) / 100 + COALESCE(tm.adjustment, 0), 0), 100) AS score,
+		ps.graded_at IS NOT NULL AS graded
+	FROM project_subs ps
+	LEFT JOIN team_members tm ON tm.team_id = ps.team_id
+	WHERE ps.project_id IN (SELECT project_id FROM course_projects) AND ps.is_latest
+)
+SELECT e.enrollment_id, e.student_id, u.username, u.email,
+	COALESCE(MAX(rp.attendance_rate), 100)::float8 AS attendance_rate,
+	COALESCE(ROUND(AVG(l.score) FILTER (WHERE l.graded), 2), 0)::float8 AS average_score,
+	COUNT(l.project_id) FILTER (WHERE NOT cp.optional)::int AS submitted_projects,
+	(SELECT COUNT(*) FROM course_projects WHERE NOT optional)::int AS required_projects
+FROM enrollments e
+JOIN users u ON u.user_id = e.student_id
+LEFT JOIN report rp ON rp.student_id = e.student_id
+LEFT JOIN latest l ON l.student_id = e.student_id
+LEFT JOIN course_projects cp ON cp.project_id = l.project_id
+WHERE e.course_id = @course_id AND e.enroll_status = @enroll
+GROUP BY e.enrollment_id, e.student_id, u.username, u.email
+ORDER BY e.student_id`
+
+// completion figures of every enrolled student, attendance rate reuses the attendance report
+func (r *CompletionRepoImpl) GetCompletionStats(courseID string, now time.Time) ([]model.CompletionStats, error) {
+	var stats []model.CompletionStats
+
+	args := append(reportArgs(courseID), sql.Named("now", now))
+	if err := r.db.Raw(studentAttendReportQuery+completionStatsQuery, args...).Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
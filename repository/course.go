@@ -3,6 +3,7 @@ package repository
 import (
 	"github.com/nadyafa/go-learn/entity"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CourseRepo interface {
@@ -52,7 +53,8 @@ func (r *CourseRepoImpl) GetCourseByID(courseID string) (*entity.Course, error)
 }
 
 func (r *CourseRepoImpl) UpdateCourseByID(courseID string, course *entity.Course) error {
-	if err := r.db.Where("course_id = ?", courseID).Updates(&course).Error; err != nil {
+	// write every column so zero thresholds & deadlines are stored too
+	if err := r.db.Model(course).Where("course_id = ?", courseID).Select("*").Omit(clause.Associations, "CreatedAt").Updates(course).Error; err != nil {
 		return err
 	}
 
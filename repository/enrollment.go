@@ -1,14 +1,27 @@
 package repository
 
 import (
+	"errors"
+	"time"
+
 	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/model"
 	"gorm.io/gorm"
 )
 
 type EnrollRepo interface {
-	StudentEnroll(enroll entity.Enrollment) (*entity.Enrollment, error)
+	StudentEnroll(enroll entity.Enrollment, history entity.EnrollmentHistory) (*entity.Enrollment, error)
 	GetStudentCourseEnroll(courseID, userID string) (*entity.Enrollment, error)
-	UpdateStudentEnroll(courseID, userID string, updateEnroll entity.Enrollment) (*entity.Enrollment, error)
+	CountCourseEnrolls(courseID string, enrollStatus entity.Status) (int64, error)
+	GetCourseEnrolls(courseID string, enrollStatus entity.Status) ([]entity.Enrollment, error)
+	GetEnrollByID(courseID, enrollID string) (*entity.Enrollment, error)
+	UpdateEnrollStatus(enroll *entity.Enrollment, history entity.EnrollmentHistory) error
+	GetEnrollHistories(enrollID uint) ([]entity.EnrollmentHistory, error)
+	SearchEnrolls(filter model.EnrollFilter) ([]model.EnrollDetail, int64, error)
+	UpdateEnroll(enroll *entity.Enrollment) error
+	CancelEnroll(enroll *entity.Enrollment, history entity.EnrollmentHistory, now time.Time) ([]string, error)
+	ImportEnrolls(imports []model.EnrollImport, history entity.EnrollmentHistory) error
+	GetCourseStudentIDs(courseID uint) ([]uint, error)
 }
 
 type EnrollRepoImpl struct {
@@ -21,8 +34,17 @@ func NewEnrollRepo(db *gorm.DB) EnrollRepo {
 	}
 }
 
-func (r *EnrollRepoImpl) StudentEnroll(enroll entity.Enrollment) (*entity.Enrollment, error) {
-	if err := r.db.Create(&enroll).Error; err != nil {
+// create enrollment together with its first history
+func (r *EnrollRepoImpl) StudentEnroll(enroll entity.Enrollment, history entity.EnrollmentHistory) (*entity.Enrollment, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&enroll).Error; err != nil {
+			return err
+		}
+
+		history.EnrollmentID = enroll.EnrollmentID
+		return tx.Create(&history).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -32,17 +54,234 @@ func (r *EnrollRepoImpl) StudentEnroll(enroll entity.Enrollment) (*entity.Enroll
 func (r *EnrollRepoImpl) GetStudentCourseEnroll(courseID, studentID string) (*entity.Enrollment, error) {
 	var studentEnroll entity.Enrollment
 
-	if err := r.db.First(&studentEnroll, studentID, courseID).Error; err != nil {
+	if err := r.db.Where("course_id = ? AND student_id = ?", courseID, studentID).First(&studentEnroll).Error; err != nil {
 		return nil, err
 	}
 
 	return &studentEnroll, nil
 }
 
-func (r *EnrollRepoImpl) UpdateStudentEnroll(courseID, studentID string, updateEnroll entity.Enrollment) (*entity.Enrollment, error) {
-	if err := r.db.Where("student_id = ? AND course_id = ?", studentID, courseID).Updates(updateEnroll).Error; err != nil {
+func (r *EnrollRepoImpl) CountCourseEnrolls(courseID string, enrollStatus entity.Status) (int64, error) {
+	var count int64
+
+	if err := r.db.Model(&entity.Enrollment{}).Where("course_id = ? AND enroll_status = ?", courseID, enrollStatus).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *EnrollRepoImpl) GetCourseEnrolls(courseID string, enrollStatus entity.Status) ([]entity.Enrollment, error) {
+	var enrolls []entity.Enrollment
+
+	if err := r.db.Where("course_id = ? AND enroll_status = ?", courseID, enrollStatus).Order("created_at").Find(&enrolls).Error; err != nil {
+		return nil, err
+	}
+
+	return enrolls, nil
+}
+
+func (r *EnrollRepoImpl) GetEnrollByID(courseID, enrollID string) (*entity.Enrollment, error) {
+	var enroll entity.Enrollment
+
+	if err := r.db.Where("course_id = ? AND enrollment_id = ?", courseID, enrollID).First(&enroll).Error; err != nil {
+		return nil, err
+	}
+
+	return &enroll, nil
+}
+
+// save enrollment status & record the transition in one transaction
+func (r *EnrollRepoImpl) UpdateEnrollStatus(enroll *entity.Enrollment, history entity.EnrollmentHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(enroll).Error; err != nil {
+			return err
+		}
+
+		history.EnrollmentID = enroll.EnrollmentID
+		return tx.Create(&history).Error
+	})
+}
+
+func (r *EnrollRepoImpl) GetEnrollHistories(enrollID uint) ([]entity.EnrollmentHistory, error) {
+	var histories []entity.EnrollmentHistory
+
+	if err := r.db.Where("enrollment_id = ?", enrollID).Order("created_at, history_id").Find(&histories).Error; err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+// list enrollments with user & course details, returns the page and total matching rows
+func (r *EnrollRepoImpl) SearchEnrolls(filter model.EnrollFilter) ([]model.EnrollDetail, int64, error) {
+	var enrolls []model.EnrollDetail
+	var total int64
+
+	query := r.db.Table("enrollments e").
+		Joins("JOIN users u ON u.user_id = e.student_id").
+		Joins("JOIN courses c ON c.course_id = e.course_id")
+
+	if filter.CourseID != 0 {
+		query = query.Where("e.course_id = ?", filter.CourseID)
+	}
+
+	if filter.StudentID != 0 {
+		query = query.Where("e.student_id = ?", filter.StudentID)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("e.enroll_status = ?", filter.Status)
+	}
+
+	if filter.Search != "" {
+		search := "%" + filter.Search + "%"
+		query = query.Where("u.username ILIKE ? OR u.email ILIKE ?", search, search)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Select("e.enrollment_id, e.student_id, u.username, u.email, e.course_id, c.course_name, e.enrollment_date, e.enroll_status, e.created_at").
+		Order("e.enrollment_id")
+
+	if filter.PerPage > 0 {
+		query = query.Limit(filter.PerPage).Offset((filter.Page - 1) * filter.PerPage)
+	}
+
+	if err := query.Scan(&enrolls).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return enrolls, total, nil
+}
+
+func (r *EnrollRepoImpl) UpdateEnroll(enroll *entity.Enrollment) error {
+	return r.db.Save(enroll).Error
+}
+
+// cancel enrollment & remove the student's future attendances, pending excuses, pending peer reviews and ungraded latest submissions,
+// returns storage keys of the removed excuse documents & submission files
+func (r *EnrollRepoImpl) CancelEnroll(enroll *entity.Enrollment, history entity.EnrollmentHistory, now time.Time) ([]string, error) {
+	var fileKeys []string
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(enroll).Error; err != nil {
+			return err
+		}
+
+		history.EnrollmentID = enroll.EnrollmentID
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+
+		futureClasses := tx.Model(&entity.Class{}).Select("class_id").Where("course_id = ? AND start_date > ?", enroll.CourseID, now)
+		if err := tx.Where("student_id = ? AND class_id IN (?)", enroll.StudentID, futureClasses).Delete(&entity.Attendance{}).Error; err != nil {
+			return err
+		}
+
+		var excuses []entity.Excuse
+		if err := tx.Where("student_id = ? AND course_id = ? AND status = ?", enroll.StudentID, enroll.CourseID, entity.ExcusePending).Find(&excuses).Error; err != nil {
+			return err
+		}
+
+		for _, excuse := range excuses {
+			if excuse.DocumentKey != "" {
+				fileKeys = append(fileKeys, excuse.DocumentKey)
+			}
+		}
+
+		if len(excuses) > 0 {
+			if err := tx.Delete(&excuses).Error; err != nil {
+				return err
+			}
+		}
+
+		courseProjects := tx.Model(&entity.Project{}).Select("project_id").Where("course_id = ?", enroll.CourseID)
+
+		// student leaves their project teams, team submissions stay with the team
+		if err := tx.Where("student_id = ? AND project_id IN (?)", enroll.StudentID, courseProjects).Delete(&entity.TeamMember{}).Error; err != nil {
+			return err
+		}
+
+		// pending peer reviews assigned to the student are dropped
+		if err := tx.Where("reviewer_id = ? AND submitted_at IS NULL AND project_id IN (?)", enroll.StudentID, courseProjects).Delete(&entity.PeerReview{}).Error; err != nil {
+			return err
+		}
+
+		// only the latest attempt the mentor hasn't graded yet goes, earlier attempts & their files are kept
+		var projectSubs []entity.ProjectSub
+		if err := tx.Where("student_id = ? AND team_id IS NULL AND is_latest = ? AND graded_at IS NULL AND project_id IN (?)", enroll.StudentID, true, courseProjects).Find(&projectSubs).Error; err != nil {
+			return err
+		}
+
+		for i := range projectSubs {
+			if err := tx.Delete(&projectSubs[i]).Error; err != nil {
+				return err
+			}
+
+			if projectSubs[i].FileKey != "" {
+				fileKeys = append(fileKeys, projectSubs[i].FileKey)
+			}
+
+			// previous attempt becomes the latest one again
+			var previous entity.ProjectSub
+			err := sameOwner(tx, &projectSubs[i]).Order("attempt DESC").First(&previous).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Model(&previous).Update("is_latest", true).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fileKeys, nil
+}
+
+// create missing users & their enrollments, nothing is saved when one row fails
+func (r *EnrollRepoImpl) ImportEnrolls(imports []model.EnrollImport, history entity.EnrollmentHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range imports {
+			if imports[i].User.UserID == 0 {
+				if err := tx.Create(imports[i].User).Error; err != nil {
+					return err
+				}
+			}
+
+			enroll := &imports[i].Enrollment
+			enroll.StudentID = imports[i].User.UserID
+			if err := tx.Create(enroll).Error; err != nil {
+				return err
+			}
+
+			enrollHistory := history
+			enrollHistory.EnrollmentID = enroll.EnrollmentID
+			if err := tx.Create(&enrollHistory).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *EnrollRepoImpl) GetCourseStudentIDs(courseID uint) ([]uint, error) {
+	var studentIDs []uint
+
+	if err := r.db.Model(&entity.Enrollment{}).Where("course_id = ?", courseID).Pluck("student_id", &studentIDs).Error; err != nil {
 		return nil, err
 	}
 
-	return &updateEnroll, nil
+	return studentIDs, nil
 }
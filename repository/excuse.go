@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExcuseRepo interface {
+	CreateExcuse(excuse *entity.Excuse) error
+	GetClassExcuses(classID, studentID string) ([]entity.Excuse, error)
+	GetExcuseByID(classID, excuseID string) (*entity.Excuse, error)
+	GetActiveStudentExcuse(classID, studentID string) (*entity.Excuse, error)
+	ReviewExcuse(excuse *entity.Excuse, attend *entity.Attendance) error
+}
+
+type ExcuseRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewExcuseRepo(db *gorm.DB) ExcuseRepo {
+	return &ExcuseRepoImpl{
+		db: db,
+	}
+}
+
+func (r *ExcuseRepoImpl) CreateExcuse(excuse *entity.Excuse) error {
+	if err := r.db.Create(excuse).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// all excuses of a class, or only student's own when studentID given
+func (r *ExcuseRepoImpl) GetClassExcuses(classID, studentID string) ([]entity.Excuse, error) {
+	var excuses []entity.Excuse
+
+	query := r.db.Where("class_id = ?", classID)
+	if studentID != "" {
+		query = query.Where("student_id = ?", studentID)
+	}
+
+	if err := query.Order("created_at DESC").Find(&excuses).Error; err != nil {
+		return nil, err
+	}
+
+	return excuses, nil
+}
+
+func (r *ExcuseRepoImpl) GetExcuseByID(classID, excuseID string) (*entity.Excuse, error) {
+	var excuse entity.Excuse
+
+	if err := r.db.Where("class_id = ? AND excuse_id = ?", classID, excuseID).First(&excuse).Error; err != nil {
+		return nil, err
+	}
+
+	return &excuse, nil
+}
+
+// pending or approved excuse, a rejected excuse can be submitted again
+func (r *ExcuseRepoImpl) GetActiveStudentExcuse(classID, studentID string) (*entity.Excuse, error) {
+	var excuse entity.Excuse
+
+	if err := r.db.Where("class_id = ? AND student_id = ? AND status IN ?", classID, studentID, []entity.ExcuseStatus{entity.ExcusePending, entity.ExcuseApproved}).First(&excuse).Error; err != nil {
+		return nil, err
+	}
+
+	return &excuse, nil
+}
+
+// save review & set attendance to excused in one transaction
+func (r *ExcuseRepoImpl) ReviewExcuse(excuse *entity.Excuse, attend *entity.Attendance) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(excuse).Error; err != nil {
+			return err
+		}
+
+		if attend == nil {
+			return nil
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "student_id"}, {Name: "class_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"status", "attended"}),
+		}).Create(attend).Error
+	})
+}
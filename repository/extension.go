@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExtensionRepo interface {
+	SaveExtension(extension *entity.DeadlineExtension) error
+	GetExtensions(projectID, studentID string) ([]entity.DeadlineExtension, error)
+	GetExtensionByID(projectID, extensionID string) (*entity.DeadlineExtension, error)
+	GetStudentExtension(projectID, studentID uint) (*entity.DeadlineExtension, error)
+	DeleteExtension(extension *entity.DeadlineExtension) error
+}
+
+type ExtensionRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewExtensionRepo(db *gorm.DB) ExtensionRepo {
+	return &ExtensionRepoImpl{
+		db: db,
+	}
+}
+
+// a student has at most one extension per project, granting again replaces it
+func (r *ExtensionRepoImpl) SaveExtension(extension *entity.DeadlineExtension) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"deadline", "reason", "granted_by", "updated_at"}),
+	}).Create(extension).Error
+}
+
+// extensions of a project, or only student's own when studentID given
+func (r *ExtensionRepoImpl) GetExtensions(projectID, studentID string) ([]entity.DeadlineExtension, error) {
+	var extensions []entity.DeadlineExtension
+
+	query := r.db.Where("project_id = ?", projectID)
+	if studentID != "" {
+		query = query.Where("student_id = ?", studentID)
+	}
+
+	if err := query.Order("student_id").Find(&extensions).Error; err != nil {
+		return nil, err
+	}
+
+	return extensions, nil
+}
+
+func (r *ExtensionRepoImpl) GetExtensionByID(projectID, extensionID string) (*entity.DeadlineExtension, error) {
+	var extension entity.DeadlineExtension
+
+	if err := r.db.Where("project_id = ? AND extension_id = ?", projectID, extensionID).First(&extension).Error; err != nil {
+		return nil, err
+	}
+
+	return &extension, nil
+}
+
+func (r *ExtensionRepoImpl) GetStudentExtension(projectID, studentID uint) (*entity.DeadlineExtension, error) {
+	var extension entity.DeadlineExtension
+
+	if err := r.db.Where("project_id = ? AND student_id = ?", projectID, studentID).First(&extension).Error; err != nil {
+		return nil, err
+	}
+
+	return &extension, nil
+}
+
+func (r *ExtensionRepoImpl) DeleteExtension(extension *entity.DeadlineExtension) error {
+	return r.db.Delete(extension).Error
+}
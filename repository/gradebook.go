@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GradebookRepo interface {
+	CreateCategory(category *entity.GradeCategory) error
+	GetCategories(courseID string) ([]entity.GradeCategory, error)
+	GetCategoryByID(courseID, categoryID string) (*entity.GradeCategory, error)
+	UpdateCategory(category *entity.GradeCategory) error
+	DeleteCategory(category *entity.GradeCategory) error
+	GetGradeScale(courseID string) ([]entity.GradeLetter, error)
+	SaveGradeScale(courseID uint, letters []entity.GradeLetter) error
+	SaveExcusal(excusal *entity.GradeExcusal) error
+	DeleteExcusal(projectID, studentID string) error
+	GetCourseExcusals(courseID string) ([]entity.GradeExcusal, error)
+	GetCourseExtensions(courseID string) ([]entity.DeadlineExtension, error)
+	GetLatestProjectSubs(courseID string) ([]entity.ProjectSub, error)
+	GetCourseTeamMembers(courseID string) ([]entity.TeamMember, error)
+}
+
+type GradebookRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewGradebookRepo(db *gorm.DB) GradebookRepo {
+	return &GradebookRepoImpl{
+		db: db,
+	}
+}
+
+func courseProjectIDs(db *gorm.DB, courseID string) *gorm.DB {
+	return db.Model(&entity.Project{}).Select("project_id").Where("course_id = ?", courseID)
+}
+
+func (r *GradebookRepoImpl) CreateCategory(category *entity.GradeCategory) error {
+	if err := r.db.Create(category).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *GradebookRepoImpl) GetCategories(courseID string) ([]entity.GradeCategory, error) {
+	var categories []entity.GradeCategory
+
+	if err := r.db.Where("course_id = ?", courseID).Order("category_id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+func (r *GradebookRepoImpl) GetCategoryByID(courseID, categoryID string) (*entity.GradeCategory, error) {
+	var category entity.GradeCategory
+
+	if err := r.db.Where("course_id = ? AND category_id = ?", courseID, categoryID).First(&category).Error; err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (r *GradebookRepoImpl) UpdateCategory(category *entity.GradeCategory) error {
+	return r.db.Save(category).Error
+}
+
+// projects of the category become uncategorized
+func (r *GradebookRepoImpl) DeleteCategory(category *entity.GradeCategory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.Project{}).Where("category_id = ?", category.CategoryID).Update("category_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(category).Error
+	})
+}
+
+func (r *GradebookRepoImpl) GetGradeScale(courseID string) ([]entity.GradeLetter, error) {
+	var letters []entity.GradeLetter
+
+	if err := r.db.Where("course_id = ?", courseID).Order("min_score DESC").Find(&letters).Error; err != nil {
+		return nil, err
+	}
+
+	return letters, nil
+}
+
+// whole scale is replaced, empty letters resets to the default scale
+func (r *GradebookRepoImpl) SaveGradeScale(courseID uint, letters []entity.GradeLetter) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("course_id = ?", courseID).Delete(&entity.GradeLetter{}).Error; err != nil {
+			return err
+		}
+
+		if len(letters) == 0 {
+			return nil
+		}
+
+		return tx.Create(&letters).Error
+	})
+}
+
+func (r *GradebookRepoImpl) SaveExcusal(excusal *entity.GradeExcusal) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "student_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "excused_by"}),
+	}).Create(excusal).Error
+}
+
+func (r *GradebookRepoImpl) DeleteExcusal(projectID, studentID string) error {
+	result := r.db.Where("project_id = ? AND student_id = ?", projectID, studentID).Delete(&entity.GradeExcusal{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (r *GradebookRepoImpl) GetCourseExcusals(courseID string) ([]entity.GradeExcusal, error) {
+	var excusals []entity.GradeExcusal
+
+	if err := r.db.Where("project_id IN (?)", courseProjectIDs(r.db, courseID)).Find(&excusals).Error; err != nil {
+		return nil, err
+	}
+
+	return excusals, nil
+}
+
+func (r *GradebookRepoImpl) GetCourseExtensions(courseID string) ([]entity.DeadlineExtension, error) {
+	var extensions []entity.DeadlineExtension
+
+	if err := r.db.Where("project_id IN (?)", courseProjectIDs(r.db, courseID)).Find(&extensions).Error; err != nil {
+		return nil, err
+	}
+
+	return extensions, nil
+}
+
+// graded attempt of every student for every course project
+func (r *GradebookRepoImpl) GetLatestProjectSubs(courseID string) ([]entity.ProjectSub, error) {
+	var projectSubs []entity.ProjectSub
+
+	if err := r.db.Where("project_id IN (?) AND is_latest = ?", courseProjectIDs(r.db, courseID), true).Find(&projectSubs).Error; err != nil {
+		return nil, err
+	}
+
+	return projectSubs, nil
+}
+
+func (r *GradebookRepoImpl) GetCourseTeamMembers(courseID string) ([]entity.TeamMember, error) {
+	var members []entity.TeamMember
+
+	if err := r.db.Where("project_id IN (?)", courseProjectIDs(r.db, courseID)).Find(&members).Error; err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
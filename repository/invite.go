@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// invite got revoked, expired or used up while redeeming
+var ErrInviteUnavailable = errors.New("invite code is no longer available")
+
+type InviteRepo interface {
+	CreateInvite(invite *entity.CourseInvite) error
+	GetCourseInvites(courseID string) ([]entity.CourseInvite, error)
+	GetInviteByID(courseID, inviteID string) (*entity.CourseInvite, error)
+	GetInviteByCode(code string) (*entity.CourseInvite, error)
+	UpdateInvite(invite *entity.CourseInvite) error
+	RedeemInvite(inviteID uint, enroll entity.Enrollment, history entity.EnrollmentHistory, now time.Time) (*entity.Enrollment, error)
+}
+
+type InviteRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewInviteRepo(db *gorm.DB) InviteRepo {
+	return &InviteRepoImpl{
+		db: db,
+	}
+}
+
+func (r *InviteRepoImpl) CreateInvite(invite *entity.CourseInvite) error {
+	if err := r.db.Create(invite).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *InviteRepoImpl) GetCourseInvites(courseID string) ([]entity.CourseInvite, error) {
+	var invites []entity.CourseInvite
+
+	if err := r.db.Where("course_id = ?", courseID).Order("created_at DESC").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+func (r *InviteRepoImpl) GetInviteByID(courseID, inviteID string) (*entity.CourseInvite, error) {
+	var invite entity.CourseInvite
+
+	if err := r.db.Preload("Redemptions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).Where("course_id = ? AND invite_id = ?", courseID, inviteID).First(&invite).Error; err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+func (r *InviteRepoImpl) GetInviteByCode(code string) (*entity.CourseInvite, error) {
+	var invite entity.CourseInvite
+
+	if err := r.db.Where("code = ?", code).First(&invite).Error; err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
+
+func (r *InviteRepoImpl) UpdateInvite(invite *entity.CourseInvite) error {
+	return r.db.Omit(clause.Associations).Save(invite).Error
+}
+
+// take one use of the invite & enroll the student in one transaction,
+// the use is only taken while invite is still usable so concurrent redeems can't exceed max uses
+func (r *InviteRepoImpl) RedeemInvite(inviteID uint, enroll entity.Enrollment, history entity.EnrollmentHistory, now time.Time) (*entity.Enrollment, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.CourseInvite{}).
+			Where("invite_id = ? AND revoked_at IS NULL", inviteID).
+			Where("expires_at IS NULL OR expires_at > ?", now).
+			Where("max_uses = 0 OR used_count < max_uses").
+			Update("used_count", gorm.Expr("used_count + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return ErrInviteUnavailable
+		}
+
+		if err := tx.Create(&enroll).Error; err != nil {
+			return err
+		}
+
+		history.EnrollmentID = enroll.EnrollmentID
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&entity.InviteRedemption{
+			InviteID:     inviteID,
+			StudentID:    enroll.StudentID,
+			EnrollmentID: enroll.EnrollmentID,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &enroll, nil
+}
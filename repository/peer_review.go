@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PeerReviewRepo interface {
+	GetProjectsToAssign(now time.Time) ([]entity.Project, error)
+	CreateReviews(project *entity.Project, reviews []entity.PeerReview, now time.Time) error
+	GetReviews(projectID string, reviewerID uint) ([]entity.PeerReview, error)
+	GetReviewByID(projectID, reviewID string) (*entity.PeerReview, error)
+	GetSubReviews(projectSubID uint) ([]entity.PeerReview, error)
+	SubmitReview(review *entity.PeerReview, grades []entity.PeerCriterionGrade) error
+	UpdateReview(review *entity.PeerReview) error
+	GetPeerAverage(projectSubID uint) (*float64, error)
+}
+
+type PeerReviewRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewPeerReviewRepo(db *gorm.DB) PeerReviewRepo {
+	return &PeerReviewRepoImpl{
+		db: db,
+	}
+}
+
+// peer review projects past their deadline that haven't been assigned yet
+func (r *PeerReviewRepoImpl) GetProjectsToAssign(now time.Time) ([]entity.Project, error) {
+	var projects []entity.Project
+
+	if err := r.db.Where("peer_review_enabled = ? AND peer_assigned_at IS NULL AND deadline < ?", true, now).Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// new reviews are created & the project is marked as assigned in one transaction
+func (r *PeerReviewRepoImpl) CreateReviews(project *entity.Project, reviews []entity.PeerReview, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(reviews) > 0 {
+			if err := tx.Create(&reviews).Error; err != nil {
+				return err
+			}
+		}
+
+		if project.PeerAssignedAt != nil {
+			return nil
+		}
+
+		if err := tx.Model(&entity.Project{}).Where("project_id = ?", project.ProjectID).Update("peer_assigned_at", now).Error; err != nil {
+			return err
+		}
+
+		project.PeerAssignedAt = &now
+		return nil
+	})
+}
+
+// reviews of a project, or only the ones assigned to reviewerID when given
+func (r *PeerReviewRepoImpl) GetReviews(projectID string, reviewerID uint) ([]entity.PeerReview, error) {
+	var reviews []entity.PeerReview
+
+	query := r.db.Preload("Grades").Where("project_id = ?", projectID)
+	if reviewerID != 0 {
+		query = query.Where("reviewer_id = ?", reviewerID)
+	}
+
+	if err := query.Order("project_sub_id, review_id").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (r *PeerReviewRepoImpl) GetReviewByID(projectID, reviewID string) (*entity.PeerReview, error) {
+	var review entity.PeerReview
+
+	if err := r.db.Preload("Grades").Where("project_id = ? AND review_id = ?", projectID, reviewID).First(&review).Error; err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (r *PeerReviewRepoImpl) GetSubReviews(projectSubID uint) ([]entity.PeerReview, error) {
+	var reviews []entity.PeerReview
+
+	if err := r.db.Preload("Grades").Where("project_sub_id = ?", projectSubID).Order("review_id").Find(&reviews).Error; err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// save review & replace its criterion grades in one transaction
+func (r *PeerReviewRepoImpl) SubmitReview(review *entity.PeerReview, grades []entity.PeerCriterionGrade) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(review).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("review_id = ?", review.ReviewID).Delete(&entity.PeerCriterionGrade{}).Error; err != nil {
+			return err
+		}
+
+		for i := range grades {
+			grades[i].ReviewID = review.ReviewID
+		}
+
+		if len(grades) > 0 {
+			if err := tx.Create(&grades).Error; err != nil {
+				return err
+			}
+		}
+
+		review.Grades = grades
+		return nil
+	})
+}
+
+func (r *PeerReviewRepoImpl) UpdateReview(review *entity.PeerReview) error {
+	return r.db.Omit(clause.Associations).Save(review).Error
+}
+
+// average score of the submitted reviews, nil when no review has been submitted
+func (r *PeerReviewRepoImpl) GetPeerAverage(projectSubID uint) (*float64, error) {
+	var result struct {
+		Average *float64
+	}
+
+	if err := r.db.Model(&entity.PeerReview{}).Select("AVG(score) AS average").
+		Where("project_sub_id = ? AND submitted_at IS NOT NULL", projectSubID).Scan(&result).Error; err != nil {
+		return nil, err
+	}
+
+	return result.Average, nil
+}
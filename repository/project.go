@@ -3,6 +3,7 @@ package repository
 import (
 	"github.com/nadyafa/go-learn/entity"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProjectRepo interface {
@@ -52,7 +53,8 @@ func (r *ProjectRepoImpl) GetProjectByID(courseID, projectID string) (*entity.Pr
 }
 
 func (r *ProjectRepoImpl) UpdateProjectByID(courseID, projectID string, project entity.Project) (*entity.Project, error) {
-	if err := r.db.Where("course_id = ? AND project_id = ?", courseID, projectID).Updates(project).Error; err != nil {
+	// select all columns so optional can be set back to false
+	if err := r.db.Model(&entity.Project{}).Where("course_id = ? AND project_id = ?", courseID, projectID).Select("*").Omit("project_id", "created_at", clause.Associations).Updates(project).Error; err != nil {
 		return nil, err
 	}
 
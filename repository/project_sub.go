@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// student has used every attempt of the project
+var ErrMaxAttempts = errors.New("maximum submission attempts reached")
+
+type ProjectSubRepo interface {
+	CreateProjectSub(projectSub *entity.ProjectSub, maxAttempts int) error
+	GetProjectSubs(projectID, studentID string, latestOnly bool) ([]entity.ProjectSub, error)
+	GetProjectSubByID(projectID, projectSubID string) (*entity.ProjectSub, error)
+	GetProjectSubHistory(projectSub *entity.ProjectSub) ([]entity.ProjectSub, error)
+	UpdateProjectSub(projectSub *entity.ProjectSub) error
+	GradeProjectSub(projectSub *entity.ProjectSub, grades []entity.CriterionGrade) error
+	DeleteProjectSub(projectSub *entity.ProjectSub) error
+	GetTestResults(projectSubID uint) ([]entity.TestResult, error)
+}
+
+type ProjectSubRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewProjectSubRepo(db *gorm.DB) ProjectSubRepo {
+	return &ProjectSubRepoImpl{
+		db: db,
+	}
+}
+
+// attempts of the same team, or of the same student on individual project
+func sameOwner(db *gorm.DB, projectSub *entity.ProjectSub) *gorm.DB {
+	if projectSub.TeamID != nil {
+		return db.Where("project_id = ? AND team_id = ?", projectSub.ProjectID, *projectSub.TeamID)
+	}
+
+	return db.Where("project_id = ? AND student_id = ? AND team_id IS NULL", projectSub.ProjectID, projectSub.StudentID)
+}
+
+// new attempt becomes the latest one, project row is locked so attempts of a student are numbered in order
+func (r *ProjectSubRepoImpl) CreateProjectSub(projectSub *entity.ProjectSub, maxAttempts int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("project_id = ?", projectSub.ProjectID).First(&entity.Project{}).Error; err != nil {
+			return err
+		}
+
+		var attempts struct {
+			Total int
+			Last  int
+		}
+		if err := sameOwner(tx.Model(&entity.ProjectSub{}), projectSub).Select("COUNT(*) AS total, COALESCE(MAX(attempt), 0) AS last").Scan(&attempts).Error; err != nil {
+			return err
+		}
+
+		if maxAttempts > 0 && attempts.Total >= maxAttempts {
+			return ErrMaxAttempts
+		}
+
+		if err := sameOwner(tx.Model(&entity.ProjectSub{}), projectSub).Where("is_latest = ?", true).Update("is_latest", false).Error; err != nil {
+			return err
+		}
+
+		projectSub.Attempt = attempts.Last + 1
+		projectSub.IsLatest = true
+
+		return tx.Create(projectSub).Error
+	})
+}
+
+// submissions of a project, or only student's own & their team's when studentID given
+func (r *ProjectSubRepoImpl) GetProjectSubs(projectID, studentID string, latestOnly bool) ([]entity.ProjectSub, error) {
+	var projectSubs []entity.ProjectSub
+
+	query := r.db.Preload("Grades").Where("project_id = ?", projectID)
+	if studentID != "" {
+		teams := r.db.Model(&entity.TeamMember{}).Select("team_id").Where("project_id = ? AND student_id = ?", projectID, studentID)
+		query = query.Where("(student_id = ? OR team_id IN (?))", studentID, teams)
+	}
+
+	if latestOnly {
+		query = query.Where("is_latest = ?", true)
+	}
+
+	if err := query.Order("submission_date DESC").Find(&projectSubs).Error; err != nil {
+		return nil, err
+	}
+
+	return projectSubs, nil
+}
+
+func (r *ProjectSubRepoImpl) GetProjectSubByID(projectID, projectSubID string) (*entity.ProjectSub, error) {
+	var projectSub entity.ProjectSub
+
+	if err := r.db.Preload("Grades").Where("project_id = ? AND project_sub_id = ?", projectID, projectSubID).First(&projectSub).Error; err != nil {
+		return nil, err
+	}
+
+	return &projectSub, nil
+}
+
+// every attempt of the submission's student or team, newest first
+func (r *ProjectSubRepoImpl) GetProjectSubHistory(projectSub *entity.ProjectSub) ([]entity.ProjectSub, error) {
+	var projectSubs []entity.ProjectSub
+
+	if err := sameOwner(r.db.Preload("Grades"), projectSub).Order("attempt DESC").Find(&projectSubs).Error; err != nil {
+		return nil, err
+	}
+
+	return projectSubs, nil
+}
+
+func (r *ProjectSubRepoImpl) UpdateProjectSub(projectSub *entity.ProjectSub) error {
+	return r.db.Omit(clause.Associations).Save(projectSub).Error
+}
+
+// rubric grading replaces every earlier criterion grade of the submission
+func (r *ProjectSubRepoImpl) GradeProjectSub(projectSub *entity.ProjectSub, grades []entity.CriterionGrade) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_sub_id = ?", projectSub.ProjectSubID).Delete(&entity.CriterionGrade{}).Error; err != nil {
+			return err
+		}
+
+		for i := range grades {
+			grades[i].ProjectSubID = projectSub.ProjectSubID
+		}
+
+		if err := tx.Create(&grades).Error; err != nil {
+			return err
+		}
+
+		projectSub.Grades = grades
+
+		return tx.Omit(clause.Associations).Save(projectSub).Error
+	})
+}
+
+// deleting the latest attempt makes the previous one the graded attempt
+func (r *ProjectSubRepoImpl) DeleteProjectSub(projectSub *entity.ProjectSub) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(projectSub).Error; err != nil {
+			return err
+		}
+
+		if !projectSub.IsLatest {
+			return nil
+		}
+
+		var previous entity.ProjectSub
+		err := sameOwner(tx, projectSub).Order("attempt DESC").First(&previous).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&previous).Update("is_latest", true).Error
+	})
+}
+
+func (r *ProjectSubRepoImpl) GetTestResults(projectSubID uint) ([]entity.TestResult, error) {
+	var results []entity.TestResult
+
+	if err := r.db.Where("project_sub_id = ?", projectSubID).Order("package, test").Find(&results).Error; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
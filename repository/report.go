@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/model"
+	"gorm.io/gorm"
+)
+
+type ReportRepo interface {
+	GetStudentAttendReports(courseID string, now time.Time) ([]model.StudentAttendReport, error)
+	GetAtRiskStudents(courseID string, now time.Time, threshold float64) ([]model.StudentAttendReport, error)
+	GetClassTurnoutReports(courseID string) ([]model.ClassTurnoutReport, error)
+	GetAttendTrendReports(courseID, interval, timezone string) ([]model.AttendTrendReport, error)
+}
+
+type ReportRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewReportRepo(db *gorm.DB) ReportRepo {
+	return &ReportRepoImpl{
+		db: db,
+	}
+}
+
+// attendance of enrolled students over ended classes, unrecorded class counts as absent & excused class is left out of the rate
+const studentAttendReportQuery = `
+WITH ended AS (
+	SELECT class_id FROM classes WHERE course_id = @course_id AND end_date < @now
+), stats AS (
+	SELECT e.student_id,
+		COUNT(a.attend_id) FILTER (WHERE a.status = @present) AS present,
+		COUNT(a.attend_id) FILTER (WHERE a.status = @late) AS late,
+		COUNT(a.attend_id) FILTER (WHERE a.status = @excused) AS excused
+	FROM enrollments e
+	LEFT JOIN attendances a ON a.student_id = e.student_id AND a.class_id IN (SELECT class_id FROM ended)
+	WHERE e.course_id = @course_id AND e.enroll_status = @enroll
+	GROUP BY e.student_id
+), report AS (
+	SELECT s.student_id, u.username, u.email, t.total_classes, s.present, s.late, s.excused,
+		t.total_classes - s.present - s.late - s.excused AS absent,
+		COALESCE(ROUND(100.0 * (s.present + s.late) / NULLIF(t.total_classes - s.excused, 0), 2), 100)::float8 AS attendance_rate
+	FROM stats s
+	JOIN users u ON u.user_id = s.student_id
+	CROSS JOIN (SELECT COUNT(*) AS total_classes FROM ended) t
+)`
+
+// turnout of every class against currently enrolled students
+const classTurnoutReportQuery = `
+WITH enrolled AS (
+	SELECT COUNT(*) AS total FROM enrollments WHERE course_id = @course_id AND enroll_status = @enroll
+), turnout AS (
+	SELECT cl.class_id, cl.class_name, cl.start_date, en.total AS enrolled,
+		COUNT(a.attend_id) FILTER (WHERE a.status = @present) AS present,
+		COUNT(a.attend_id) FILTER (WHERE a.status = @late) AS late,
+		COUNT(a.attend_id) FILTER (WHERE a.status = @excused) AS excused,
+		COUNT(a.attend_id) FILTER (WHERE a.status = @absent) AS absent,
+		COALESCE(ROUND(100.0 * COUNT(a.attend_id) FILTER (WHERE a.status IN (@present, @late)) / NULLIF(en.total, 0), 2), 0)::float8 AS turnout_rate
+	FROM classes cl
+	CROSS JOIN enrolled en
+	LEFT JOIN attendances a ON a.class_id = cl.class_id
+	WHERE cl.course_id = @course_id
+	GROUP BY cl.class_id, cl.class_name, cl.start_date, en.total
+)`
+
+func reportArgs(courseID string) []interface{} {
+	return []interface{}{
+		sql.Named("course_id", courseID),
+		sql.Named("enroll", entity.Enroll),
+		sql.Named("present", entity.Present),
+		sql.Named("late", entity.Late),
+		sql.Named("excused", entity.Excused),
+		sql.Named("absent", entity.Absent),
+	}
+}
+
+func (r *ReportRepoImpl) GetStudentAttendReports(courseID string, now time.Time) ([]model.StudentAttendReport, error) {
+	var reports []model.StudentAttendReport
+
+	args := append(reportArgs(courseID), sql.Named("now", now))
+	if err := r.db.Raw(studentAttendReportQuery+` SELECT * FROM report ORDER BY attendance_rate, student_id`, args...).Scan(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+func (r *ReportRepoImpl) GetAtRiskStudents(courseID string, now time.Time, threshold float64) ([]model.StudentAttendReport, error) {
+	var reports []model.StudentAttendReport
+
+	args := append(reportArgs(courseID), sql.Named("now", now), sql.Named("threshold", threshold))
+	if err := r.db.Raw(studentAttendReportQuery+` SELECT * FROM report WHERE attendance_rate < @threshold ORDER BY attendance_rate, student_id`, args...).Scan(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+func (r *ReportRepoImpl) GetClassTurnoutReports(courseID string) ([]model.ClassTurnoutReport, error) {
+	var reports []model.ClassTurnoutReport
+
+	if err := r.db.Raw(classTurnoutReportQuery+` SELECT * FROM turnout ORDER BY start_date`, reportArgs(courseID)...).Scan(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// interval must be one of postgres date_trunc field, validated by service layer
+func (r *ReportRepoImpl) GetAttendTrendReports(courseID, interval, timezone string) ([]model.AttendTrendReport, error) {
+	var reports []model.AttendTrendReport
+
+	args := append(reportArgs(courseID), sql.Named("interval", interval), sql.Named("timezone", timezone))
+	query := classTurnoutReportQuery + `
+	SELECT date_trunc(@interval, start_date AT TIME ZONE @timezone) AT TIME ZONE @timezone AS period,
+		COUNT(*) AS classes,
+		SUM(present + late)::int AS attended,
+		ROUND(AVG(turnout_rate)::numeric, 2)::float8 AS average_turnout
+	FROM turnout
+	GROUP BY period
+	ORDER BY period`
+
+	if err := r.db.Raw(query, args...).Scan(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+)
+
+type RoomRepo interface {
+	CreateRoom(room *entity.Room) error
+	GetRooms() ([]entity.Room, error)
+	GetRoomByID(roomID string) (*entity.Room, error)
+	UpdateRoomByID(roomID string, room *entity.Room) error
+	DeleteRoomByID(roomID string) error
+	GetRoomBookings(roomID uint, startDate, endDate time.Time, excludeClassID uint) ([]entity.Class, error)
+}
+
+type RoomRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewRoomRepo(db *gorm.DB) RoomRepo {
+	return &RoomRepoImpl{
+		db: db,
+	}
+}
+
+func (r *RoomRepoImpl) CreateRoom(room *entity.Room) error {
+	if err := r.db.Create(room).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RoomRepoImpl) GetRooms() ([]entity.Room, error) {
+	var rooms []entity.Room
+
+	if err := r.db.Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
+func (r *RoomRepoImpl) GetRoomByID(roomID string) (*entity.Room, error) {
+	var room entity.Room
+
+	if err := r.db.Where("room_id = ?", roomID).First(&room).Error; err != nil {
+		return nil, err
+	}
+
+	return &room, nil
+}
+
+func (r *RoomRepoImpl) UpdateRoomByID(roomID string, room *entity.Room) error {
+	if err := r.db.Where("room_id = ?", roomID).Updates(room).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RoomRepoImpl) DeleteRoomByID(roomID string) error {
+	var room entity.Room
+
+	if err := r.db.Where("room_id = ?", roomID).Delete(&room).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// classes of any course booked in the room that overlap the given time range
+func (r *RoomRepoImpl) GetRoomBookings(roomID uint, startDate, endDate time.Time, excludeClassID uint) ([]entity.Class, error) {
+	var classes []entity.Class
+
+	if err := r.db.Where("room_id = ? AND class_id <> ? AND start_date < ? AND end_date > ?", roomID, excludeClassID, endDate, startDate).Find(&classes).Error; err != nil {
+		return nil, err
+	}
+
+	return classes, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+)
+
+type RubricRepo interface {
+	CreateRubric(rubric *entity.Rubric) error
+	GetRubrics() ([]entity.Rubric, error)
+	GetRubricByID(rubricID string) (*entity.Rubric, error)
+	ReplaceRubric(rubric *entity.Rubric) error
+	DeleteRubric(rubric *entity.Rubric) error
+	IsRubricUsed(rubricID uint) (bool, error)
+	IsRubricGraded(rubricID uint) (bool, error)
+}
+
+type RubricRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewRubricRepo(db *gorm.DB) RubricRepo {
+	return &RubricRepoImpl{
+		db: db,
+	}
+}
+
+// criteria in rubric order, levels from lowest points
+func preloadRubric(db *gorm.DB) *gorm.DB {
+	return db.Preload("Criteria", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position")
+	}).Preload("Criteria.Levels", func(db *gorm.DB) *gorm.DB {
+		return db.Order("points")
+	})
+}
+
+func (r *RubricRepoImpl) CreateRubric(rubric *entity.Rubric) error {
+	if err := r.db.Create(rubric).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RubricRepoImpl) GetRubrics() ([]entity.Rubric, error) {
+	var rubrics []entity.Rubric
+
+	if err := preloadRubric(r.db).Order("title").Find(&rubrics).Error; err != nil {
+		return nil, err
+	}
+
+	return rubrics, nil
+}
+
+func (r *RubricRepoImpl) GetRubricByID(rubricID string) (*entity.Rubric, error) {
+	var rubric entity.Rubric
+
+	if err := preloadRubric(r.db).Where("rubric_id = ?", rubricID).First(&rubric).Error; err != nil {
+		return nil, err
+	}
+
+	return &rubric, nil
+}
+
+// rubric details are saved & every criterion is recreated from rubric.Criteria
+func (r *RubricRepoImpl) ReplaceRubric(rubric *entity.Rubric) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		criteria := tx.Model(&entity.RubricCriterion{}).Select("criterion_id").Where("rubric_id = ?", rubric.RubricID)
+		if err := tx.Where("criterion_id IN (?)", criteria).Delete(&entity.RubricLevel{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("rubric_id = ?", rubric.RubricID).Delete(&entity.RubricCriterion{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(rubric).Error
+	})
+}
+
+func (r *RubricRepoImpl) DeleteRubric(rubric *entity.Rubric) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		criteria := tx.Model(&entity.RubricCriterion{}).Select("criterion_id").Where("rubric_id = ?", rubric.RubricID)
+		if err := tx.Where("criterion_id IN (?)", criteria).Delete(&entity.RubricLevel{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("rubric_id = ?", rubric.RubricID).Delete(&entity.RubricCriterion{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(rubric).Error
+	})
+}
+
+// any project is using the rubric
+func (r *RubricRepoImpl) IsRubricUsed(rubricID uint) (bool, error) {
+	var count int64
+
+	if err := r.db.Model(&entity.Project{}).Where("rubric_id = ?", rubricID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// any submission has been graded with the rubric criteria
+func (r *RubricRepoImpl) IsRubricGraded(rubricID uint) (bool, error) {
+	var count int64
+
+	criteria := r.db.Model(&entity.RubricCriterion{}).Select("criterion_id").Where("rubric_id = ?", rubricID)
+	if err := r.db.Model(&entity.CriterionGrade{}).Where("criterion_id IN (?)", criteria).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+)
+
+type ScanRepo interface {
+	GetPendingScans(limit int) ([]entity.ProjectSub, error)
+	SaveScan(projectSub *entity.ProjectSub) error
+	QueueScan(projectSub *entity.ProjectSub) error
+	QueueUnscanned() (int64, error)
+	GetPendingExcuseScans(limit int) ([]entity.Excuse, error)
+	SaveExcuseScan(excuse *entity.Excuse) error
+}
+
+type ScanRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewScanRepo(db *gorm.DB) ScanRepo {
+	return &ScanRepoImpl{
+		db: db,
+	}
+}
+
+// oldest quarantined files first
+func (r *ScanRepoImpl) GetPendingScans(limit int) ([]entity.ProjectSub, error) {
+	var projectSubs []entity.ProjectSub
+
+	if err := r.db.Where("scan_status = ?", entity.ScanPending).Order("submission_date, project_sub_id").Limit(limit).Find(&projectSubs).Error; err != nil {
+		return nil, err
+	}
+
+	return projectSubs, nil
+}
+
+// store the scan outcome, an infected file is never auto graded
+func (r *ScanRepoImpl) SaveScan(projectSub *entity.ProjectSub) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(projectSub).Select("scan_status", "scan_signature", "scanned_at").Updates(projectSub).Error; err != nil {
+			return err
+		}
+
+		if projectSub.ScanStatus != entity.ScanInfected {
+			return nil
+		}
+
+		return tx.Model(&entity.ProjectSub{}).Where("project_sub_id = ? AND auto_status = ?", projectSub.ProjectSubID, entity.AutoGradePending).
+			Updates(map[string]interface{}{"auto_status": entity.AutoGradeError, "auto_feedback": "submission file failed the malware scan"}).Error
+	})
+}
+
+// quarantine the file again until the next scan
+func (r *ScanRepoImpl) QueueScan(projectSub *entity.ProjectSub) error {
+	return r.db.Model(projectSub).Select("scan_status", "scan_signature", "scanned_at").
+		Updates(map[string]interface{}{"scan_status": entity.ScanPending, "scan_signature": "", "scanned_at": nil}).Error
+}
+
+// quarantine files stored while scanning was disabled, they are scanned like new uploads
+func (r *ScanRepoImpl) QueueUnscanned() (int64, error) {
+	var queued int64
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.ProjectSub{}).Where("scan_status = '' AND file_key <> ''").Update("scan_status", entity.ScanPending)
+		if result.Error != nil {
+			return result.Error
+		}
+		queued += result.RowsAffected
+
+		result = tx.Model(&entity.Excuse{}).Where("scan_status = '' AND document_key <> ''").Update("scan_status", entity.ScanPending)
+		if result.Error != nil {
+			return result.Error
+		}
+		queued += result.RowsAffected
+
+		return nil
+	})
+
+	return queued, err
+}
+
+// oldest quarantined excuse documents first
+func (r *ScanRepoImpl) GetPendingExcuseScans(limit int) ([]entity.Excuse, error) {
+	var excuses []entity.Excuse
+
+	if err := r.db.Where("scan_status = ?", entity.ScanPending).Order("created_at, excuse_id").Limit(limit).Find(&excuses).Error; err != nil {
+		return nil, err
+	}
+
+	return excuses, nil
+}
+
+func (r *ScanRepoImpl) SaveExcuseScan(excuse *entity.Excuse) error {
+	return r.db.Model(excuse).Select("scan_status", "scan_signature", "scanned_at").Updates(excuse).Error
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// analysis of the project is already running
+var ErrReportRunning = errors.New("similarity analysis is already running")
+
+// running report older than this is left over from a stopped server
+const staleReportAge = time.Hour
+
+type SimilarityRepo interface {
+	GetProjectsToAnalyze(now time.Time) ([]entity.Project, error)
+	CreateReport(report *entity.SimilarityReport) error
+	SaveReport(report *entity.SimilarityReport) error
+	GetReports(projectID string) ([]entity.SimilarityReport, error)
+	GetReportByID(projectID, reportID string) (*entity.SimilarityReport, error)
+	UpdateFileHash(projectSubID uint, fileHash string) error
+}
+
+type SimilarityRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewSimilarityRepo(db *gorm.DB) SimilarityRepo {
+	return &SimilarityRepoImpl{
+		db: db,
+	}
+}
+
+// projects past their deadline with a latest submission made after their last report
+func (r *SimilarityRepoImpl) GetProjectsToAnalyze(now time.Time) ([]entity.Project, error) {
+	var projects []entity.Project
+
+	if err := r.db.Where("deadline < ?", now).
+		Where(`EXISTS (SELECT 1 FROM project_subs ps WHERE ps.project_id = projects.project_id AND ps.is_latest
+			AND NOT EXISTS (SELECT 1 FROM similarity_reports sr WHERE sr.project_id = projects.project_id AND sr.created_at > ps.submission_date))`).
+		Find(&projects).Error; err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// project row is locked so only one analysis of a project runs at a time
+func (r *SimilarityRepoImpl) CreateReport(report *entity.SimilarityReport) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("project_id = ?", report.ProjectID).First(&entity.Project{}).Error; err != nil {
+			return err
+		}
+
+		var running int64
+		if err := tx.Model(&entity.SimilarityReport{}).
+			Where("project_id = ? AND status = ? AND created_at > ?", report.ProjectID, entity.ReportRunning, time.Now().Add(-staleReportAge)).
+			Count(&running).Error; err != nil {
+			return err
+		}
+
+		if running > 0 {
+			return ErrReportRunning
+		}
+
+		return tx.Create(report).Error
+	})
+}
+
+// store the outcome of the analysis together with its pairs & segments
+func (r *SimilarityRepoImpl) SaveReport(report *entity.SimilarityReport) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(report).Error; err != nil {
+			return err
+		}
+
+		for i := range report.Pairs {
+			report.Pairs[i].ReportID = report.ReportID
+		}
+
+		if len(report.Pairs) > 0 {
+			if err := tx.Create(&report.Pairs).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// reports of the project newest first, without their pairs
+func (r *SimilarityRepoImpl) GetReports(projectID string) ([]entity.SimilarityReport, error) {
+	var reports []entity.SimilarityReport
+
+	if err := r.db.Where("project_id = ?", projectID).Order("created_at DESC").Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// report with its pairs, most similar first
+func (r *SimilarityRepoImpl) GetReportByID(projectID, reportID string) (*entity.SimilarityReport, error) {
+	var report entity.SimilarityReport
+
+	err := r.db.Preload("Pairs", func(db *gorm.DB) *gorm.DB {
+		return db.Order("exact_duplicate DESC, similarity DESC, pair_id")
+	}).Preload("Pairs.Segments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("words DESC, segment_id")
+	}).Where("project_id = ? AND report_id = ?", projectID, reportID).First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+func (r *SimilarityRepoImpl) UpdateFileHash(projectSubID uint, fileHash string) error {
+	return r.db.Model(&entity.ProjectSub{}).Where("project_sub_id = ?", projectSubID).UpdateColumn("file_hash", fileHash).Error
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/nadyafa/go-learn/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// team reached the project max team size while joining
+var ErrTeamFull = errors.New("team is full")
+
+type TeamRepo interface {
+	CreateTeams(teams []entity.Team) error
+	GetTeams(projectID string) ([]entity.Team, error)
+	GetTeamByID(projectID, teamID string) (*entity.Team, error)
+	GetStudentTeam(projectID, studentID uint) (*entity.Team, error)
+	GetTeamedStudentIDs(projectID uint) ([]uint, error)
+	AddMember(member *entity.TeamMember, maxSize int) error
+	UpdateMember(member *entity.TeamMember) error
+	RemoveMember(team *entity.Team, studentID uint) error
+	DeleteTeam(team *entity.Team) error
+	HasTeamSubmissions(teamID uint) (bool, error)
+}
+
+type TeamRepoImpl struct {
+	db *gorm.DB
+}
+
+func NewTeamRepo(db *gorm.DB) TeamRepo {
+	return &TeamRepoImpl{
+		db: db,
+	}
+}
+
+func preloadMembers(db *gorm.DB) *gorm.DB {
+	return db.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	})
+}
+
+// teams are created with their members, unique membership per project fails the whole batch
+func (r *TeamRepoImpl) CreateTeams(teams []entity.Team) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range teams {
+			if err := tx.Create(&teams[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *TeamRepoImpl) GetTeams(projectID string) ([]entity.Team, error) {
+	var teams []entity.Team
+
+	if err := preloadMembers(r.db).Where("project_id = ?", projectID).Order("team_id").Find(&teams).Error; err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (r *TeamRepoImpl) GetTeamByID(projectID, teamID string) (*entity.Team, error) {
+	var team entity.Team
+
+	if err := preloadMembers(r.db).Where("project_id = ? AND team_id = ?", projectID, teamID).First(&team).Error; err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+func (r *TeamRepoImpl) GetStudentTeam(projectID, studentID uint) (*entity.Team, error) {
+	var team entity.Team
+
+	members := r.db.Model(&entity.TeamMember{}).Select("team_id").Where("project_id = ? AND student_id = ?", projectID, studentID)
+	if err := preloadMembers(r.db).Where("team_id IN (?)", members).First(&team).Error; err != nil {
+		return nil, err
+	}
+
+	return &team, nil
+}
+
+func (r *TeamRepoImpl) GetTeamedStudentIDs(projectID uint) ([]uint, error) {
+	var studentIDs []uint
+
+	if err := r.db.Model(&entity.TeamMember{}).Where("project_id = ?", projectID).Pluck("student_id", &studentIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return studentIDs, nil
+}
+
+// team row is locked so concurrent joins can't go over max size
+func (r *TeamRepoImpl) AddMember(member *entity.TeamMember, maxSize int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("team_id = ?", member.TeamID).First(&entity.Team{}).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&entity.TeamMember{}).Where("team_id = ?", member.TeamID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if maxSize > 0 && int(count) >= maxSize {
+			return ErrTeamFull
+		}
+
+		return tx.Create(member).Error
+	})
+}
+
+func (r *TeamRepoImpl) UpdateMember(member *entity.TeamMember) error {
+	return r.db.Save(member).Error
+}
+
+// team without members left is deleted
+func (r *TeamRepoImpl) RemoveMember(team *entity.Team, studentID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("team_id = ? AND student_id = ?", team.TeamID, studentID).Delete(&entity.TeamMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var count int64
+		if err := tx.Model(&entity.TeamMember{}).Where("team_id = ?", team.TeamID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return nil
+		}
+
+		return tx.Delete(team).Error
+	})
+}
+
+func (r *TeamRepoImpl) DeleteTeam(team *entity.Team) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", team.TeamID).Delete(&entity.TeamMember{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(team).Error
+	})
+}
+
+func (r *TeamRepoImpl) HasTeamSubmissions(teamID uint) (bool, error) {
+	var count int64
+
+	if err := r.db.Model(&entity.ProjectSub{}).Where("team_id = ?", teamID).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -11,6 +11,9 @@ type UserRepo interface {
 	GetUserByID(userID string) (*entity.User, error)
 	UpdateUserRoleByID(userID string, role string) (*entity.User, error)
 	DeleteUserByID(userID string) error
+	UpdateUserTimezone(userID, timezone string) error
+	GetUsersByEmails(emails []string) ([]entity.User, error)
+	GetUsersByUsernames(usernames []string) ([]entity.User, error)
 }
 
 type UserRepoImpl struct {
@@ -70,3 +73,32 @@ func (r *UserRepoImpl) DeleteUserByID(userID string) error {
 
 	return nil
 }
+
+func (r *UserRepoImpl) UpdateUserTimezone(userID, timezone string) error {
+	if err := r.db.Model(&entity.User{}).Where("user_id = ?", userID).Update("timezone", timezone).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *UserRepoImpl) GetUsersByEmails(emails []string) ([]entity.User, error) {
+	var users []entity.User
+
+	// emails are matched case-insensitively, callers pass them lowercased
+	if err := r.db.Where("LOWER(email) IN ?", emails).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *UserRepoImpl) GetUsersByUsernames(usernames []string) ([]entity.User, error) {
+	var users []entity.User
+
+	if err := r.db.Where("username IN ?", usernames).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
@@ -14,6 +14,10 @@ type AttendService interface {
 	StudentAttendClass(userClaims *middleware.UserClaims, courseID, classID string, attendReq model.AttendReq) (*entity.Attendance, error)
 	GetClassAttendances(userClaims *middleware.UserClaims, courseID, classID string) ([]entity.Attendance, error)
 	DeleteAttendanceByID(userClaims *middleware.UserClaims, courseID, classID, attendID string) error
+	GetCheckinCode(userClaims *middleware.UserClaims, courseID, classID string) (*entity.CheckinCode, error)
+	StudentCheckin(userClaims *middleware.UserClaims, courseID, classID, code string) (*entity.Attendance, bool, error)
+	BulkUpdateAttendances(userClaims *middleware.UserClaims, courseID, classID string, bulkReq model.BulkAttendReq) ([]entity.Attendance, error)
+	MarkAbsentStudents() (int64, error)
 }
 
 type AttendServiceImpl struct {
@@ -32,9 +36,10 @@ func NewAttendService(attendRepo repository.AttendRepo, courseRepo repository.Co
 	}
 }
 
+// record attendance manually (admin & course mentor), student must check in with a code
 func (s *AttendServiceImpl) StudentAttendClass(userClaims *middleware.UserClaims, courseID, classID string, attendReq model.AttendReq) (*entity.Attendance, error) {
-	if userClaims.Role == entity.Mentor {
-		return nil, fmt.Errorf("only admin & student can post attend class")
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("student must check in using the class check-in code")
 	}
 
 	// check if course exist
@@ -43,6 +48,11 @@ func (s *AttendServiceImpl) StudentAttendClass(userClaims *middleware.UserClaims
 		return nil, fmt.Errorf("course_id %s not found", courseID)
 	}
 
+	// check if mentor own the course
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("you prohibit to do this action")
+	}
+
 	// check if class exist
 	class, err := s.classRepo.GetClassByID(courseID, classID)
 	if err != nil {
@@ -50,11 +60,7 @@ func (s *AttendServiceImpl) StudentAttendClass(userClaims *middleware.UserClaims
 	}
 
 	if attendReq.StudentID == 0 {
-		if userClaims.Role == entity.Student {
-			attendReq.StudentID = userClaims.UserID
-		} else {
-			return nil, fmt.Errorf("student_id is required")
-		}
+		return nil, fmt.Errorf("student_id is required")
 	}
 
 	// make sure user is enrolled in course
@@ -67,12 +73,40 @@ func (s *AttendServiceImpl) StudentAttendClass(userClaims *middleware.UserClaims
 		return nil, fmt.Errorf("student not enroll to course")
 	}
 
+	// status derived from attended flag when not given
+	status := entity.AttendStatus(attendReq.Status)
+	if status == "" {
+		status = entity.Absent
+		if attendReq.Attended {
+			status = entity.Present
+		}
+	}
+
+	if !status.IsValid() {
+		return nil, fmt.Errorf("status must be present, late, excused or absent")
+	}
+
+	// update existing attendance instead of recording twice
+	existingAttend, err := s.attendRepo.GetStudentClassAttendance(classID, fmt.Sprint(attendReq.StudentID))
+	if err == nil {
+		existingAttend.Attended = status.Attended()
+		existingAttend.Status = status
+		existingAttend.AttendAt = time.Now()
+
+		if err := s.attendRepo.UpdateAttendance(existingAttend); err != nil {
+			return nil, fmt.Errorf("unable to update attendance")
+		}
+
+		return existingAttend, nil
+	}
+
 	// create entity attendance
 	newAttend := entity.Attendance{
 		StudentID: attendReq.StudentID,
 		ClassID:   class.ClassID,
 		CourseID:  course.CourseID,
-		Attended:  true,
+		Attended:  status.Attended(),
+		Status:    status,
 		AttendAt:  time.Now(),
 	}
 
@@ -139,3 +173,298 @@ func (s *AttendServiceImpl) DeleteAttendanceByID(userClaims *middleware.UserClai
 
 	return nil
 }
+
+// current check-in code of a running class, a new code is issued once the previous one expired
+func (s *AttendServiceImpl) GetCheckinCode(userClaims *middleware.UserClaims, courseID, classID string) (*entity.CheckinCode, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can generate check-in code")
+	}
+
+	// check if course exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	// check if mentor own the course
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("you prohibit to do this action")
+	}
+
+	// check if class exist
+	class, err := s.classRepo.GetClassByID(courseID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("class_id %s not found", classID)
+	}
+
+	// code only available while class is running
+	now := time.Now()
+	if now.Before(class.StartDate) || now.After(class.EndDate.Add(middleware.CheckinGracePeriod)) {
+		return nil, fmt.Errorf("check-in code only available while class is running")
+	}
+
+	// reuse code until it expired
+	if checkinCode, err := s.attendRepo.GetActiveCheckinCode(classID, now); err == nil {
+		return checkinCode, nil
+	}
+
+	code, err := middleware.GenerateCheckinCode()
+	if err != nil {
+		return nil, err
+	}
+
+	checkinCode := entity.CheckinCode{
+		ClassID:   class.ClassID,
+		CourseID:  course.CourseID,
+		Code:      code,
+		CreatedBy: userClaims.UserID,
+		ExpiresAt: now.Add(middleware.CheckinCodeTTL),
+	}
+
+	if err := s.attendRepo.CreateCheckinCode(&checkinCode); err != nil {
+		return nil, fmt.Errorf("unable to create check-in code")
+	}
+
+	return &checkinCode, nil
+}
+
+// student check in with code, returns false when student already checked in before
+func (s *AttendServiceImpl) StudentCheckin(userClaims *middleware.UserClaims, courseID, classID, code string) (*entity.Attendance, bool, error) {
+	if userClaims.Role != entity.Student {
+		return nil, false, fmt.Errorf("only student can check in to class")
+	}
+
+	// check if course exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, false, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	// check if class exist
+	class, err := s.classRepo.GetClassByID(courseID, classID)
+	if err != nil {
+		return nil, false, fmt.Errorf("class_id %s not found", classID)
+	}
+
+	studentID := fmt.Sprint(userClaims.UserID)
+
+	// make sure user is enrolled in course
+	enroll, err := s.enrollRepo.GetStudentCourseEnroll(courseID, studentID)
+	if err != nil || enroll.EnrollStatus != entity.Enroll {
+		return nil, false, fmt.Errorf("student not enroll to course")
+	}
+
+	// validate check-in window
+	now := time.Now()
+	if now.Before(class.StartDate) {
+		return nil, false, fmt.Errorf("check-in is not open yet")
+	}
+
+	if now.After(class.EndDate.Add(middleware.CheckinGracePeriod)) {
+		return nil, false, fmt.Errorf("check-in is closed")
+	}
+
+	// repeated check-in returns the existing attendance
+	existingAttend, err := s.attendRepo.GetStudentClassAttendance(classID, studentID)
+	if err == nil && existingAttend.Status != entity.Absent {
+		return existingAttend, false, nil
+	}
+
+	// every try counts before the code is checked, so parallel guesses are capped too
+	attempts, err := s.attendRepo.AddCheckinAttempt(class.ClassID, userClaims.UserID)
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to check in")
+	}
+
+	if attempts > middleware.MaxCheckinAttempts {
+		return nil, false, fmt.Errorf("too many check-in attempts, please ask your mentor to record your attendance")
+	}
+
+	// validate check-in code
+	if _, err := s.attendRepo.GetValidCheckinCode(classID, code, now); err != nil {
+		return nil, false, fmt.Errorf("invalid or expired check-in code")
+	}
+
+	// late once the course threshold after class start has passed
+	status := entity.Present
+	if now.After(class.StartDate.Add(time.Duration(course.LateThresholdMinutes) * time.Minute)) {
+		status = entity.Late
+	}
+
+	if existingAttend != nil {
+		existingAttend.Attended = true
+		existingAttend.Status = status
+		existingAttend.AttendAt = now
+
+		if err := s.attendRepo.UpdateAttendance(existingAttend); err != nil {
+			return nil, false, fmt.Errorf("unable to update attendance")
+		}
+
+		return existingAttend, true, nil
+	}
+
+	newAttend := entity.Attendance{
+		StudentID: userClaims.UserID,
+		ClassID:   class.ClassID,
+		CourseID:  course.CourseID,
+		Attended:  true,
+		Status:    status,
+		AttendAt:  now,
+	}
+
+	attend, err := s.attendRepo.CreateAttendance(newAttend)
+	if err != nil {
+		// concurrent check-in already recorded attendance
+		if existingAttend, err := s.attendRepo.GetStudentClassAttendance(classID, studentID); err == nil {
+			return existingAttend, false, nil
+		}
+
+		return nil, false, fmt.Errorf("unable to create attendance")
+	}
+
+	return attend, true, nil
+}
+
+// set attendance status for the whole class roster (admin & course mentor)
+func (s *AttendServiceImpl) BulkUpdateAttendances(userClaims *middleware.UserClaims, courseID, classID string, bulkReq model.BulkAttendReq) ([]entity.Attendance, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can update attendance")
+	}
+
+	// check if course exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	// check if mentor own the course
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("you prohibit to do this action")
+	}
+
+	// check if class exist
+	class, err := s.classRepo.GetClassByID(courseID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("class_id %s not found", classID)
+	}
+
+	if bulkReq.Status == "" && len(bulkReq.Students) == 0 {
+		return nil, fmt.Errorf("status or students is required")
+	}
+
+	// class roster
+	enrolls, err := s.enrollRepo.GetCourseEnrolls(courseID, entity.Enroll)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch enrolled students")
+	}
+
+	statuses := make(map[uint]entity.AttendStatus)
+
+	// default status for every enrolled student
+	if bulkReq.Status != "" {
+		status := entity.AttendStatus(bulkReq.Status)
+		if !status.IsValid() {
+			return nil, fmt.Errorf("status must be present, late, excused or absent")
+		}
+
+		for _, enroll := range enrolls {
+			statuses[enroll.StudentID] = status
+		}
+	}
+
+	// per student override
+	enrolled := make(map[uint]bool)
+	for _, enroll := range enrolls {
+		enrolled[enroll.StudentID] = true
+	}
+
+	for _, student := range bulkReq.Students {
+		if !enrolled[student.StudentID] {
+			return nil, fmt.Errorf("student_id %d not enroll to course", student.StudentID)
+		}
+
+		status := entity.AttendStatus(student.Status)
+		if !status.IsValid() {
+			return nil, fmt.Errorf("status of student_id %d must be present, late, excused or absent", student.StudentID)
+		}
+
+		statuses[student.StudentID] = status
+	}
+
+	now := time.Now()
+	var attends []entity.Attendance
+
+	for studentID, status := range statuses {
+		attends = append(attends, entity.Attendance{
+			StudentID: studentID,
+			ClassID:   class.ClassID,
+			CourseID:  course.CourseID,
+			Attended:  status.Attended(),
+			Status:    status,
+			AttendAt:  now,
+		})
+	}
+
+	if len(attends) == 0 {
+		return nil, fmt.Errorf("course has no enrolled students")
+	}
+
+	if err := s.attendRepo.UpsertAttendances(attends); err != nil {
+		return nil, fmt.Errorf("unable to update attendances")
+	}
+
+	// return the whole class attendance list
+	attendances, err := s.attendRepo.GetClassAttendances(courseID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch attendance lists")
+	}
+
+	return attendances, nil
+}
+
+// mark enrolled students without attendance as absent once class ended & check-in grace passed
+func (s *AttendServiceImpl) MarkAbsentStudents() (int64, error) {
+	now := time.Now()
+
+	classes, err := s.classRepo.GetClassesToClose(now.Add(-middleware.CheckinGracePeriod))
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch ended classes: %v", err)
+	}
+
+	var marked int64
+
+	for _, class := range classes {
+		enrolls, err := s.enrollRepo.GetCourseEnrolls(fmt.Sprint(class.CourseID), entity.Enroll)
+		if err != nil {
+			return marked, fmt.Errorf("unable to fetch enrolled students of course_id %d: %v", class.CourseID, err)
+		}
+
+		var attends []entity.Attendance
+
+		for _, enroll := range enrolls {
+			attends = append(attends, entity.Attendance{
+				StudentID: enroll.StudentID,
+				ClassID:   class.ClassID,
+				CourseID:  class.CourseID,
+				Attended:  false,
+				Status:    entity.Absent,
+				AttendAt:  now,
+			})
+		}
+
+		if len(attends) > 0 {
+			count, err := s.attendRepo.CreateMissingAttendances(attends)
+			if err != nil {
+				return marked, fmt.Errorf("unable to mark absent students of class_id %d: %v", class.ClassID, err)
+			}
+
+			marked += count
+		}
+
+		if err := s.classRepo.CloseClassAttendance(class.ClassID); err != nil {
+			return marked, fmt.Errorf("unable to close attendance of class_id %d: %v", class.ClassID, err)
+		}
+	}
+
+	return marked, nil
+}
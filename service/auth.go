@@ -3,6 +3,7 @@ package service
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/nadyafa/go-learn/entity"
@@ -11,9 +12,13 @@ import (
 	"github.com/nadyafa/go-learn/repository"
 )
 
+// how long the set password link of an imported user stays valid
+const PasswordTokenTTL = 72 * time.Hour
+
 type AuthService interface {
 	UserSignup(userSignup model.UserSignup) (*entity.User, error)
 	UserSignin(user model.UserSignin) (*entity.User, string, error)
+	SetPassword(token string, setPassword model.SetPassword) error
 }
 
 type AuthServiceImpl struct {
@@ -35,6 +40,13 @@ func (s *AuthServiceImpl) UserSignup(userSignup model.UserSignup) (*entity.User,
 		return nil, fmt.Errorf("validation failed: %v", errorMsg)
 	}
 
+	// validate timezone input, optional
+	if userSignup.Timezone != "" {
+		if _, err := middleware.LoadTimezone(userSignup.Timezone); err != nil {
+			return nil, err
+		}
+	}
+
 	// check if username is already exist
 	existingUser, _ := s.authRepo.FindByUsername(userSignup.Username)
 	if existingUser == nil {
@@ -59,6 +71,7 @@ func (s *AuthServiceImpl) UserSignup(userSignup model.UserSignup) (*entity.User,
 		Email:    userSignup.Email,
 		Password: hashedPassword,
 		Role:     entity.Student,
+		Timezone: userSignup.Timezone,
 	}
 
 	// save user to db
@@ -126,10 +139,35 @@ func (s *AuthServiceImpl) UserSignin(user model.UserSignin) (*entity.User, strin
 	}
 
 	// generate JWT token
-	token, err := middleware.GenerateJWT(existingUser.Username, existingUser.Role, existingUser.UserID)
+	token, err := middleware.GenerateJWT(existingUser.Username, existingUser.Role, existingUser.UserID, existingUser.Timezone)
 	if err != nil {
 		return nil, "", fmt.Errorf("unable to generate token: %v", err)
 	}
 
 	return existingUser, token, nil
 }
+
+// imported user chooses their password with the link sent by mail
+func (s *AuthServiceImpl) SetPassword(token string, setPassword model.SetPassword) error {
+	if err := s.validator.Struct(setPassword); err != nil {
+		return fmt.Errorf("password must be at least 8 characters alphanumerical")
+	}
+
+	tokenHash := middleware.HashPasswordToken(token)
+
+	user, err := s.authRepo.FindByPasswordToken(tokenHash)
+	if token == "" || err != nil {
+		return fmt.Errorf("invalid password link")
+	}
+
+	if user.PasswordTokenExpiresAt == nil || time.Now().After(*user.PasswordTokenExpiresAt) {
+		return fmt.Errorf("password link has expired")
+	}
+
+	hashedPassword, err := middleware.HashPassword(setPassword.Password)
+	if err != nil {
+		return fmt.Errorf("unable to hash password")
+	}
+
+	return s.authRepo.SetPassword(user, tokenHash, hashedPassword)
+}
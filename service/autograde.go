@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/nadyafa/go-learn/autograde"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/repository"
+	"github.com/nadyafa/go-learn/storage"
+)
+
+const (
+	// seconds a test suite may run by default & at most
+	DefaultTestTimeout = 120
+	MaxTestTimeout     = 600
+
+	// storage prefix of test suites, kept apart from submissions so they can't be downloaded as one
+	TestSuitePrefix = "test-suites"
+
+	// submissions graded per job run
+	autogradeBatch = 10
+)
+
+// hidden test suites are zips of go test files
+var testSuitePolicy = middleware.FilePolicy{FileTypes: []string{".zip"}, MaxSize: middleware.MaxFileSize}
+
+type AutogradeService interface {
+	RunPending() (int, error)
+	RegradeSubmission(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) error
+	RegradeProject(userClaims *middleware.UserClaims, courseID, projectID string) (int64, error)
+}
+
+type AutogradeServiceImpl struct {
+	autogradeRepo  repository.AutogradeRepo
+	projectSubRepo repository.ProjectSubRepo
+	projectRepo    repository.ProjectRepo
+	courseRepo     repository.CourseRepo
+	sandbox        autograde.Sandbox
+	store          storage.Storage
+}
+
+func NewAutogradeService(autogradeRepo repository.AutogradeRepo, projectSubRepo repository.ProjectSubRepo, projectRepo repository.ProjectRepo, courseRepo repository.CourseRepo, sandbox autograde.Sandbox, store storage.Storage) AutogradeService {
+	return &AutogradeServiceImpl{
+		autogradeRepo:  autogradeRepo,
+		projectSubRepo: projectSubRepo,
+		projectRepo:    projectRepo,
+		courseRepo:     courseRepo,
+		sandbox:        sandbox,
+		store:          store,
+	}
+}
+
+// check course & project with a test suite exist and mentor owns the course
+func (s *AutogradeServiceImpl) checkAutogradeAccess(userClaims *middleware.UserClaims, courseID, projectID string) (*entity.Project, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can run the test suite")
+	}
+
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	project, err := s.projectRepo.GetProjectByID(courseID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	if project.TestSuiteKey == "" {
+		return nil, fmt.Errorf("project doesn't have a test suite")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("mentor can only grade submissions of their own course")
+	}
+
+	return project, nil
+}
+
+// grade queued submissions one at a time, returns the number of finished runs
+func (s *AutogradeServiceImpl) RunPending() (int, error) {
+	// a run never takes longer than the max timeout plus the sandbox allowance
+	if err := s.autogradeRepo.ResetStaleRuns(time.Now().Add(-(MaxTestTimeout*time.Second + 5*time.Minute))); err != nil {
+		return 0, err
+	}
+
+	projectSubs, err := s.autogradeRepo.GetPendingSubs(autogradeBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	graded := 0
+	for i := range projectSubs {
+		claimed, err := s.autogradeRepo.ClaimSub(projectSubs[i].ProjectSubID, time.Now())
+		if err != nil {
+			return graded, err
+		}
+
+		if !claimed {
+			continue
+		}
+
+		s.grade(&projectSubs[i])
+		graded++
+	}
+
+	return graded, nil
+}
+
+// run the hidden tests, errors of the run itself are kept as feedback
+func (s *AutogradeServiceImpl) grade(projectSub *entity.ProjectSub) {
+	result, err := s.runTests(projectSub)
+
+	gradedAt := time.Now()
+	projectSub.AutoGradedAt = &gradedAt
+
+	if err != nil {
+		projectSub.AutoFeedback = err.Error()
+		if err := s.autogradeRepo.SaveError(projectSub); err != nil {
+			log.Printf("Error saving autograde error of submission %d: %v", projectSub.ProjectSubID, err)
+		}
+		return
+	}
+
+	results := make([]entity.TestResult, 0, len(result.Tests))
+	for _, test := range result.Tests {
+		results = append(results, entity.TestResult{
+			ProjectSubID: projectSub.ProjectSubID,
+			Package:      test.Package,
+			Test:         test.Name,
+			Status:       string(test.Status),
+			Elapsed:      test.Elapsed,
+			Output:       test.Output,
+		})
+	}
+
+	projectSub.AutoScore = &result.Score
+	projectSub.AutoFeedback = result.Feedback
+
+	if err := s.autogradeRepo.SaveResult(projectSub, results); err != nil {
+		log.Printf("Error saving autograde result of submission %d: %v", projectSub.ProjectSubID, err)
+	}
+}
+
+func (s *AutogradeServiceImpl) runTests(projectSub *entity.ProjectSub) (*autograde.Result, error) {
+	project, err := s.autogradeRepo.GetProject(projectSub.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	if project.TestSuiteKey == "" {
+		return nil, fmt.Errorf("project doesn't have a test suite")
+	}
+
+	if projectSub.FileKey == "" {
+		return nil, fmt.Errorf("submission file not found")
+	}
+
+	if !projectSub.ScanStatus.Released() {
+		return nil, fmt.Errorf("submission file hasn't passed the malware scan")
+	}
+
+	// sandbox works on local copies of both archives
+	filePath, err := storage.TempFile(context.Background(), s.store, projectSub.FileKey)
+	if err != nil {
+		return nil, fmt.Errorf("submission file not found")
+	}
+	defer os.Remove(filePath)
+
+	testSuitePath, err := storage.TempFile(context.Background(), s.store, project.TestSuiteKey)
+	if err != nil {
+		return nil, fmt.Errorf("test suite not found")
+	}
+	defer os.Remove(testSuitePath)
+
+	return autograde.Grade(context.Background(), s.sandbox, filePath, testSuitePath, time.Duration(project.TestTimeout)*time.Second)
+}
+
+// queue a submission for another run (admin & mentor)
+func (s *AutogradeServiceImpl) RegradeSubmission(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) error {
+	project, err := s.checkAutogradeAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return err
+	}
+
+	projectSub, err := s.projectSubRepo.GetProjectSubByID(projectID, projectSubID)
+	if err != nil {
+		return fmt.Errorf("project submission not found")
+	}
+
+	if projectSub.ScanStatus == entity.ScanInfected {
+		return fmt.Errorf("submission file failed the malware scan")
+	}
+
+	queued, err := s.autogradeRepo.QueueSubs(project.ProjectID, projectSub.ProjectSubID)
+	if err != nil {
+		return fmt.Errorf("unable to queue project submission")
+	}
+
+	if queued == 0 {
+		return fmt.Errorf("project submission is being graded")
+	}
+
+	return nil
+}
+
+// queue the latest attempt of every student, e.g. after the test suite changed (admin & mentor)
+func (s *AutogradeServiceImpl) RegradeProject(userClaims *middleware.UserClaims, courseID, projectID string) (int64, error) {
+	project, err := s.checkAutogradeAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return 0, err
+	}
+
+	queued, err := s.autogradeRepo.QueueSubs(project.ProjectID, 0)
+	if err != nil {
+		return 0, fmt.Errorf("unable to queue project submissions")
+	}
+
+	return queued, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/repository"
+)
+
+type CertificateService interface {
+	GetCertificatePDF(userClaims *middleware.UserClaims, courseID, enrollID string, loc *time.Location) (*entity.Certificate, []byte, error)
+	VerifyCertificate(serial string) (*entity.Certificate, error)
+	RevokeCertificate(userClaims *middleware.UserClaims, serial, reason string) (*entity.Certificate, error)
+}
+
+type CertificateServiceImpl struct {
+	certificateRepo repository.CertificateRepo
+	courseRepo      repository.CourseRepo
+	enrollRepo      repository.EnrollRepo
+	userRepo        repository.UserRepo
+}
+
+func NewCertificateService(certificateRepo repository.CertificateRepo, courseRepo repository.CourseRepo, enrollRepo repository.EnrollRepo, userRepo repository.UserRepo) CertificateService {
+	return &CertificateServiceImpl{
+		certificateRepo: certificateRepo,
+		courseRepo:      courseRepo,
+		enrollRepo:      enrollRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// issue certificate of a completed enrollment once, later calls return the same one
+func (s *CertificateServiceImpl) issueCertificate(course *entity.Course, enroll *entity.Enrollment) (*entity.Certificate, error) {
+	if certificate, err := s.certificateRepo.GetCertificateByEnroll(enroll.EnrollmentID); err == nil {
+		return certificate, nil
+	}
+
+	student, err := s.userRepo.GetUserByID(fmt.Sprint(enroll.StudentID))
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	mentor, err := s.userRepo.GetUserByID(fmt.Sprint(course.MentorID))
+	if err != nil {
+		return nil, fmt.Errorf("mentor not found")
+	}
+
+	// completion date is when the enrollment moved to complete
+	completedAt := enroll.UpdatedAt
+	histories, err := s.enrollRepo.GetEnrollHistories(enroll.EnrollmentID)
+	if err == nil {
+		for _, history := range histories {
+			if history.ToStatus == entity.Complete {
+				completedAt = history.CreatedAt
+			}
+		}
+	}
+
+	serial, err := middleware.GenerateCertificateSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	certificate := entity.Certificate{
+		Serial:       serial,
+		EnrollmentID: enroll.EnrollmentID,
+		StudentID:    student.UserID,
+		CourseID:     course.CourseID,
+		StudentName:  student.Username,
+		CourseName:   course.CourseName,
+		MentorName:   mentor.Username,
+		CompletedAt:  completedAt,
+	}
+
+	// another request may have issued it first, its certificate is returned either way
+	if err := s.certificateRepo.CreateCertificate(&certificate); err != nil {
+		if existing, err := s.certificateRepo.GetCertificateByEnroll(enroll.EnrollmentID); err == nil {
+			return existing, nil
+		}
+
+		return nil, fmt.Errorf("unable to issue certificate")
+	}
+
+	existing, err := s.certificateRepo.GetCertificateByEnroll(enroll.EnrollmentID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to issue certificate")
+	}
+
+	return existing, nil
+}
+
+// certificate pdf of a completed enrollment (admin, course mentor & the student), dates shown in loc
+func (s *CertificateServiceImpl) GetCertificatePDF(userClaims *middleware.UserClaims, courseID, enrollID string, loc *time.Location) (*entity.Certificate, []byte, error) {
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("course not found")
+	}
+
+	// check if enrollment exist
+	enroll, err := s.enrollRepo.GetEnrollByID(courseID, enrollID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("enrollment not found")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, nil, fmt.Errorf("mentor can only get certificates of their own course")
+	}
+
+	if userClaims.Role == entity.Student && enroll.StudentID != userClaims.UserID {
+		return nil, nil, fmt.Errorf("student can only get their own certificate")
+	}
+
+	if enroll.EnrollStatus != entity.Complete {
+		return nil, nil, fmt.Errorf("certificate is only available for completed enrollment")
+	}
+
+	certificate, err := s.issueCertificate(course, enroll)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if certificate.RevokedAt != nil {
+		return nil, nil, fmt.Errorf("certificate %s has been revoked", certificate.Serial)
+	}
+
+	tmpl, err := middleware.LoadCertificateTemplate()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pdf, err := middleware.RenderCertificatePDF(tmpl, middleware.CertificateData{
+		Serial:      certificate.Serial,
+		StudentName: certificate.StudentName,
+		CourseName:  certificate.CourseName,
+		MentorName:  certificate.MentorName,
+		CompletedAt: certificate.CompletedAt.In(loc),
+		VerifyURL:   middleware.CertificateVerifyURL(certificate.Serial),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certificate, pdf, nil
+}
+
+// public, revoked certificate is returned too so the caller can tell why it's invalid
+func (s *CertificateServiceImpl) VerifyCertificate(serial string) (*entity.Certificate, error) {
+	certificate, err := s.certificateRepo.GetCertificateBySerial(serial)
+	if err != nil {
+		return nil, fmt.Errorf("certificate not found")
+	}
+
+	return certificate, nil
+}
+
+// admin only
+func (s *CertificateServiceImpl) RevokeCertificate(userClaims *middleware.UserClaims, serial, reason string) (*entity.Certificate, error) {
+	if userClaims.Role != entity.Admin {
+		return nil, fmt.Errorf("only admin can revoke certificates")
+	}
+
+	certificate, err := s.certificateRepo.GetCertificateBySerial(serial)
+	if err != nil {
+		return nil, fmt.Errorf("certificate not found")
+	}
+
+	if certificate.RevokedAt != nil {
+		return nil, fmt.Errorf("certificate has already been revoked")
+	}
+
+	now := time.Now()
+	certificate.RevokedAt = &now
+	certificate.RevokeReason = reason
+
+	if err := s.certificateRepo.UpdateCertificate(certificate); err != nil {
+		return nil, fmt.Errorf("unable to revoke certificate")
+	}
+
+	return certificate, nil
+}
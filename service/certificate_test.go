@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/repository"
+	"gorm.io/gorm"
+)
+
+// certificate repo keeping rows in memory, race issues a certificate of another request just before create
+type memoryCertificateRepo struct {
+	repository.CertificateRepo
+	certificates map[uint]entity.Certificate
+	race         bool
+	failCreate   bool
+}
+
+func (r *memoryCertificateRepo) CreateCertificate(certificate *entity.Certificate) error {
+	if r.race {
+		r.certificates[certificate.EnrollmentID] = entity.Certificate{EnrollmentID: certificate.EnrollmentID, Serial: "ISSUED-BY-JOB"}
+	}
+
+	if _, ok := r.certificates[certificate.EnrollmentID]; ok || r.failCreate {
+		return errors.New(`duplicate key value violates unique constraint "idx_certificates_enrollment_id"`)
+	}
+
+	r.certificates[certificate.EnrollmentID] = *certificate
+	return nil
+}
+
+func (r *memoryCertificateRepo) GetCertificateByEnroll(enrollID uint) (*entity.Certificate, error) {
+	certificate, ok := r.certificates[enrollID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &certificate, nil
+}
+
+type memoryUserRepo struct {
+	repository.UserRepo
+}
+
+func (r *memoryUserRepo) GetUserByID(userID string) (*entity.User, error) {
+	return &entity.User{Username: "user" + userID}, nil
+}
+
+type memoryEnrollRepo struct {
+	repository.EnrollRepo
+}
+
+func (r *memoryEnrollRepo) GetEnrollHistories(enrollID uint) ([]entity.EnrollmentHistory, error) {
+	return nil, nil
+}
+
+func TestIssueCertificate(t *testing.T) {
+	course := &entity.Course{CourseID: 1, CourseName: "Go", MentorID: 2}
+	enroll := &entity.Enrollment{EnrollmentID: 3, CourseID: 1, StudentID: 4, EnrollStatus: entity.Complete}
+
+	tests := []struct {
+		name       string
+		repo       *memoryCertificateRepo
+		wantSerial string
+		wantErr    bool
+	}{
+		{name: "first issue", repo: &memoryCertificateRepo{certificates: map[uint]entity.Certificate{}}},
+		{name: "already issued", repo: &memoryCertificateRepo{certificates: map[uint]entity.Certificate{3: {EnrollmentID: 3, Serial: "EXISTING"}}}, wantSerial: "EXISTING"},
+		{name: "issued by another request first", repo: &memoryCertificateRepo{certificates: map[uint]entity.Certificate{}, race: true}, wantSerial: "ISSUED-BY-JOB"},
+		{name: "create fails", repo: &memoryCertificateRepo{certificates: map[uint]entity.Certificate{}, failCreate: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &CertificateServiceImpl{certificateRepo: tt.repo, enrollRepo: &memoryEnrollRepo{}, userRepo: &memoryUserRepo{}}
+
+			certificate, err := service.issueCertificate(course, enroll)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", certificate)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantSerial != "" && certificate.Serial != tt.wantSerial {
+				t.Fatalf("got serial %s, want %s", certificate.Serial, tt.wantSerial)
+			}
+			if certificate.Serial == "" || certificate.EnrollmentID != enroll.EnrollmentID {
+				t.Fatalf("got %+v", certificate)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/nadyafa/go-learn/entity"
 	"github.com/nadyafa/go-learn/middleware"
@@ -15,20 +16,55 @@ type ClassService interface {
 	GetClassByID(courseID, classID string) (*entity.Class, error)
 	UpdateClassByID(userClaims *middleware.UserClaims, courseID, classID string, classReq model.UpdateClass) (*entity.Class, error)
 	DeleteClassByID(userClaims *middleware.UserClaims, courseID, classID string) error
+	GetClassMeeting(userClaims *middleware.UserClaims, courseID, classID string) (*entity.Class, error)
 }
 
 type ClassServiceImpl struct {
-	classRepo  repository.ClassRepo
-	courseRepo repository.CourseRepo
+	classRepo       repository.ClassRepo
+	courseRepo      repository.CourseRepo
+	roomRepo        repository.RoomRepo
+	enrollRepo      repository.EnrollRepo
+	meetingProvider middleware.MeetingProvider
 }
 
-func NewClassService(classRepo repository.ClassRepo, courseRepo repository.CourseRepo) ClassService {
+func NewClassService(classRepo repository.ClassRepo, courseRepo repository.CourseRepo, roomRepo repository.RoomRepo, enrollRepo repository.EnrollRepo, meetingProvider middleware.MeetingProvider) ClassService {
 	return &ClassServiceImpl{
-		classRepo:  classRepo,
-		courseRepo: courseRepo,
+		classRepo:       classRepo,
+		courseRepo:      courseRepo,
+		roomRepo:        roomRepo,
+		enrollRepo:      enrollRepo,
+		meetingProvider: meetingProvider,
 	}
 }
 
+// make sure room exist, fit enrolled students & not booked by another class at the same time
+func (s *ClassServiceImpl) checkRoomBooking(courseID string, roomID uint, startDate, endDate time.Time, excludeClassID uint) error {
+	room, err := s.roomRepo.GetRoomByID(fmt.Sprint(roomID))
+	if err != nil {
+		return fmt.Errorf("room_id %d not found", roomID)
+	}
+
+	enrolled, err := s.enrollRepo.CountCourseEnrolls(courseID, entity.Enroll)
+	if err != nil {
+		return fmt.Errorf("unable to count enrolled students")
+	}
+
+	if enrolled > int64(room.Capacity) {
+		return fmt.Errorf("room %s capacity is %d, but course has %d enrolled students", room.RoomName, room.Capacity, enrolled)
+	}
+
+	bookings, err := s.roomRepo.GetRoomBookings(room.RoomID, startDate, endDate, excludeClassID)
+	if err != nil {
+		return fmt.Errorf("unable to check room availability")
+	}
+
+	if len(bookings) > 0 {
+		return fmt.Errorf("room %s is already booked by class_id %d at that time", room.RoomName, bookings[0].ClassID)
+	}
+
+	return nil
+}
+
 func (s *ClassServiceImpl) CreateClass(userClaims *middleware.UserClaims, courseID string, class model.CreateClass) (*entity.Class, error) {
 	// only admin & mentor
 	if userClaims.Role == entity.Student {
@@ -69,6 +105,26 @@ func (s *ClassServiceImpl) CreateClass(userClaims *middleware.UserClaims, course
 		CourseID:    existingCourse.CourseID,
 	}
 
+	// book physical room
+	if class.RoomID != 0 {
+		if err := s.checkRoomBooking(courseID, class.RoomID, class.StartDate.Time, class.EndDate.Time, 0); err != nil {
+			return nil, err
+		}
+
+		newClass.RoomID = &class.RoomID
+	}
+
+	// generate online meeting link
+	if class.Online {
+		meetingURL, err := s.meetingProvider.CreateMeeting(class.ClassName, class.StartDate.Time, class.EndDate.Time)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create meeting link: %v", err)
+		}
+
+		newClass.MeetingURL = meetingURL
+		newClass.MeetingProvider = s.meetingProvider.Name()
+	}
+
 	// create new class
 	if err := s.classRepo.CreateClass(&newClass); err != nil {
 		return nil, fmt.Errorf("unable to create a new class")
@@ -127,23 +183,52 @@ func (s *ClassServiceImpl) UpdateClassByID(userClaims *middleware.UserClaims, co
 		existingClass.ClassName = classReq.ClassName
 	}
 
-	isValid, errMsg := middleware.ValidateCourseDate(classReq.StartDate.Format("02-01-2006 15:04"), classReq.EndDate.Format("02-01-2006 15:04"))
-	if !isValid {
-		return nil, errMsg
-	}
-
-	if classReq.StartDate.IsZero() {
+	if !classReq.StartDate.IsZero() {
 		existingClass.StartDate = classReq.StartDate.Time
 	}
 
-	if classReq.EndDate.IsZero() {
+	if !classReq.EndDate.IsZero() {
 		existingClass.EndDate = classReq.EndDate.Time
 	}
 
+	isValid, errMsg := middleware.ValidateCourseDate(existingClass.StartDate.Format("02-01-2006 15:04"), existingClass.EndDate.Format("02-01-2006 15:04"))
+	if !isValid {
+		return nil, errMsg
+	}
+
 	if classReq.Description != "" {
 		existingClass.Description = classReq.Description
 	}
 
+	// recheck room booking when room or schedule changes
+	if classReq.RoomID != 0 {
+		existingClass.RoomID = &classReq.RoomID
+	}
+
+	if existingClass.RoomID != nil {
+		if err := s.checkRoomBooking(courseID, *existingClass.RoomID, existingClass.StartDate, existingClass.EndDate, existingClass.ClassID); err != nil {
+			return nil, err
+		}
+	}
+
+	// generate online meeting link if class become online
+	if classReq.Online != nil {
+		if *classReq.Online && existingClass.MeetingURL == "" {
+			meetingURL, err := s.meetingProvider.CreateMeeting(existingClass.ClassName, existingClass.StartDate, existingClass.EndDate)
+			if err != nil {
+				return nil, fmt.Errorf("unable to create meeting link: %v", err)
+			}
+
+			existingClass.MeetingURL = meetingURL
+			existingClass.MeetingProvider = s.meetingProvider.Name()
+		}
+
+		if !*classReq.Online {
+			existingClass.MeetingURL = ""
+			existingClass.MeetingProvider = ""
+		}
+	}
+
 	// update class
 	class, err := s.classRepo.UpdateClassByID(courseID, classID, *existingClass)
 	if err != nil {
@@ -172,3 +257,44 @@ func (s *ClassServiceImpl) DeleteClassByID(userClaims *middleware.UserClaims, co
 
 	return nil
 }
+
+func (s *ClassServiceImpl) GetClassMeeting(userClaims *middleware.UserClaims, courseID, classID string) (*entity.Class, error) {
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	class, err := s.classRepo.GetClassByID(courseID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("class_id %s not found", classID)
+	}
+
+	if class.MeetingURL == "" {
+		return nil, fmt.Errorf("class_id %s has no online meeting", classID)
+	}
+
+	// mentor can only see meeting of their own course
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("you have no access to this class meeting")
+	}
+
+	if userClaims.Role == entity.Student {
+		// make sure student is enrolled in course
+		enroll, err := s.enrollRepo.GetStudentCourseEnroll(courseID, fmt.Sprint(userClaims.UserID))
+		if err != nil || enroll.EnrollStatus != entity.Enroll {
+			return nil, fmt.Errorf("student not enroll to course")
+		}
+
+		// link only revealed shortly before class start
+		now := time.Now()
+		if now.Before(class.StartDate.Add(-middleware.MeetingRevealWindow)) {
+			return nil, fmt.Errorf("meeting link will be available %s before class start", middleware.MeetingRevealWindow)
+		}
+
+		if now.After(class.EndDate) {
+			return nil, fmt.Errorf("class has ended")
+		}
+	}
+
+	return class, nil
+}
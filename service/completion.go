@@ -0,0 +1,271 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+)
+
+type CompletionService interface {
+	GetCompletionRule(userClaims *middleware.UserClaims, courseID string) (*entity.CompletionRule, error)
+	SetCompletionRule(userClaims *middleware.UserClaims, courseID string, ruleReq model.CompletionRuleReq) (*entity.CompletionRule, error)
+	PreviewCompletion(userClaims *middleware.UserClaims, courseID string) (*model.CompletionResult, error)
+	EvaluateCompletion(userClaims *middleware.UserClaims, courseID string) (*model.CompletionResult, error)
+	EvaluateEndedCourses() (int, error)
+}
+
+type CompletionServiceImpl struct {
+	completionRepo repository.CompletionRepo
+	courseRepo     repository.CourseRepo
+	enrollRepo     repository.EnrollRepo
+	validator      *validator.Validate
+}
+
+func NewCompletionService(completionRepo repository.CompletionRepo, courseRepo repository.CourseRepo, enrollRepo repository.EnrollRepo) CompletionService {
+	return &CompletionServiceImpl{
+		completionRepo: completionRepo,
+		courseRepo:     courseRepo,
+		enrollRepo:     enrollRepo,
+		validator:      validator.New(),
+	}
+}
+
+// admin & course mentor only
+func (s *CompletionServiceImpl) checkCompletionAccess(userClaims *middleware.UserClaims, courseID string) (*entity.Course, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can manage course completion")
+	}
+
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("mentor can only manage completion of their own course")
+	}
+
+	return course, nil
+}
+
+// rules are visible for all so students know the criteria
+func (s *CompletionServiceImpl) GetCompletionRule(userClaims *middleware.UserClaims, courseID string) (*entity.CompletionRule, error) {
+	if _, err := s.courseRepo.GetCourseByID(courseID); err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	rule, err := s.completionRepo.GetCompletionRule(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course has no completion rules")
+	}
+
+	return rule, nil
+}
+
+func (s *CompletionServiceImpl) SetCompletionRule(userClaims *middleware.UserClaims, courseID string, ruleReq model.CompletionRuleReq) (*entity.CompletionRule, error) {
+	course, err := s.checkCompletionAccess(userClaims, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validator.Struct(ruleReq); err != nil {
+		return nil, fmt.Errorf("min_attendance_rate & min_average_score must be between 0 and 100")
+	}
+
+	// changing rules of an ended course lets it be evaluated again
+	rule := entity.CompletionRule{
+		CourseID:           course.CourseID,
+		MinAttendanceRate:  ruleReq.MinAttendanceRate,
+		MinAverageScore:    ruleReq.MinAverageScore,
+		RequireAllProjects: ruleReq.RequireAllProjects,
+	}
+
+	if err := s.completionRepo.SaveCompletionRule(&rule); err != nil {
+		return nil, fmt.Errorf("unable to save completion rules")
+	}
+
+	return &rule, nil
+}
+
+// check student figures against the rules
+func evaluateCompletion(rule *entity.CompletionRule, stats model.CompletionStats) model.CompletionOutcome {
+	outcome := model.CompletionOutcome{
+		CompletionStats: stats,
+		Result:          entity.Complete,
+	}
+
+	check := func(criterion string, required, actual float64, format string) {
+		passed := actual >= required
+		outcome.Criteria = append(outcome.Criteria, model.CompletionCriterion{
+			Criterion: criterion,
+			Required:  required,
+			Actual:    actual,
+			Passed:    passed,
+		})
+
+		if !passed {
+			outcome.Result = entity.Failed
+			outcome.Reasons = append(outcome.Reasons, fmt.Sprintf(format, actual, required))
+		}
+	}
+
+	if rule.MinAttendanceRate > 0 {
+		check("attendance_rate", rule.MinAttendanceRate, stats.AttendanceRate, "attendance rate %.2f%% is below %.2f%%")
+	}
+
+	if rule.MinAverageScore > 0 {
+		check("average_score", rule.MinAverageScore, stats.AverageScore, "average score %.2f is below %.2f")
+	}
+
+	if rule.RequireAllProjects {
+		check("submitted_projects", float64(stats.RequiredProjects), float64(stats.SubmittedProjects), "submitted %.0f of %.0f required projects")
+	}
+
+	if outcome.Result == entity.Complete {
+		outcome.Reasons = []string{"all completion criteria are met"}
+	}
+
+	return outcome
+}
+
+// outcome of every enrolled student without applying it
+func (s *CompletionServiceImpl) evaluateCourse(course *entity.Course) (*model.CompletionResult, *entity.CompletionRule, error) {
+	rule, err := s.completionRepo.GetCompletionRule(fmt.Sprint(course.CourseID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("course has no completion rules")
+	}
+
+	stats, err := s.completionRepo.GetCompletionStats(fmt.Sprint(course.CourseID), time.Now())
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get completion figures")
+	}
+
+	result := &model.CompletionResult{
+		CourseID: course.CourseID,
+		Outcomes: []model.CompletionOutcome{},
+	}
+
+	for _, stat := range stats {
+		outcome := evaluateCompletion(rule, stat)
+		if outcome.Result == entity.Complete {
+			result.Completed++
+		} else {
+			result.Failed++
+		}
+
+		result.Outcomes = append(result.Outcomes, outcome)
+	}
+
+	return result, rule, nil
+}
+
+func (s *CompletionServiceImpl) PreviewCompletion(userClaims *middleware.UserClaims, courseID string) (*model.CompletionResult, error) {
+	course, err := s.checkCompletionAccess(userClaims, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, _, err := s.evaluateCourse(course)
+	return result, err
+}
+
+// move every enrolled student to complete or failed
+func (s *CompletionServiceImpl) applyCompletion(actorID uint, actorRole entity.Role, course *entity.Course) (*model.CompletionResult, error) {
+	result, rule, err := s.evaluateCourse(course)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, outcome := range result.Outcomes {
+		enroll, err := s.enrollRepo.GetEnrollByID(fmt.Sprint(course.CourseID), fmt.Sprint(outcome.EnrollmentID))
+		if err != nil || enroll.EnrollStatus != entity.Enroll {
+			continue
+		}
+
+		history := entity.EnrollmentHistory{
+			FromStatus: enroll.EnrollStatus,
+			ToStatus:   outcome.Result,
+			ActorID:    actorID,
+			ActorRole:  actorRole,
+			Reason:     strings.Join(outcome.Reasons, "; "),
+		}
+
+		enroll.EnrollStatus = outcome.Result
+		if err := s.enrollRepo.UpdateEnrollStatus(enroll, history); err != nil {
+			return nil, fmt.Errorf("unable to update enrollmentID %d", enroll.EnrollmentID)
+		}
+
+		// notify student
+		message := fmt.Sprintf("Your result for course %s is %s. %s.", course.CourseName, outcome.Result, history.Reason)
+		if outcome.Result == entity.Complete {
+			message += fmt.Sprintf(" Download your certificate at %s", middleware.CertificateDownloadURL(course.CourseID, enroll.EnrollmentID))
+		}
+
+		if err := middleware.SendMail(
+			outcome.Email,
+			"Go-Learn: Course Result",
+			message,
+		); err != nil {
+			log.Println("Error sending course result:", err)
+		}
+	}
+
+	now := time.Now()
+	rule.EvaluatedAt = &now
+	if err := s.completionRepo.SaveCompletionRule(rule); err != nil {
+		return nil, fmt.Errorf("unable to save completion evaluation")
+	}
+
+	result.Applied = true
+	return result, nil
+}
+
+// evaluate on demand (admin & course mentor)
+func (s *CompletionServiceImpl) EvaluateCompletion(userClaims *middleware.UserClaims, courseID string) (*model.CompletionResult, error) {
+	course, err := s.checkCompletionAccess(userClaims, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, status := range []entity.Status{entity.Complete, entity.Failed} {
+		if !slices.Contains(entity.Enroll.TransitionRoles(status), userClaims.Role) {
+			return nil, fmt.Errorf("%s can't move enrollment from %s to %s", userClaims.Role, entity.Enroll, status)
+		}
+	}
+
+	return s.applyCompletion(userClaims.UserID, userClaims.Role, course)
+}
+
+// evaluate courses that have ended, run by background job
+func (s *CompletionServiceImpl) EvaluateEndedCourses() (int, error) {
+	rules, err := s.completionRepo.GetRulesToEvaluate(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	evaluated := 0
+	for _, rule := range rules {
+		course, err := s.courseRepo.GetCourseByID(fmt.Sprint(rule.CourseID))
+		if err != nil {
+			continue
+		}
+
+		if _, err := s.applyCompletion(0, entity.SystemActor, course); err != nil {
+			log.Printf("Error evaluating courseID %d: %v", course.CourseID, err)
+			continue
+		}
+
+		evaluated++
+	}
+
+	return evaluated, nil
+}
@@ -10,6 +10,12 @@ import (
 	"github.com/nadyafa/go-learn/repository"
 )
 
+// minutes after class start before a check-in counts as late, when the course does not set it
+const DefaultLateThresholdMinutes = 15
+
+// days after course start a student can still drop on their own, when the course does not set it
+const DefaultDropDeadlineDays = 7
+
 type CourseService interface {
 	CreateCourse(userClaims *middleware.UserClaims, courseReq model.CourseReq) (*entity.Course, error)
 	GetCourses() ([]entity.Course, error)
@@ -45,6 +51,24 @@ func (s *CourseServiceImpl) CreateCourse(userClaims *middleware.UserClaims, cour
 		return nil, errMsg
 	}
 
+	// omitted threshold falls back to the default, an explicit 0 marks late right at start
+	lateThreshold := DefaultLateThresholdMinutes
+	if courseReq.LateThresholdMinutes != nil {
+		if *courseReq.LateThresholdMinutes < 0 {
+			return nil, fmt.Errorf("late_threshold_minutes cannot be negative")
+		}
+		lateThreshold = *courseReq.LateThresholdMinutes
+	}
+
+	// an explicit 0 closes self-drop once the course starts
+	dropDeadline := DefaultDropDeadlineDays
+	if courseReq.DropDeadlineDays != nil {
+		if *courseReq.DropDeadlineDays < 0 {
+			return nil, fmt.Errorf("drop_deadline_days cannot be negative")
+		}
+		dropDeadline = *courseReq.DropDeadlineDays
+	}
+
 	if courseReq.MentorID == 0 {
 		if userClaims.Role == entity.Mentor {
 			courseReq.MentorID = userClaims.UserID
@@ -55,11 +79,13 @@ func (s *CourseServiceImpl) CreateCourse(userClaims *middleware.UserClaims, cour
 
 	// create course entity to repo layer
 	course := entity.Course{
-		CourseName:  courseReq.CourseName,
-		Description: courseReq.Description,
-		MentorID:    courseReq.MentorID,
-		StartDate:   courseReq.StartDate.Time,
-		EndDate:     courseReq.EndDate.Time,
+		CourseName:           courseReq.CourseName,
+		Description:          courseReq.Description,
+		MentorID:             courseReq.MentorID,
+		LateThresholdMinutes: lateThreshold,
+		DropDeadlineDays:     dropDeadline,
+		StartDate:            courseReq.StartDate.Time,
+		EndDate:              courseReq.EndDate.Time,
 	}
 
 	if err := s.courseRepo.CreateCourse(&course); err != nil {
@@ -118,6 +144,20 @@ func (s *CourseServiceImpl) UpdateCourseByID(userClaims *middleware.UserClaims,
 		existingCourse.MentorID = courseReq.MentorID
 	}
 
+	if courseReq.LateThresholdMinutes != nil {
+		if *courseReq.LateThresholdMinutes < 0 {
+			return nil, fmt.Errorf("late_threshold_minutes cannot be negative")
+		}
+		existingCourse.LateThresholdMinutes = *courseReq.LateThresholdMinutes
+	}
+
+	if courseReq.DropDeadlineDays != nil {
+		if *courseReq.DropDeadlineDays < 0 {
+			return nil, fmt.Errorf("drop_deadline_days cannot be negative")
+		}
+		existingCourse.DropDeadlineDays = *courseReq.DropDeadlineDays
+	}
+
 	if !courseReq.StartDate.IsZero() {
 		existingCourse.StartDate = courseReq.StartDate.Time
 	}
@@ -1,35 +1,58 @@
 package service
 
 import (
+	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/nadyafa/go-learn/entity"
 	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
 	"github.com/nadyafa/go-learn/repository"
+	"github.com/nadyafa/go-learn/storage"
 )
 
 type EnrollService interface {
-	StudentEnroll(userClaims *middleware.UserClaims, courseID, studentID string) (*entity.Enrollment, error)
-	UpdateStudentEnroll(userClaims *middleware.UserClaims, courseID, studentID string, enrollStatus entity.Status) (*entity.Enrollment, error)
+	StudentEnroll(userClaims *middleware.UserClaims, courseID, studentID, inviteCode string) (*entity.Enrollment, error)
+	JoinByInvite(userClaims *middleware.UserClaims, inviteCode string) (*entity.Enrollment, error)
+	UpdateStudentEnroll(userClaims *middleware.UserClaims, courseID, enrollID string, enrollStatus entity.Status, reason string) (*entity.Enrollment, error)
+	GetEnrollHistories(userClaims *middleware.UserClaims, courseID, enrollID string) ([]entity.EnrollmentHistory, error)
+	GetCourseEnrolls(userClaims *middleware.UserClaims, courseID string, filter model.EnrollFilter) ([]model.EnrollDetail, int64, error)
+	GetMyEnrolls(userClaims *middleware.UserClaims, filter model.EnrollFilter) ([]model.EnrollDetail, int64, error)
+	SearchEnrolls(userClaims *middleware.UserClaims, filter model.EnrollFilter) ([]model.EnrollDetail, int64, error)
+	DropEnroll(userClaims *middleware.UserClaims, courseID, enrollID, reason string) (*entity.Enrollment, bool, error)
+	ReviewDropEnroll(userClaims *middleware.UserClaims, courseID, enrollID string, approve bool, reason string) (*entity.Enrollment, error)
+	ImportEnrolls(userClaims *middleware.UserClaims, courseID string, file io.Reader, enrollStatus entity.Status, dryRun bool) (*model.EnrollImportResult, error)
 }
 
 type EnrollServiceImpl struct {
 	courseRepo repository.CourseRepo
 	enrollRepo repository.EnrollRepo
 	userRepo   repository.UserRepo
+	inviteRepo repository.InviteRepo
+	store      storage.Storage
 }
 
-func NewEnrollService(courseRepo repository.CourseRepo, enrollRepo repository.EnrollRepo, userRepo repository.UserRepo) EnrollService {
+func NewEnrollService(courseRepo repository.CourseRepo, enrollRepo repository.EnrollRepo, userRepo repository.UserRepo, inviteRepo repository.InviteRepo, store storage.Storage) EnrollService {
 	return &EnrollServiceImpl{
 		courseRepo: courseRepo,
 		enrollRepo: enrollRepo,
 		userRepo:   userRepo,
+		inviteRepo: inviteRepo,
+		store:      store,
 	}
 }
 
-func (s *EnrollServiceImpl) StudentEnroll(userClaims *middleware.UserClaims, courseID, studentID string) (*entity.Enrollment, error) {
+// enroll as pending, or straight to enroll when a valid invite code is given
+func (s *EnrollServiceImpl) StudentEnroll(userClaims *middleware.UserClaims, courseID, studentID, inviteCode string) (*entity.Enrollment, error) {
 	// user & admin only
 	if userClaims.Role == entity.Mentor {
 		return nil, fmt.Errorf("you can't perform this action")
@@ -56,11 +79,15 @@ func (s *EnrollServiceImpl) StudentEnroll(userClaims *middleware.UserClaims, cou
 	}
 
 	// check if student already enroll to a course
-	existingEnroll, err := s.enrollRepo.GetStudentCourseEnroll(studentID, courseID)
+	existingEnroll, err := s.enrollRepo.GetStudentCourseEnroll(courseID, studentID)
 	if err == nil {
 		return nil, fmt.Errorf("student has enroll with enrollment_id %d", existingEnroll.EnrollmentID)
 	}
 
+	if inviteCode != "" {
+		return s.redeemInvite(userClaims, course, userExist, inviteCode)
+	}
+
 	// create student enrollment entity
 	enroll := entity.Enrollment{
 		StudentID:    userExist.UserID,
@@ -68,8 +95,14 @@ func (s *EnrollServiceImpl) StudentEnroll(userClaims *middleware.UserClaims, cou
 		EnrollStatus: entity.Pending,
 	}
 
+	history := entity.EnrollmentHistory{
+		ToStatus:  entity.Pending,
+		ActorID:   userClaims.UserID,
+		ActorRole: userClaims.Role,
+	}
+
 	// enroll student
-	newEnroll, err := s.enrollRepo.StudentEnroll(enroll)
+	newEnroll, err := s.enrollRepo.StudentEnroll(enroll, history)
 	if err != nil {
 		return nil, fmt.Errorf("student unable to enroll")
 	}
@@ -99,56 +132,703 @@ func (s *EnrollServiceImpl) StudentEnroll(userClaims *middleware.UserClaims, cou
 	return newEnroll, nil
 }
 
-func (s *EnrollServiceImpl) UpdateStudentEnroll(userClaims *middleware.UserClaims, courseID, studentID string, enrollStatus entity.Status) (*entity.Enrollment, error) {
-	// admin only
-	if userClaims.Role != entity.Admin {
-		return nil, fmt.Errorf("only admin can update enrollment data")
+// move an enrollment to a new status following the allowed transitions
+func (s *EnrollServiceImpl) UpdateStudentEnroll(userClaims *middleware.UserClaims, courseID, enrollID string, enrollStatus entity.Status, reason string) (*entity.Enrollment, error) {
+	if !enrollStatus.IsValid() {
+		return nil, fmt.Errorf("invalid enroll_status %q", enrollStatus)
 	}
 
 	// check if courseID exist
-	if _, err := s.courseRepo.GetCourseByID(courseID); err != nil {
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
 		return nil, fmt.Errorf("course not found")
 	}
 
-	// check if user exist
-	userExist, err := s.userRepo.GetUserByID(studentID)
+	// check if enrollment exist
+	enroll, err := s.enrollRepo.GetEnrollByID(courseID, enrollID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("enrollment not found")
 	}
 
-	// check if student already enroll to a course
-	existingEnroll, err := s.enrollRepo.GetStudentCourseEnroll(studentID, courseID)
+	// mentor can only update their own course & student their own enrollment
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("mentor can only update enrollments of their own course")
+	}
+
+	if userClaims.Role == entity.Student && enroll.StudentID != userClaims.UserID {
+		return nil, fmt.Errorf("student can only update their own enrollment")
+	}
+
+	// validate transition
+	if enroll.EnrollStatus == enrollStatus {
+		return nil, fmt.Errorf("enrollment is already %s", enrollStatus)
+	}
+
+	roles := enroll.EnrollStatus.TransitionRoles(enrollStatus)
+	if roles == nil {
+		return nil, fmt.Errorf("enrollment can't move from %s to %s", enroll.EnrollStatus, enrollStatus)
+	}
+
+	if !slices.Contains(roles, userClaims.Role) {
+		return nil, fmt.Errorf("%s can't move enrollment from %s to %s", userClaims.Role, enroll.EnrollStatus, enrollStatus)
+	}
+
+	// past the drop deadline student has to request a drop
+	if userClaims.Role == entity.Student && enroll.EnrollStatus == entity.Enroll && enrollStatus == entity.Cancel && time.Now().After(course.DropDeadline()) {
+		return nil, fmt.Errorf("drop deadline has passed, please submit a drop request")
+	}
+
+	if err := s.changeEnrollStatus(userClaims, course, enroll, enrollStatus, reason); err != nil {
+		return nil, err
+	}
+
+	return enroll, nil
+}
+
+// save the transition, cleaning up course data when the enrollment is cancelled
+func (s *EnrollServiceImpl) changeEnrollStatus(userClaims *middleware.UserClaims, course *entity.Course, enroll *entity.Enrollment, enrollStatus entity.Status, reason string) error {
+	// check if user exist
+	userExist, err := s.userRepo.GetUserByID(fmt.Sprint(enroll.StudentID))
 	if err != nil {
-		return nil, fmt.Errorf("student hasn't enroll to a course. courseid: %s, studentid: %s. err: %v", courseID, studentID, err.Error())
+		return fmt.Errorf("user not found")
 	}
 
-	// create student enrollment entity
-	updateEnroll := entity.Enrollment{
-		EnrollmentID:   existingEnroll.EnrollmentID,
-		StudentID:      existingEnroll.StudentID,
-		CourseID:       existingEnroll.CourseID,
-		EnrollmentDate: time.Now(),
-		EnrollStatus:   enrollStatus,
-		CreatedAt:      existingEnroll.CreatedAt,
-		UpdatedAt:      time.Now(),
+	history := entity.EnrollmentHistory{
+		FromStatus: enroll.EnrollStatus,
+		ToStatus:   enrollStatus,
+		ActorID:    userClaims.UserID,
+		ActorRole:  userClaims.Role,
+		Reason:     reason,
+	}
+
+	if enrollStatus == entity.Enroll {
+		enroll.EnrollmentDate = time.Now()
 	}
+	wasEnrolled := enroll.EnrollStatus == entity.Enroll
+	enroll.EnrollStatus = enrollStatus
+	enroll.DropRequestedAt = nil
+	enroll.DropReason = ""
 
 	// update enrollmentStatus
-	enroll, err := s.enrollRepo.UpdateStudentEnroll(courseID, studentID, updateEnroll)
+	if enrollStatus == entity.Cancel {
+		fileKeys, err := s.enrollRepo.CancelEnroll(enroll, history, time.Now())
+		if err != nil {
+			return fmt.Errorf("unable to cancel student enrollment")
+		}
+
+		// stored files of removed excuses & submissions
+		for _, fileKey := range fileKeys {
+			if err := s.store.Delete(context.Background(), fileKey); err != nil {
+				log.Println("Error removing file:", err)
+			}
+		}
+	} else {
+		if err := s.enrollRepo.UpdateEnrollStatus(enroll, history); err != nil {
+			return fmt.Errorf("unable to update student status enrollment")
+		}
+	}
+
+	// a seat opened for the waitlist
+	if wasEnrolled && enrollStatus == entity.Cancel {
+		s.notifyWaitlist(course)
+	}
+
+	// notify student
+	message := fmt.Sprintf("Your enrollment status for courseID %d has changed from %s to %s.", course.CourseID, history.FromStatus, history.ToStatus)
+	if reason != "" {
+		message += fmt.Sprintf(" Reason: %s", reason)
+	}
+
+	if enrollStatus == entity.Complete {
+		message += fmt.Sprintf(" Download your certificate at %s", middleware.CertificateDownloadURL(course.CourseID, enroll.EnrollmentID))
+	}
+
+	if err := middleware.SendMail(
+		userExist.Email,
+		"Go-Learn: Course Enrollment",
+		message,
+	); err != nil {
+		log.Println("Error notifying enrollment status:", err)
+	}
+
+	return nil
+}
+
+// pending enrollments are the course waitlist, first in line & admin are told a seat opened
+func (s *EnrollServiceImpl) notifyWaitlist(course *entity.Course) {
+	waitlist, err := s.enrollRepo.GetCourseEnrolls(fmt.Sprint(course.CourseID), entity.Pending)
+	if err != nil || len(waitlist) == 0 {
+		return
+	}
+
+	next := waitlist[0]
+	student, err := s.userRepo.GetUserByID(fmt.Sprint(next.StudentID))
 	if err != nil {
-		return nil, fmt.Errorf("unable to update student status enrollment")
+		return
+	}
+
+	if err := middleware.SendMail(
+		student.Email,
+		"Go-Learn: A Seat Opened",
+		fmt.Sprintf("A seat has opened in course %s. Your enrollment is next in line and will be reviewed soon.", course.CourseName),
+	); err != nil {
+		log.Println("Error notifying waitlist:", err)
+	}
+
+	if err := middleware.SendMail(
+		os.Getenv("ADMIN_EMAIL"),
+		"A Course Seat Opened",
+		fmt.Sprintf("A student dropped courseID %d. EnrollmentID %d (userID %d) is next on the waitlist.", course.CourseID, next.EnrollmentID, next.StudentID),
+	); err != nil {
+		log.Println("Error notifying admin:", err)
+	}
+}
+
+// student drops their own enrollment, past the drop deadline it waits for admin approval
+func (s *EnrollServiceImpl) DropEnroll(userClaims *middleware.UserClaims, courseID, enrollID, reason string) (*entity.Enrollment, bool, error) {
+	if userClaims.Role != entity.Student {
+		return nil, false, fmt.Errorf("only student can drop their enrollment")
+	}
+
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, false, fmt.Errorf("course not found")
+	}
+
+	// check if enrollment exist
+	enroll, err := s.enrollRepo.GetEnrollByID(courseID, enrollID)
+	if err != nil || enroll.StudentID != userClaims.UserID {
+		return nil, false, fmt.Errorf("enrollment not found")
+	}
+
+	if enroll.EnrollStatus != entity.Pending && enroll.EnrollStatus != entity.Enroll {
+		return nil, false, fmt.Errorf("enrollment can't move from %s to %s", enroll.EnrollStatus, entity.Cancel)
+	}
+
+	// drop right away
+	if enroll.EnrollStatus == entity.Pending || !time.Now().After(course.DropDeadline()) {
+		if err := s.changeEnrollStatus(userClaims, course, enroll, entity.Cancel, reason); err != nil {
+			return nil, false, err
+		}
+
+		return enroll, true, nil
+	}
+
+	if enroll.DropRequestedAt != nil {
+		return nil, false, fmt.Errorf("drop request is already waiting for admin approval")
+	}
+
+	if reason == "" {
+		return nil, false, fmt.Errorf("reason is required after the drop deadline")
+	}
+
+	now := time.Now()
+	enroll.DropRequestedAt = &now
+	enroll.DropReason = reason
+
+	if err := s.enrollRepo.UpdateEnroll(enroll); err != nil {
+		return nil, false, fmt.Errorf("unable to request enrollment drop")
+	}
+
+	// notify admin
+	if err := middleware.SendMail(
+		os.Getenv("ADMIN_EMAIL"),
+		"An Enrollment Drop Request",
+		fmt.Sprintf("UserID %d requested to drop courseID %s (enrollmentID %d) after the drop deadline. Reason: %s", enroll.StudentID, courseID, enroll.EnrollmentID, reason),
+	); err != nil {
+		log.Println("Error notifying drop request:", err)
+	}
+
+	return enroll, false, nil
+}
+
+// admin approves or rejects a late drop request
+func (s *EnrollServiceImpl) ReviewDropEnroll(userClaims *middleware.UserClaims, courseID, enrollID string, approve bool, reason string) (*entity.Enrollment, error) {
+	if userClaims.Role != entity.Admin {
+		return nil, fmt.Errorf("only admin can review drop requests")
+	}
+
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	// check if enrollment exist
+	enroll, err := s.enrollRepo.GetEnrollByID(courseID, enrollID)
+	if err != nil {
+		return nil, fmt.Errorf("enrollment not found")
+	}
+
+	if enroll.DropRequestedAt == nil {
+		return nil, fmt.Errorf("enrollment has no drop request")
+	}
+
+	if approve {
+		if reason == "" {
+			reason = enroll.DropReason
+		}
+
+		if err := s.changeEnrollStatus(userClaims, course, enroll, entity.Cancel, reason); err != nil {
+			return nil, err
+		}
+
+		return enroll, nil
+	}
+
+	enroll.DropRequestedAt = nil
+	enroll.DropReason = ""
+
+	if err := s.enrollRepo.UpdateEnroll(enroll); err != nil {
+		return nil, fmt.Errorf("unable to reject drop request")
 	}
 
 	// notify student
+	student, err := s.userRepo.GetUserByID(fmt.Sprint(enroll.StudentID))
+	if err != nil {
+		log.Println("Error notifying drop rejection:", err)
+		return enroll, nil
+	}
+
+	message := fmt.Sprintf("Your drop request for course %s has been rejected.", course.CourseName)
+	if reason != "" {
+		message += fmt.Sprintf(" Reason: %s", reason)
+	}
+
+	if err := middleware.SendMail(
+		student.Email,
+		"Go-Learn: Drop Request",
+		message,
+	); err != nil {
+		log.Println("Error notifying drop rejection:", err)
+	}
+
+	return enroll, nil
+}
+
+// admin, course mentor & the enrolled student
+func (s *EnrollServiceImpl) GetEnrollHistories(userClaims *middleware.UserClaims, courseID, enrollID string) ([]entity.EnrollmentHistory, error) {
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	// check if enrollment exist
+	enroll, err := s.enrollRepo.GetEnrollByID(courseID, enrollID)
+	if err != nil {
+		return nil, fmt.Errorf("enrollment not found")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("mentor can only see enrollments of their own course")
+	}
+
+	if userClaims.Role == entity.Student && enroll.StudentID != userClaims.UserID {
+		return nil, fmt.Errorf("student can only see their own enrollment")
+	}
+
+	histories, err := s.enrollRepo.GetEnrollHistories(enroll.EnrollmentID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get enrollment history")
+	}
+
+	return histories, nil
+}
+
+func checkEnrollFilter(filter model.EnrollFilter) error {
+	if filter.Status != "" && !filter.Status.IsValid() {
+		return fmt.Errorf("invalid status %q", filter.Status)
+	}
+
+	return nil
+}
+
+// course roster (admin & course mentor)
+func (s *EnrollServiceImpl) GetCourseEnrolls(userClaims *middleware.UserClaims, courseID string, filter model.EnrollFilter) ([]model.EnrollDetail, int64, error) {
 	if userClaims.Role == entity.Student {
-		if err := middleware.SendMail(
-			userExist.Email,
-			"Go-Learn: Course Enrollment",
-			fmt.Sprintf("You have successfully signed to a courseID %s. You enrollment status currently on pending. We will soon notified you once it verified. Good luck!", courseID),
-		); err != nil {
-			return nil, fmt.Errorf("failed to send notification to student: %v", err)
+		return nil, 0, fmt.Errorf("only admin & mentor can see course enrollments")
+	}
+
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("course not found")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, 0, fmt.Errorf("mentor can only see enrollments of their own course")
+	}
+
+	if err := checkEnrollFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	filter.CourseID = course.CourseID
+	enrolls, total, err := s.enrollRepo.SearchEnrolls(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to get course enrollments")
+	}
+
+	return enrolls, total, nil
+}
+
+// enrollments of the signed in user
+func (s *EnrollServiceImpl) GetMyEnrolls(userClaims *middleware.UserClaims, filter model.EnrollFilter) ([]model.EnrollDetail, int64, error) {
+	if err := checkEnrollFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	filter.StudentID = userClaims.UserID
+	enrolls, total, err := s.enrollRepo.SearchEnrolls(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to get enrollments")
+	}
+
+	return enrolls, total, nil
+}
+
+// search all enrollments (admin only)
+func (s *EnrollServiceImpl) SearchEnrolls(userClaims *middleware.UserClaims, filter model.EnrollFilter) ([]model.EnrollDetail, int64, error) {
+	if userClaims.Role != entity.Admin {
+		return nil, 0, fmt.Errorf("only admin can search enrollments")
+	}
+
+	if err := checkEnrollFilter(filter); err != nil {
+		return nil, 0, err
+	}
+
+	enrolls, total, err := s.enrollRepo.SearchEnrolls(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to search enrollments")
+	}
+
+	return enrolls, total, nil
+}
+
+// read username & email columns of the import csv, header is required and column order is free
+func readEnrollImportCSV(file io.Reader) ([]model.EnrollImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("csv file is empty or invalid")
+	}
+
+	usernameCol, emailCol := -1, -1
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))) {
+		case "username":
+			usernameCol = i
+		case "email":
+			emailCol = i
 		}
 	}
 
-	return enroll, nil
+	if usernameCol < 0 || emailCol < 0 {
+		return nil, fmt.Errorf("csv header must contain username and email columns")
+	}
+
+	var rows []model.EnrollImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv at line %d: %v", line, err)
+		}
+
+		row := model.EnrollImportRow{Row: line}
+		if usernameCol < len(record) {
+			row.Username = strings.TrimSpace(record[usernameCol])
+		}
+		if emailCol < len(record) {
+			row.Email = strings.ToLower(strings.TrimSpace(record[emailCol]))
+		}
+
+		// skip blank lines
+		if row.Username == "" && row.Email == "" {
+			continue
+		}
+
+		rows = append(rows, row)
+		if len(rows) > model.MaxEnrollImportRows {
+			return nil, fmt.Errorf("csv can't have more than %d rows", model.MaxEnrollImportRows)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("csv has no rows to import")
+	}
+
+	return rows, nil
+}
+
+// enroll students from csv, creating missing users (admin only)
+func (s *EnrollServiceImpl) ImportEnrolls(userClaims *middleware.UserClaims, courseID string, file io.Reader, enrollStatus entity.Status, dryRun bool) (*model.EnrollImportResult, error) {
+	if userClaims.Role != entity.Admin {
+		return nil, fmt.Errorf("only admin can import enrollments")
+	}
+
+	if enrollStatus == "" {
+		enrollStatus = entity.Pending
+	}
+
+	// new enrollment starts as pending or enroll
+	if enrollStatus != entity.Pending && enrollStatus != entity.Enroll {
+		return nil, fmt.Errorf("enroll_status must be %s or %s", entity.Pending, entity.Enroll)
+	}
+
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	rows, err := readEnrollImportCSV(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// look up existing users & enrollments at once
+	var emails, usernames []string
+	for _, row := range rows {
+		emails = append(emails, row.Email)
+		usernames = append(usernames, row.Username)
+	}
+
+	usersByEmail := map[string]entity.User{}
+	users, err := s.userRepo.GetUsersByEmails(emails)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check existing users")
+	}
+	for _, user := range users {
+		usersByEmail[strings.ToLower(user.Email)] = user
+	}
+
+	takenUsernames := map[string]bool{}
+	users, err = s.userRepo.GetUsersByUsernames(usernames)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check existing users")
+	}
+	for _, user := range users {
+		takenUsernames[user.Username] = true
+	}
+
+	enrolledStudents := map[uint]bool{}
+	studentIDs, err := s.enrollRepo.GetCourseStudentIDs(course.CourseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check existing enrollments")
+	}
+	for _, studentID := range studentIDs {
+		enrolledStudents[studentID] = true
+	}
+
+	// validate every row
+	validate := validator.New()
+	seenEmails := map[string]int{}
+	seenUsernames := map[string]int{}
+
+	result := &model.EnrollImportResult{
+		DryRun:       dryRun,
+		EnrollStatus: enrollStatus,
+		TotalRows:    len(rows),
+	}
+
+	for i := range rows {
+		row := &rows[i]
+
+		if err := validate.Var(row.Email, "required,email"); err != nil {
+			row.Errors = append(row.Errors, "invalid email")
+		} else if firstRow, ok := seenEmails[row.Email]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("duplicate email of row %d", firstRow))
+		} else {
+			seenEmails[row.Email] = row.Row
+		}
+
+		if user, ok := usersByEmail[row.Email]; ok {
+			row.UserID = user.UserID
+			if user.Role != entity.Student {
+				row.Errors = append(row.Errors, fmt.Sprintf("user is a %s, only students can be enrolled", user.Role))
+			}
+			if enrolledStudents[user.UserID] {
+				row.Errors = append(row.Errors, "user has already enrolled to this course")
+			}
+		} else {
+			row.NewUser = true
+
+			if err := validate.Var(row.Username, "required,alphanum,min=6,max=100"); err != nil {
+				row.Errors = append(row.Errors, "username must be at least 6 characters alphanumerical")
+			} else if takenUsernames[row.Username] {
+				row.Errors = append(row.Errors, "username is taken")
+			} else if firstRow, ok := seenUsernames[row.Username]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("duplicate username of row %d", firstRow))
+			} else {
+				seenUsernames[row.Username] = row.Row
+			}
+		}
+
+		if len(row.Errors) > 0 {
+			result.InvalidRows++
+		}
+	}
+
+	result.Rows = rows
+
+	// nothing is imported when a row is invalid or on dry run
+	if result.InvalidRows > 0 || dryRun {
+		return result, nil
+	}
+
+	now := time.Now()
+	imports := make([]model.EnrollImport, 0, len(rows))
+	passwordTokens := map[int]string{}
+	tokenExpiresAt := now.Add(PasswordTokenTTL)
+
+	for _, row := range rows {
+		enroll := entity.Enrollment{
+			CourseID:     course.CourseID,
+			EnrollStatus: enrollStatus,
+		}
+		if enrollStatus == entity.Enroll {
+			enroll.EnrollmentDate = now
+		}
+
+		if !row.NewUser {
+			user := usersByEmail[row.Email]
+			imports = append(imports, model.EnrollImport{User: &user, Enrollment: enroll})
+			continue
+		}
+
+		// nobody knows the initial password, user sets their own with the mailed link
+		password, err := middleware.GeneratePassword(32)
+		if err != nil {
+			return nil, err
+		}
+
+		hashedPassword, err := middleware.HashPassword(password)
+		if err != nil {
+			return nil, fmt.Errorf("unable to hash password")
+		}
+
+		token, tokenHash, err := middleware.GeneratePasswordToken()
+		if err != nil {
+			return nil, err
+		}
+
+		passwordTokens[row.Row] = token
+		imports = append(imports, model.EnrollImport{
+			User: &entity.User{
+				Username:               row.Username,
+				Email:                  row.Email,
+				Password:               hashedPassword,
+				Role:                   entity.Student,
+				PasswordTokenHash:      tokenHash,
+				PasswordTokenExpiresAt: &tokenExpiresAt,
+			},
+			Enrollment: enroll,
+		})
+	}
+
+	history := entity.EnrollmentHistory{
+		ToStatus:  enrollStatus,
+		ActorID:   userClaims.UserID,
+		ActorRole: userClaims.Role,
+		Reason:    "bulk import",
+	}
+
+	if err := s.enrollRepo.ImportEnrolls(imports, history); err != nil {
+		return nil, fmt.Errorf("unable to import enrollments, nothing was saved")
+	}
+
+	// notify users, import is already saved so failures are only logged
+	for i, imported := range imports {
+		row := &result.Rows[i]
+		row.UserID = imported.User.UserID
+		result.Enrolled++
+
+		var subject, body string
+		if row.NewUser {
+			result.UsersCreated++
+			subject = "Go-Learn: You're Invited"
+			body = fmt.Sprintf("An account has been created for you and you are enrolled to course %s (status: %s).\nUsername: %s\nSet your password at %s\nThe link can be used once and expires in %d hours.", course.CourseName, enrollStatus, imported.User.Username, middleware.SetPasswordURL(passwordTokens[row.Row]), int(PasswordTokenTTL.Hours()))
+		} else {
+			subject = "Go-Learn: Course Enrollment"
+			body = fmt.Sprintf("You have been enrolled to course %s. Your enrollment status is %s.", course.CourseName, enrollStatus)
+		}
+
+		if err := middleware.SendMail(imported.User.Email, subject, body); err != nil {
+			log.Println("Error sending import notification:", err)
+		}
+	}
+
+	return result, nil
+}
+
+// enroll with invite code, skipping pending (student only)
+func (s *EnrollServiceImpl) redeemInvite(userClaims *middleware.UserClaims, course *entity.Course, student *entity.User, inviteCode string) (*entity.Enrollment, error) {
+	if userClaims.Role != entity.Student {
+		return nil, fmt.Errorf("only student can join with invite code")
+	}
+
+	invite, err := s.inviteRepo.GetInviteByCode(middleware.NormalizeInviteCode(inviteCode))
+	if err != nil || invite.CourseID != course.CourseID {
+		return nil, fmt.Errorf("invalid invite code")
+	}
+
+	now := time.Now()
+	if status := invite.Status(now); status != entity.InviteActive {
+		return nil, fmt.Errorf("invite code is %s", status)
+	}
+
+	if invite.EmailDomain != "" && !strings.HasSuffix(strings.ToLower(student.Email), "@"+invite.EmailDomain) {
+		return nil, fmt.Errorf("invite code is only for @%s email", invite.EmailDomain)
+	}
+
+	enroll := entity.Enrollment{
+		StudentID:      student.UserID,
+		CourseID:       course.CourseID,
+		EnrollmentDate: now,
+		EnrollStatus:   entity.Enroll,
+	}
+
+	history := entity.EnrollmentHistory{
+		ToStatus:  entity.Enroll,
+		ActorID:   userClaims.UserID,
+		ActorRole: userClaims.Role,
+		Reason:    fmt.Sprintf("joined with invite code %s", invite.Code),
+	}
+
+	newEnroll, err := s.inviteRepo.RedeemInvite(invite.InviteID, enroll, history, now)
+	if errors.Is(err, repository.ErrInviteUnavailable) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("student unable to enroll")
+	}
+
+	// notify student, the enrollment is already committed so a mail failure is only logged
+	if err := middleware.SendMail(
+		student.Email,
+		"Go-Learn: Course Enrollment",
+		fmt.Sprintf("You have joined course %s with an invite code. Your enrollment is active, good luck!", course.CourseName),
+	); err != nil {
+		log.Println("Error notifying invite redemption:", err)
+	}
+
+	return newEnroll, nil
+}
+
+// self-join from an invite link (student only)
+func (s *EnrollServiceImpl) JoinByInvite(userClaims *middleware.UserClaims, inviteCode string) (*entity.Enrollment, error) {
+	invite, err := s.inviteRepo.GetInviteByCode(middleware.NormalizeInviteCode(inviteCode))
+	if err != nil {
+		return nil, fmt.Errorf("invalid invite code")
+	}
+
+	return s.StudentEnroll(userClaims, fmt.Sprint(invite.CourseID), "", inviteCode)
 }
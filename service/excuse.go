@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/malware"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+	"github.com/nadyafa/go-learn/storage"
+)
+
+// supporting documents are scans & photos
+var excuseFilePolicy = middleware.FilePolicy{
+	FileTypes: []string{".jpeg", ".jpg", ".pdf", ".png"},
+	MaxSize:   middleware.MaxFileSize,
+}
+
+type ExcuseService interface {
+	SubmitExcuse(userClaims *middleware.UserClaims, courseID, classID, reason string, upload *model.FileUpload) (*entity.Excuse, error)
+	GetClassExcuses(userClaims *middleware.UserClaims, courseID, classID string) ([]entity.Excuse, error)
+	ReviewExcuse(userClaims *middleware.UserClaims, courseID, classID, excuseID string, reviewReq model.ReviewExcuseReq) (*entity.Excuse, error)
+	GetExcuseDocument(userClaims *middleware.UserClaims, courseID, classID, excuseID string) (*storage.Link, error)
+}
+
+type ExcuseServiceImpl struct {
+	excuseRepo repository.ExcuseRepo
+	courseRepo repository.CourseRepo
+	classRepo  repository.ClassRepo
+	enrollRepo repository.EnrollRepo
+	userRepo   repository.UserRepo
+	store      storage.Storage
+	scanner    malware.Scanner
+}
+
+func NewExcuseService(excuseRepo repository.ExcuseRepo, courseRepo repository.CourseRepo, classRepo repository.ClassRepo, enrollRepo repository.EnrollRepo, userRepo repository.UserRepo, store storage.Storage, scanner malware.Scanner) ExcuseService {
+	return &ExcuseServiceImpl{
+		excuseRepo: excuseRepo,
+		courseRepo: courseRepo,
+		classRepo:  classRepo,
+		enrollRepo: enrollRepo,
+		userRepo:   userRepo,
+		store:      store,
+		scanner:    scanner,
+	}
+}
+
+// excuse with an optional supporting document (student only)
+func (s *ExcuseServiceImpl) SubmitExcuse(userClaims *middleware.UserClaims, courseID, classID, reason string, upload *model.FileUpload) (*entity.Excuse, error) {
+	// student only
+	if userClaims.Role != entity.Student {
+		return nil, fmt.Errorf("only student can submit an excuse")
+	}
+
+	// check if course exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	// check if class exist
+	class, err := s.classRepo.GetClassByID(courseID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("class_id %s not found", classID)
+	}
+
+	studentID := fmt.Sprint(userClaims.UserID)
+
+	// make sure user is enrolled in course
+	enroll, err := s.enrollRepo.GetStudentCourseEnroll(courseID, studentID)
+	if err != nil || enroll.EnrollStatus != entity.Enroll {
+		return nil, fmt.Errorf("student not enroll to course")
+	}
+
+	// validate reason input
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	// only one pending or approved excuse per class
+	if existingExcuse, err := s.excuseRepo.GetActiveStudentExcuse(classID, studentID); err == nil {
+		return nil, fmt.Errorf("student already has %s excuse with excuse_id %d", existingExcuse.Status, existingExcuse.ExcuseID)
+	}
+
+	excuse := entity.Excuse{
+		StudentID: userClaims.UserID,
+		ClassID:   class.ClassID,
+		CourseID:  course.CourseID,
+		Reason:    reason,
+		Status:    entity.ExcusePending,
+	}
+
+	if upload != nil {
+		if err := s.storeDocument(&excuse, *upload); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.excuseRepo.CreateExcuse(&excuse); err != nil {
+		// stored document is removed when the excuse can't be saved
+		if excuse.DocumentKey != "" {
+			if err := s.store.Delete(context.Background(), excuse.DocumentKey); err != nil {
+				log.Println("Error removing file:", err)
+			}
+		}
+
+		return nil, fmt.Errorf("unable to submit excuse")
+	}
+
+	// the excuse is already stored, notification failures are only logged
+	s.notify(studentID, "Go-Learn: Absence Excuse Submitted",
+		fmt.Sprintf("Your absence excuse for class %s has been submitted with ExcuseID %d. We will notify you once your course mentor reviews it.", class.ClassName, excuse.ExcuseID))
+
+	s.notify(fmt.Sprint(course.MentorID), "Go-Learn: New Absence Excuse",
+		fmt.Sprintf("UserID %d has submitted an absence excuse for class %s in course %s. Please review ExcuseID %d.", userClaims.UserID, class.ClassName, course.CourseName, excuse.ExcuseID))
+
+	return &excuse, nil
+}
+
+// type & size are checked while the document is streamed to the storage, it's quarantined until the scan job has checked it
+func (s *ExcuseServiceImpl) storeDocument(excuse *entity.Excuse, upload model.FileUpload) error {
+	if upload.Size > excuseFilePolicy.MaxSize {
+		return fmt.Errorf("file size must be at most %s", middleware.FormatSize(excuseFilePolicy.MaxSize))
+	}
+
+	file, err := middleware.NewUploadReader(upload.File, upload.Name, excuseFilePolicy)
+	if err != nil {
+		return err
+	}
+
+	// stored key has nothing of the user file name
+	key, err := storage.NewKey(fmt.Sprintf("excuses/%d", excuse.ClassID), file.Ext)
+	if err != nil {
+		return fmt.Errorf("failed to save file")
+	}
+
+	if err := s.store.Put(context.Background(), key, file, upload.Size, file.ContentType); err != nil {
+		if errors.Is(err, middleware.ErrFileTooLarge) {
+			return fmt.Errorf("file size must be at most %s", middleware.FormatSize(excuseFilePolicy.MaxSize))
+		}
+
+		log.Printf("Error storing excuse document %s: %v", key, err)
+		return fmt.Errorf("failed to save file")
+	}
+
+	excuse.DocumentPath = file.Name
+	excuse.DocumentKey = key
+	excuse.ContentType = file.ContentType
+	if s.scanner != nil {
+		excuse.ScanStatus = entity.ScanPending
+	}
+
+	return nil
+}
+
+func (s *ExcuseServiceImpl) GetClassExcuses(userClaims *middleware.UserClaims, courseID, classID string) ([]entity.Excuse, error) {
+	// check if course exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	// check if class exist
+	if _, err := s.classRepo.GetClassByID(courseID, classID); err != nil {
+		return nil, fmt.Errorf("class_id %s not found", classID)
+	}
+
+	// check if mentor own the course
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("you prohibit to do this action")
+	}
+
+	// student only see their own excuses
+	studentID := ""
+	if userClaims.Role == entity.Student {
+		studentID = fmt.Sprint(userClaims.UserID)
+	}
+
+	excuses, err := s.excuseRepo.GetClassExcuses(classID, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch excuse lists")
+	}
+
+	return excuses, nil
+}
+
+func (s *ExcuseServiceImpl) ReviewExcuse(userClaims *middleware.UserClaims, courseID, classID, excuseID string, reviewReq model.ReviewExcuseReq) (*entity.Excuse, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can review an excuse")
+	}
+
+	// check if course exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	// check if mentor own the course
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("you prohibit to do this action")
+	}
+
+	// check if class exist
+	class, err := s.classRepo.GetClassByID(courseID, classID)
+	if err != nil {
+		return nil, fmt.Errorf("class_id %s not found", classID)
+	}
+
+	excuse, err := s.excuseRepo.GetExcuseByID(classID, excuseID)
+	if err != nil {
+		return nil, fmt.Errorf("excuse_id %s not found", excuseID)
+	}
+
+	if excuse.Status != entity.ExcusePending {
+		return nil, fmt.Errorf("excuse_id %s has been %s", excuseID, excuse.Status)
+	}
+
+	// validate review status
+	status := entity.ExcuseStatus(reviewReq.Status)
+	if status != entity.ExcuseApproved && status != entity.ExcuseRejected {
+		return nil, fmt.Errorf("status must be approved or rejected")
+	}
+
+	now := time.Now()
+	excuse.Status = status
+	excuse.ReviewerID = &userClaims.UserID
+	excuse.ReviewNote = reviewReq.ReviewNote
+	excuse.ReviewedAt = &now
+
+	// approved excuse changes attendance to excused
+	var attend *entity.Attendance
+	if status == entity.ExcuseApproved {
+		attend = &entity.Attendance{
+			StudentID: excuse.StudentID,
+			ClassID:   excuse.ClassID,
+			CourseID:  excuse.CourseID,
+			Attended:  false,
+			Status:    entity.Excused,
+			AttendAt:  now,
+		}
+	}
+
+	if err := s.excuseRepo.ReviewExcuse(excuse, attend); err != nil {
+		return nil, fmt.Errorf("unable to review excuse")
+	}
+
+	// the review is already stored, notification failures are only logged
+	s.notify(fmt.Sprint(excuse.StudentID), "Go-Learn: Absence Excuse Reviewed",
+		fmt.Sprintf("Your absence excuse for class %s has been %s. %s", class.ClassName, excuse.Status, excuse.ReviewNote))
+
+	s.notify(fmt.Sprint(userClaims.UserID), "Go-Learn: Absence Excuse Reviewed",
+		fmt.Sprintf("You have %s ExcuseID %d of UserID %d for class %s.", excuse.Status, excuse.ExcuseID, excuse.StudentID, class.ClassName))
+
+	return excuse, nil
+}
+
+// download link of the supporting document (admin, course mentor & the student who submitted it)
+func (s *ExcuseServiceImpl) GetExcuseDocument(userClaims *middleware.UserClaims, courseID, classID, excuseID string) (*storage.Link, error) {
+	// check if course exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	// check if mentor own the course
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("you prohibit to do this action")
+	}
+
+	// check if class exist
+	if _, err := s.classRepo.GetClassByID(courseID, classID); err != nil {
+		return nil, fmt.Errorf("class_id %s not found", classID)
+	}
+
+	excuse, err := s.excuseRepo.GetExcuseByID(classID, excuseID)
+	if err != nil {
+		return nil, fmt.Errorf("excuse_id %s not found", excuseID)
+	}
+
+	// student only gets their own document
+	if userClaims.Role == entity.Student && excuse.StudentID != userClaims.UserID {
+		return nil, fmt.Errorf("excuse_id %s not found", excuseID)
+	}
+
+	if excuse.DocumentKey == "" {
+		return nil, fmt.Errorf("excuse_id %s has no document", excuseID)
+	}
+
+	if err := checkFileScan(excuse.ScanStatus, excuse.ScanSignature); err != nil {
+		return nil, err
+	}
+
+	link, err := storage.NewLink(context.Background(), s.store, excuse.DocumentKey, excuse.DocumentPath, DownloadURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create download link")
+	}
+
+	return link, nil
+}
+
+// mail a user about an excuse, failures are logged
+func (s *ExcuseServiceImpl) notify(userID, subject, body string) {
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		log.Printf("Error notifying userID %s: user not found", userID)
+		return
+	}
+
+	if err := middleware.SendMail(user.Email, subject, body); err != nil {
+		log.Printf("Error notifying userID %s: %v", userID, err)
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+)
+
+type ExtensionService interface {
+	GrantExtension(userClaims *middleware.UserClaims, courseID, projectID string, extensionReq model.ExtensionReq) (*entity.DeadlineExtension, error)
+	GetExtensions(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.DeadlineExtension, error)
+	RevokeExtension(userClaims *middleware.UserClaims, courseID, projectID, extensionID string) error
+}
+
+type ExtensionServiceImpl struct {
+	extensionRepo repository.ExtensionRepo
+	projectRepo   repository.ProjectRepo
+	courseRepo    repository.CourseRepo
+	enrollRepo    repository.EnrollRepo
+}
+
+func NewExtensionService(extensionRepo repository.ExtensionRepo, projectRepo repository.ProjectRepo, courseRepo repository.CourseRepo, enrollRepo repository.EnrollRepo) ExtensionService {
+	return &ExtensionServiceImpl{
+		extensionRepo: extensionRepo,
+		projectRepo:   projectRepo,
+		courseRepo:    courseRepo,
+		enrollRepo:    enrollRepo,
+	}
+}
+
+// check course & project exist and mentor owns the course
+func (s *ExtensionServiceImpl) checkExtensionAccess(userClaims *middleware.UserClaims, courseID, projectID string) (*entity.Course, *entity.Project, error) {
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("course not found")
+	}
+
+	// check if project belongs to the course
+	project, err := s.projectRepo.GetProjectByID(courseID, projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("project not found")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, nil, fmt.Errorf("mentor can only manage extensions of their own course")
+	}
+
+	return course, project, nil
+}
+
+// grant or replace a student's deadline (admin & course mentor)
+func (s *ExtensionServiceImpl) GrantExtension(userClaims *middleware.UserClaims, courseID, projectID string, extensionReq model.ExtensionReq) (*entity.DeadlineExtension, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can grant an extension")
+	}
+
+	course, project, err := s.checkExtensionAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// extension is only for enrolled student
+	enroll, err := s.enrollRepo.GetStudentCourseEnroll(courseID, fmt.Sprint(extensionReq.StudentID))
+	if err != nil || enroll.EnrollStatus != entity.Enroll {
+		return nil, fmt.Errorf("student isn't enrolled to this course")
+	}
+
+	// submissions are frozen once they have been handed out for peer review
+	if project.PeerAssignedAt != nil {
+		return nil, fmt.Errorf("submissions are closed, peer review has started")
+	}
+
+	if extensionReq.Deadline.IsZero() {
+		return nil, fmt.Errorf("deadline is required")
+	}
+
+	if !extensionReq.Deadline.After(project.Deadline) {
+		return nil, fmt.Errorf("extended deadline must be after the project deadline")
+	}
+
+	if extensionReq.Deadline.After(course.EndDate) {
+		return nil, fmt.Errorf("extended deadline can't be after the course ends")
+	}
+
+	extension := entity.DeadlineExtension{
+		ProjectID: project.ProjectID,
+		StudentID: extensionReq.StudentID,
+		Deadline:  extensionReq.Deadline.Time,
+		Reason:    extensionReq.Reason,
+		GrantedBy: userClaims.UserID,
+	}
+
+	if err := s.extensionRepo.SaveExtension(&extension); err != nil {
+		return nil, fmt.Errorf("unable to grant extension")
+	}
+
+	return &extension, nil
+}
+
+// student sees only their own extension
+func (s *ExtensionServiceImpl) GetExtensions(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.DeadlineExtension, error) {
+	if _, _, err := s.checkExtensionAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	studentID := ""
+	if userClaims.Role == entity.Student {
+		studentID = fmt.Sprint(userClaims.UserID)
+	}
+
+	extensions, err := s.extensionRepo.GetExtensions(projectID, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get extensions")
+	}
+
+	return extensions, nil
+}
+
+// student falls back to the project deadline (admin & course mentor)
+func (s *ExtensionServiceImpl) RevokeExtension(userClaims *middleware.UserClaims, courseID, projectID, extensionID string) error {
+	if userClaims.Role == entity.Student {
+		return fmt.Errorf("only admin & mentor can revoke an extension")
+	}
+
+	if _, _, err := s.checkExtensionAccess(userClaims, courseID, projectID); err != nil {
+		return err
+	}
+
+	extension, err := s.extensionRepo.GetExtensionByID(projectID, extensionID)
+	if err != nil {
+		return fmt.Errorf("extension not found")
+	}
+
+	if err := s.extensionRepo.DeleteExtension(extension); err != nil {
+		return fmt.Errorf("unable to revoke extension")
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+
+	"github.com/nadyafa/go-learn/storage"
+)
+
+type FileService interface {
+	GetSignedFile(key, fileName, expires, signature string) (*storage.Object, error)
+}
+
+type FileServiceImpl struct {
+	store storage.Storage
+}
+
+func NewFileService(store storage.Storage) FileService {
+	return &FileServiceImpl{
+		store: store,
+	}
+}
+
+// object of a signed local storage link, other storages serve their links themselves
+func (s *FileServiceImpl) GetSignedFile(key, fileName, expires, signature string) (*storage.Object, error) {
+	local, ok := s.store.(*storage.LocalStorage)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+
+	if err := local.Verify(key, fileName, expires, signature); err != nil {
+		return nil, err
+	}
+
+	return local.Get(context.Background(), key)
+}
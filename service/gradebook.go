@@ -0,0 +1,535 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+)
+
+type GradebookService interface {
+	CreateCategory(userClaims *middleware.UserClaims, courseID string, categoryReq model.GradeCategoryReq) (*entity.GradeCategory, error)
+	GetCategories(courseID string) ([]entity.GradeCategory, error)
+	UpdateCategory(userClaims *middleware.UserClaims, courseID, categoryID string, categoryReq model.GradeCategoryReq) (*entity.GradeCategory, error)
+	DeleteCategory(userClaims *middleware.UserClaims, courseID, categoryID string) error
+	GetGradeScale(courseID string) ([]entity.GradeLetter, error)
+	SaveGradeScale(userClaims *middleware.UserClaims, courseID string, scaleReq model.GradeScaleReq) ([]entity.GradeLetter, error)
+	ExcuseProject(userClaims *middleware.UserClaims, courseID, projectID string, excusalReq model.GradeExcusalReq) (*entity.GradeExcusal, error)
+	UnexcuseProject(userClaims *middleware.UserClaims, courseID, projectID, studentID string) error
+	GetGradebook(userClaims *middleware.UserClaims, courseID string) (*model.Gradebook, error)
+	GetMyGrades(userClaims *middleware.UserClaims, courseID string) (*model.Gradebook, error)
+}
+
+type GradebookServiceImpl struct {
+	gradebookRepo repository.GradebookRepo
+	courseRepo    repository.CourseRepo
+	projectRepo   repository.ProjectRepo
+	enrollRepo    repository.EnrollRepo
+}
+
+func NewGradebookService(gradebookRepo repository.GradebookRepo, courseRepo repository.CourseRepo, projectRepo repository.ProjectRepo, enrollRepo repository.EnrollRepo) GradebookService {
+	return &GradebookServiceImpl{
+		gradebookRepo: gradebookRepo,
+		courseRepo:    courseRepo,
+		projectRepo:   projectRepo,
+		enrollRepo:    enrollRepo,
+	}
+}
+
+// only admin & course mentor can manage the gradebook
+func (s *GradebookServiceImpl) checkGradebookAccess(userClaims *middleware.UserClaims, courseID string) (*entity.Course, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can manage the gradebook")
+	}
+
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("mentor can only manage the gradebook of their own course")
+	}
+
+	return course, nil
+}
+
+// weights of every course category can't go over 100
+func (s *GradebookServiceImpl) validateCategory(courseID string, categoryID uint, categoryReq model.GradeCategoryReq) error {
+	if strings.TrimSpace(categoryReq.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if categoryReq.Weight <= 0 || categoryReq.Weight > 100 {
+		return fmt.Errorf("weight must be between 0-100")
+	}
+
+	if categoryReq.DropLowest < 0 {
+		return fmt.Errorf("drop_lowest can't be negative")
+	}
+
+	categories, err := s.gradebookRepo.GetCategories(courseID)
+	if err != nil {
+		return fmt.Errorf("unable to get grade categories")
+	}
+
+	total := categoryReq.Weight
+	for _, category := range categories {
+		if category.CategoryID != categoryID {
+			total += category.Weight
+		}
+	}
+
+	if total > 100 {
+		return fmt.Errorf("category weights add up to %.2f, must not exceed 100", total)
+	}
+
+	return nil
+}
+
+func (s *GradebookServiceImpl) CreateCategory(userClaims *middleware.UserClaims, courseID string, categoryReq model.GradeCategoryReq) (*entity.GradeCategory, error) {
+	course, err := s.checkGradebookAccess(userClaims, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateCategory(courseID, 0, categoryReq); err != nil {
+		return nil, err
+	}
+
+	category := entity.GradeCategory{
+		CourseID:   course.CourseID,
+		Name:       strings.TrimSpace(categoryReq.Name),
+		Weight:     categoryReq.Weight,
+		DropLowest: categoryReq.DropLowest,
+	}
+
+	if err := s.gradebookRepo.CreateCategory(&category); err != nil {
+		return nil, fmt.Errorf("unable to create grade category")
+	}
+
+	return &category, nil
+}
+
+func (s *GradebookServiceImpl) GetCategories(courseID string) ([]entity.GradeCategory, error) {
+	if _, err := s.courseRepo.GetCourseByID(courseID); err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	categories, err := s.gradebookRepo.GetCategories(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get grade categories")
+	}
+
+	return categories, nil
+}
+
+func (s *GradebookServiceImpl) UpdateCategory(userClaims *middleware.UserClaims, courseID, categoryID string, categoryReq model.GradeCategoryReq) (*entity.GradeCategory, error) {
+	if _, err := s.checkGradebookAccess(userClaims, courseID); err != nil {
+		return nil, err
+	}
+
+	category, err := s.gradebookRepo.GetCategoryByID(courseID, categoryID)
+	if err != nil {
+		return nil, fmt.Errorf("grade category not found")
+	}
+
+	if err := s.validateCategory(courseID, category.CategoryID, categoryReq); err != nil {
+		return nil, err
+	}
+
+	category.Name = strings.TrimSpace(categoryReq.Name)
+	category.Weight = categoryReq.Weight
+	category.DropLowest = categoryReq.DropLowest
+
+	if err := s.gradebookRepo.UpdateCategory(category); err != nil {
+		return nil, fmt.Errorf("unable to update grade category")
+	}
+
+	return category, nil
+}
+
+func (s *GradebookServiceImpl) DeleteCategory(userClaims *middleware.UserClaims, courseID, categoryID string) error {
+	if _, err := s.checkGradebookAccess(userClaims, courseID); err != nil {
+		return err
+	}
+
+	category, err := s.gradebookRepo.GetCategoryByID(courseID, categoryID)
+	if err != nil {
+		return fmt.Errorf("grade category not found")
+	}
+
+	if err := s.gradebookRepo.DeleteCategory(category); err != nil {
+		return fmt.Errorf("unable to delete grade category")
+	}
+
+	return nil
+}
+
+// course scale, or the default one when course has none
+func (s *GradebookServiceImpl) GetGradeScale(courseID string) ([]entity.GradeLetter, error) {
+	letters, err := s.gradebookRepo.GetGradeScale(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get grade scale")
+	}
+
+	if len(letters) == 0 {
+		return entity.DefaultGradeScale, nil
+	}
+
+	return letters, nil
+}
+
+// empty letters resets the course to the default scale
+func (s *GradebookServiceImpl) SaveGradeScale(userClaims *middleware.UserClaims, courseID string, scaleReq model.GradeScaleReq) ([]entity.GradeLetter, error) {
+	course, err := s.checkGradebookAccess(userClaims, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	letters := make([]entity.GradeLetter, 0, len(scaleReq.Letters))
+	seenLetter := make(map[string]bool)
+	seenScore := make(map[float64]bool)
+	hasZero := false
+	for _, letterReq := range scaleReq.Letters {
+		letter := strings.TrimSpace(letterReq.Letter)
+		if letter == "" || len(letter) > 8 {
+			return nil, fmt.Errorf("letter must be 1-8 characters")
+		}
+
+		if letterReq.MinScore < 0 || letterReq.MinScore > 100 {
+			return nil, fmt.Errorf("min_score of %s must be between 0-100", letter)
+		}
+
+		if seenLetter[letter] || seenScore[letterReq.MinScore] {
+			return nil, fmt.Errorf("letter & min_score must be unique, %s is repeated", letter)
+		}
+		seenLetter[letter] = true
+		seenScore[letterReq.MinScore] = true
+
+		if letterReq.MinScore == 0 {
+			hasZero = true
+		}
+
+		letters = append(letters, entity.GradeLetter{
+			CourseID: course.CourseID,
+			Letter:   letter,
+			MinScore: letterReq.MinScore,
+		})
+	}
+
+	// every total must get a letter
+	if len(letters) > 0 && !hasZero {
+		return nil, fmt.Errorf("scale needs a letter with min_score 0")
+	}
+
+	if err := s.gradebookRepo.SaveGradeScale(course.CourseID, letters); err != nil {
+		return nil, fmt.Errorf("unable to save grade scale")
+	}
+
+	return s.GetGradeScale(courseID)
+}
+
+func (s *GradebookServiceImpl) ExcuseProject(userClaims *middleware.UserClaims, courseID, projectID string, excusalReq model.GradeExcusalReq) (*entity.GradeExcusal, error) {
+	if _, err := s.checkGradebookAccess(userClaims, courseID); err != nil {
+		return nil, err
+	}
+
+	project, err := s.projectRepo.GetProjectByID(courseID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	if _, err := s.enrollRepo.GetStudentCourseEnroll(courseID, fmt.Sprint(excusalReq.StudentID)); err != nil {
+		return nil, fmt.Errorf("student isn't enrolled to this course")
+	}
+
+	excusal := entity.GradeExcusal{
+		ProjectID: project.ProjectID,
+		StudentID: excusalReq.StudentID,
+		Reason:    excusalReq.Reason,
+		ExcusedBy: userClaims.UserID,
+	}
+
+	if err := s.gradebookRepo.SaveExcusal(&excusal); err != nil {
+		return nil, fmt.Errorf("unable to excuse project")
+	}
+
+	return &excusal, nil
+}
+
+func (s *GradebookServiceImpl) UnexcuseProject(userClaims *middleware.UserClaims, courseID, projectID, studentID string) error {
+	if _, err := s.checkGradebookAccess(userClaims, courseID); err != nil {
+		return err
+	}
+
+	if _, err := s.projectRepo.GetProjectByID(courseID, projectID); err != nil {
+		return fmt.Errorf("project not found")
+	}
+
+	if err := s.gradebookRepo.DeleteExcusal(projectID, studentID); err != nil {
+		return fmt.Errorf("excusal not found")
+	}
+
+	return nil
+}
+
+// grid of every enrolled student (admin & course mentor)
+func (s *GradebookServiceImpl) GetGradebook(userClaims *middleware.UserClaims, courseID string) (*model.Gradebook, error) {
+	course, err := s.checkGradebookAccess(userClaims, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	students, _, err := s.enrollRepo.SearchEnrolls(model.EnrollFilter{CourseID: course.CourseID, Status: entity.Enroll})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get enrolled students")
+	}
+
+	return s.buildGradebook(course, students)
+}
+
+// student's own grades, finished enrollments keep their grades
+func (s *GradebookServiceImpl) GetMyGrades(userClaims *middleware.UserClaims, courseID string) (*model.Gradebook, error) {
+	if userClaims.Role != entity.Student {
+		return nil, fmt.Errorf("only student has personal grades")
+	}
+
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	enrolls, _, err := s.enrollRepo.SearchEnrolls(model.EnrollFilter{CourseID: course.CourseID, StudentID: userClaims.UserID})
+	if err != nil || len(enrolls) == 0 {
+		return nil, fmt.Errorf("student isn't enrolled to this course")
+	}
+
+	switch enrolls[0].EnrollStatus {
+	case entity.Enroll, entity.Complete, entity.Failed:
+	default:
+		return nil, fmt.Errorf("student isn't enrolled to this course")
+	}
+
+	return s.buildGradebook(course, enrolls[:1])
+}
+
+func (s *GradebookServiceImpl) buildGradebook(course *entity.Course, students []model.EnrollDetail) (*model.Gradebook, error) {
+	courseID := fmt.Sprint(course.CourseID)
+
+	categories, err := s.gradebookRepo.GetCategories(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get grade categories")
+	}
+
+	projects, err := s.projectRepo.GetProjects(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get course projects")
+	}
+
+	projectSubs, err := s.gradebookRepo.GetLatestProjectSubs(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project submissions")
+	}
+
+	excusals, err := s.gradebookRepo.GetCourseExcusals(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get excused projects")
+	}
+
+	members, err := s.gradebookRepo.GetCourseTeamMembers(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project teams")
+	}
+
+	extensions, err := s.gradebookRepo.GetCourseExtensions(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get deadline extensions")
+	}
+
+	scale, err := s.GetGradeScale(courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Deadline.Before(projects[j].Deadline)
+	})
+
+	gradebook := model.Gradebook{
+		CourseID: course.CourseID,
+		Projects: make([]model.GradebookProject, 0, len(projects)),
+		Students: make([]model.GradebookStudent, 0, len(students)),
+	}
+
+	for _, letter := range scale {
+		gradebook.Scale = append(gradebook.Scale, model.GradeLetterResp{Letter: letter.Letter, MinScore: letter.MinScore})
+	}
+
+	for _, project := range projects {
+		gradebook.Projects = append(gradebook.Projects, model.GradebookProject{
+			ProjectID:   project.ProjectID,
+			ProjectName: project.ProjectName,
+			CategoryID:  project.CategoryID,
+			Optional:    project.Optional,
+		})
+	}
+
+	type studentProject struct{ projectID, studentID uint }
+
+	// team submission counts for every member of the team
+	latest := make(map[studentProject]entity.ProjectSub)
+	teamSubs := make(map[uint]entity.ProjectSub)
+	for _, projectSub := range projectSubs {
+		if projectSub.TeamID != nil {
+			teamSubs[*projectSub.TeamID] = projectSub
+			continue
+		}
+		latest[studentProject{projectSub.ProjectID, projectSub.StudentID}] = projectSub
+	}
+
+	teamMembers := make(map[studentProject]entity.TeamMember)
+	for _, member := range members {
+		teamMembers[studentProject{member.ProjectID, member.StudentID}] = member
+	}
+
+	excused := make(map[studentProject]bool)
+	for _, excusal := range excusals {
+		excused[studentProject{excusal.ProjectID, excusal.StudentID}] = true
+	}
+
+	deadlines := make(map[studentProject]time.Time)
+	for _, extension := range extensions {
+		deadlines[studentProject{extension.ProjectID, extension.StudentID}] = extension.Deadline
+	}
+
+	now := time.Now()
+	for _, student := range students {
+		items := make([]model.GradebookItem, 0, len(projects))
+		for _, project := range projects {
+			key := studentProject{project.ProjectID, student.StudentID}
+			item := model.GradebookItem{
+				ProjectID:  project.ProjectID,
+				CategoryID: project.CategoryID,
+			}
+
+			deadline, ok := deadlines[key]
+			if !ok {
+				deadline = project.Deadline
+			}
+
+			projectSub, submitted := latest[key]
+			member, inTeam := teamMembers[key]
+			if project.TeamProject {
+				projectSub, submitted = teamSubs[member.TeamID]
+				submitted = submitted && inTeam
+			}
+
+			switch {
+			case excused[key]:
+				item.Status = model.GradeExcused
+			// only the mentor grades an attempt, a real 0 counts & an unreviewed auto score stays pending
+			case submitted && projectSub.GradedAt != nil:
+				score := projectSub.FinalScore()
+				if project.TeamProject {
+					score = member.AdjustedScore(score)
+				}
+				item.Status = model.GradeGraded
+				item.Score = &score
+			case submitted:
+				item.Status = model.GradePending
+			case now.Before(deadline):
+				item.Status = model.GradeNotDue
+			case project.Optional:
+				item.Status = model.GradeSkipped
+			default:
+				score := 0.0
+				item.Status = model.GradeMissing
+				item.Score = &score
+			}
+
+			items = append(items, item)
+		}
+
+		gradebookStudent := model.GradebookStudent{
+			StudentID:  student.StudentID,
+			Username:   student.Username,
+			Email:      student.Email,
+			Categories: gradeCategories(categories, items),
+			Items:      items,
+		}
+
+		// categories without counted projects are left out & the rest is reweighted
+		var weighted, weights float64
+		for _, category := range gradebookStudent.Categories {
+			if category.Average != nil {
+				weighted += category.Weight * *category.Average
+				weights += category.Weight
+			}
+		}
+
+		if weights > 0 {
+			total := math.Round(weighted/weights*100) / 100
+			gradebookStudent.Total = &total
+			gradebookStudent.Letter = entity.LetterGrade(scale, total)
+		}
+
+		gradebook.Students = append(gradebook.Students, gradebookStudent)
+	}
+
+	return &gradebook, nil
+}
+
+// average of each category after dropping lowest scores, course without categories weighs every project equally
+func gradeCategories(categories []entity.GradeCategory, items []model.GradebookItem) []model.GradebookCategoryScore {
+	if len(categories) == 0 {
+		categories = []entity.GradeCategory{{Name: "Projects", Weight: 100}}
+	}
+
+	scores := make([]model.GradebookCategoryScore, 0, len(categories))
+	for _, category := range categories {
+		var counted []*model.GradebookItem
+		for i := range items {
+			inCategory := category.CategoryID == 0 || (items[i].CategoryID != nil && *items[i].CategoryID == category.CategoryID)
+			if inCategory && items[i].Score != nil {
+				counted = append(counted, &items[i])
+			}
+		}
+
+		score := model.GradebookCategoryScore{
+			Name:   category.Name,
+			Weight: category.Weight,
+		}
+		if category.CategoryID != 0 {
+			score.CategoryID = &category.CategoryID
+		}
+
+		// at least one score is kept
+		sort.SliceStable(counted, func(i, j int) bool {
+			return *counted[i].Score < *counted[j].Score
+		})
+		drop := min(category.DropLowest, len(counted)-1)
+		for i := 0; i < drop; i++ {
+			counted[i].Dropped = true
+		}
+
+		if kept := counted[max(drop, 0):]; len(kept) > 0 {
+			var sum float64
+			for _, item := range kept {
+				sum += *item.Score
+			}
+			average := math.Round(sum/float64(len(kept))*100) / 100
+			score.Average = &average
+		}
+
+		scores = append(scores, score)
+	}
+
+	return scores
+}
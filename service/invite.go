@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+)
+
+type InviteService interface {
+	CreateInvite(userClaims *middleware.UserClaims, courseID string, inviteReq model.InviteReq) (*entity.CourseInvite, error)
+	GetCourseInvites(userClaims *middleware.UserClaims, courseID string) ([]entity.CourseInvite, error)
+	GetInviteByID(userClaims *middleware.UserClaims, courseID, inviteID string) (*entity.CourseInvite, error)
+	RevokeInvite(userClaims *middleware.UserClaims, courseID, inviteID string) (*entity.CourseInvite, error)
+}
+
+type InviteServiceImpl struct {
+	inviteRepo repository.InviteRepo
+	courseRepo repository.CourseRepo
+}
+
+func NewInviteService(inviteRepo repository.InviteRepo, courseRepo repository.CourseRepo) InviteService {
+	return &InviteServiceImpl{
+		inviteRepo: inviteRepo,
+		courseRepo: courseRepo,
+	}
+}
+
+// admin & course mentor only
+func (s *InviteServiceImpl) checkInviteAccess(userClaims *middleware.UserClaims, courseID string) (*entity.Course, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can manage invite codes")
+	}
+
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("mentor can only manage invite codes of their own course")
+	}
+
+	return course, nil
+}
+
+func (s *InviteServiceImpl) CreateInvite(userClaims *middleware.UserClaims, courseID string, inviteReq model.InviteReq) (*entity.CourseInvite, error) {
+	course, err := s.checkInviteAccess(userClaims, courseID)
+	if err != nil {
+		return nil, err
+	}
+
+	if inviteReq.MaxUses < 0 {
+		return nil, fmt.Errorf("max_uses cannot be negative")
+	}
+
+	var expiresAt *time.Time
+	if inviteReq.ExpiresAt != nil && !inviteReq.ExpiresAt.IsZero() {
+		if !inviteReq.ExpiresAt.After(time.Now()) {
+			return nil, fmt.Errorf("expires_at must be in the future")
+		}
+		expiresAt = &inviteReq.ExpiresAt.Time
+	}
+
+	// accept "example.com" or "@example.com"
+	emailDomain := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(inviteReq.EmailDomain), "@"))
+	if emailDomain != "" && !strings.Contains(emailDomain, ".") {
+		return nil, fmt.Errorf("invalid email_domain")
+	}
+
+	code, err := middleware.GenerateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	invite := entity.CourseInvite{
+		CourseID:    course.CourseID,
+		Code:        code,
+		CreatedBy:   userClaims.UserID,
+		ExpiresAt:   expiresAt,
+		MaxUses:     inviteReq.MaxUses,
+		EmailDomain: emailDomain,
+	}
+
+	if err := s.inviteRepo.CreateInvite(&invite); err != nil {
+		return nil, fmt.Errorf("unable to create invite code")
+	}
+
+	return &invite, nil
+}
+
+func (s *InviteServiceImpl) GetCourseInvites(userClaims *middleware.UserClaims, courseID string) ([]entity.CourseInvite, error) {
+	if _, err := s.checkInviteAccess(userClaims, courseID); err != nil {
+		return nil, err
+	}
+
+	invites, err := s.inviteRepo.GetCourseInvites(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get invite codes")
+	}
+
+	return invites, nil
+}
+
+// invite with its redemptions
+func (s *InviteServiceImpl) GetInviteByID(userClaims *middleware.UserClaims, courseID, inviteID string) (*entity.CourseInvite, error) {
+	if _, err := s.checkInviteAccess(userClaims, courseID); err != nil {
+		return nil, err
+	}
+
+	invite, err := s.inviteRepo.GetInviteByID(courseID, inviteID)
+	if err != nil {
+		return nil, fmt.Errorf("invite code not found")
+	}
+
+	return invite, nil
+}
+
+func (s *InviteServiceImpl) RevokeInvite(userClaims *middleware.UserClaims, courseID, inviteID string) (*entity.CourseInvite, error) {
+	if _, err := s.checkInviteAccess(userClaims, courseID); err != nil {
+		return nil, err
+	}
+
+	invite, err := s.inviteRepo.GetInviteByID(courseID, inviteID)
+	if err != nil {
+		return nil, fmt.Errorf("invite code not found")
+	}
+
+	if invite.RevokedAt != nil {
+		return nil, fmt.Errorf("invite code has already been revoked")
+	}
+
+	now := time.Now()
+	invite.RevokedAt = &now
+
+	if err := s.inviteRepo.UpdateInvite(invite); err != nil {
+		return nil, fmt.Errorf("unable to revoke invite code")
+	}
+
+	return invite, nil
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"path/filepath"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+	"github.com/nadyafa/go-learn/storage"
+)
+
+type PeerReviewService interface {
+	AssignReviews(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.PeerReview, error)
+	AssignDueReviews() (int, error)
+	GetReviews(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.PeerReview, error)
+	SubmitReview(userClaims *middleware.UserClaims, courseID, projectID, reviewID string, reviewReq model.PeerReviewReq) (*entity.PeerReview, error)
+	RateReview(userClaims *middleware.UserClaims, courseID, projectID, reviewID string, qualityReq model.PeerReviewQualityReq) (*entity.PeerReview, error)
+	GetReviewFile(userClaims *middleware.UserClaims, courseID, projectID, reviewID string) (*storage.Link, error)
+}
+
+type PeerReviewServiceImpl struct {
+	peerReviewRepo repository.PeerReviewRepo
+	projectSubRepo repository.ProjectSubRepo
+	projectRepo    repository.ProjectRepo
+	courseRepo     repository.CourseRepo
+	enrollRepo     repository.EnrollRepo
+	teamRepo       repository.TeamRepo
+	rubricRepo     repository.RubricRepo
+	extensionRepo  repository.ExtensionRepo
+	store          storage.Storage
+}
+
+func NewPeerReviewService(peerReviewRepo repository.PeerReviewRepo, projectSubRepo repository.ProjectSubRepo, projectRepo repository.ProjectRepo, courseRepo repository.CourseRepo, enrollRepo repository.EnrollRepo, teamRepo repository.TeamRepo, rubricRepo repository.RubricRepo, extensionRepo repository.ExtensionRepo, store storage.Storage) PeerReviewService {
+	return &PeerReviewServiceImpl{
+		peerReviewRepo: peerReviewRepo,
+		projectSubRepo: projectSubRepo,
+		projectRepo:    projectRepo,
+		courseRepo:     courseRepo,
+		enrollRepo:     enrollRepo,
+		teamRepo:       teamRepo,
+		rubricRepo:     rubricRepo,
+		extensionRepo:  extensionRepo,
+		store:          store,
+	}
+}
+
+// check course & peer reviewed project exist and mentor owns the course
+func (s *PeerReviewServiceImpl) checkPeerReviewAccess(userClaims *middleware.UserClaims, courseID, projectID string) (*entity.Project, error) {
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	project, err := s.projectRepo.GetProjectByID(courseID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	if !project.PeerReviewEnabled {
+		return nil, fmt.Errorf("project doesn't have peer review")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("mentor can only manage peer reviews of their own course")
+	}
+
+	return project, nil
+}
+
+// review must belong to the project, student can only reach reviews assigned to them
+func (s *PeerReviewServiceImpl) getReview(userClaims *middleware.UserClaims, projectID, reviewID string) (*entity.PeerReview, error) {
+	review, err := s.peerReviewRepo.GetReviewByID(projectID, reviewID)
+	if err != nil || (userClaims.Role == entity.Student && review.ReviewerID != userClaims.UserID) {
+		return nil, fmt.Errorf("peer review not found")
+	}
+
+	return review, nil
+}
+
+// hand out submissions after the deadline (admin & mentor), running it again only fills missing reviewers
+func (s *PeerReviewServiceImpl) AssignReviews(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.PeerReview, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can assign peer reviews")
+	}
+
+	project, err := s.checkPeerReviewAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := s.peerReviewStart(project)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().Before(start) {
+		return nil, fmt.Errorf("peer reviews can only be assigned once submissions close, including granted extensions")
+	}
+
+	return s.assignProject(project)
+}
+
+// assigning freezes submissions, so it waits for the grace window & the latest granted extension
+func (s *PeerReviewServiceImpl) peerReviewStart(project *entity.Project) (time.Time, error) {
+	extensions, err := s.extensionRepo.GetExtensions(fmt.Sprint(project.ProjectID), "")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to get extensions")
+	}
+
+	return project.PeerReviewStart(extensions...), nil
+}
+
+// assign peer reviews of every project whose submissions are closed, returns the number of reviews created
+func (s *PeerReviewServiceImpl) AssignDueReviews() (int, error) {
+	now := time.Now()
+
+	projects, err := s.peerReviewRepo.GetProjectsToAssign(now)
+	if err != nil {
+		return 0, err
+	}
+
+	assigned := 0
+	for i := range projects {
+		start, err := s.peerReviewStart(&projects[i])
+		if err != nil {
+			log.Printf("Error assigning peer reviews of project %d: %v", projects[i].ProjectID, err)
+			continue
+		}
+
+		if now.Before(start) {
+			continue
+		}
+
+		reviews, err := s.assignProject(&projects[i])
+		if err != nil {
+			log.Printf("Error assigning peer reviews of project %d: %v", projects[i].ProjectID, err)
+			continue
+		}
+
+		assigned += len(reviews)
+	}
+
+	return assigned, nil
+}
+
+// every latest submission gets up to peer_reviewers enrolled students, authors never review their own work
+// & the next reviewer is always one with the fewest reviews, ties are broken randomly
+func (s *PeerReviewServiceImpl) assignProject(project *entity.Project) ([]entity.PeerReview, error) {
+	projectID := fmt.Sprint(project.ProjectID)
+
+	projectSubs, err := s.projectSubRepo.GetProjectSubs(projectID, "", true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project submissions")
+	}
+
+	enrolls, err := s.enrollRepo.GetCourseEnrolls(fmt.Sprint(project.CourseID), entity.Enroll)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get enrolled students")
+	}
+
+	existing, err := s.peerReviewRepo.GetReviews(projectID, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get peer reviews")
+	}
+
+	teamMembers := make(map[uint][]uint)
+	if project.TeamProject {
+		teams, err := s.teamRepo.GetTeams(projectID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get project teams")
+		}
+
+		for _, team := range teams {
+			for _, member := range team.Members {
+				teamMembers[team.TeamID] = append(teamMembers[team.TeamID], member.StudentID)
+			}
+		}
+	}
+
+	reviewers := make([]uint, 0, len(enrolls))
+	load := make(map[uint]int)
+	for _, enroll := range enrolls {
+		reviewers = append(reviewers, enroll.StudentID)
+		load[enroll.StudentID] = 0
+	}
+	rand.Shuffle(len(reviewers), func(i, j int) { reviewers[i], reviewers[j] = reviewers[j], reviewers[i] })
+
+	assigned := make(map[uint]map[uint]bool)
+	for _, review := range existing {
+		if assigned[review.ProjectSubID] == nil {
+			assigned[review.ProjectSubID] = make(map[uint]bool)
+		}
+		assigned[review.ProjectSubID][review.ReviewerID] = true
+		load[review.ReviewerID]++
+	}
+
+	rand.Shuffle(len(projectSubs), func(i, j int) { projectSubs[i], projectSubs[j] = projectSubs[j], projectSubs[i] })
+
+	var reviews []entity.PeerReview
+	for _, projectSub := range projectSubs {
+		authors := map[uint]bool{projectSub.StudentID: true}
+		if projectSub.TeamID != nil {
+			for _, studentID := range teamMembers[*projectSub.TeamID] {
+				authors[studentID] = true
+			}
+		}
+
+		if assigned[projectSub.ProjectSubID] == nil {
+			assigned[projectSub.ProjectSubID] = make(map[uint]bool)
+		}
+		subReviewers := assigned[projectSub.ProjectSubID]
+
+		for len(subReviewers) < project.PeerReviewers {
+			var reviewerID uint
+			found := false
+			for _, candidate := range reviewers {
+				if authors[candidate] || subReviewers[candidate] {
+					continue
+				}
+
+				if !found || load[candidate] < load[reviewerID] {
+					reviewerID = candidate
+					found = true
+				}
+			}
+
+			// not enough students left to review this submission
+			if !found {
+				break
+			}
+
+			subReviewers[reviewerID] = true
+			load[reviewerID]++
+			reviews = append(reviews, entity.PeerReview{
+				ProjectID:    project.ProjectID,
+				ProjectSubID: projectSub.ProjectSubID,
+				ReviewerID:   reviewerID,
+			})
+		}
+	}
+
+	if err := s.peerReviewRepo.CreateReviews(project, reviews, time.Now()); err != nil {
+		return nil, fmt.Errorf("unable to assign peer reviews")
+	}
+
+	return reviews, nil
+}
+
+// student gets the reviews assigned to them, mentor of the course & admin get every review
+func (s *PeerReviewServiceImpl) GetReviews(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.PeerReview, error) {
+	if _, err := s.checkPeerReviewAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	var reviewerID uint
+	if userClaims.Role == entity.Student {
+		reviewerID = userClaims.UserID
+	}
+
+	reviews, err := s.peerReviewRepo.GetReviews(projectID, reviewerID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get peer reviews")
+	}
+
+	return reviews, nil
+}
+
+// reviewer fills in the rubric or a score out of 100, it can be changed until the review deadline (reviewer only)
+func (s *PeerReviewServiceImpl) SubmitReview(userClaims *middleware.UserClaims, courseID, projectID, reviewID string, reviewReq model.PeerReviewReq) (*entity.PeerReview, error) {
+	if userClaims.Role != entity.Student {
+		return nil, fmt.Errorf("only the assigned student can submit a peer review")
+	}
+
+	project, err := s.checkPeerReviewAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := s.getReview(userClaims, projectID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if project.PeerReviewDeadline != nil && now.After(*project.PeerReviewDeadline) {
+		return nil, fmt.Errorf("peer review deadline has passed")
+	}
+
+	if review.Quality != nil {
+		return nil, fmt.Errorf("peer review has already been rated by mentor")
+	}
+
+	var grades []entity.PeerCriterionGrade
+	if project.RubricID != nil {
+		rubric, err := s.rubricRepo.GetRubricByID(fmt.Sprint(*project.RubricID))
+		if err != nil {
+			return nil, fmt.Errorf("rubric not found")
+		}
+
+		criterionGrades, score, err := rubricGrades(rubric, reviewReq.Criteria)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, grade := range criterionGrades {
+			grades = append(grades, entity.PeerCriterionGrade{
+				CriterionID: grade.CriterionID,
+				LevelID:     grade.LevelID,
+				Points:      grade.Points,
+				Comment:     grade.Comment,
+			})
+		}
+
+		peerScore := float64(score)
+		review.Score = &peerScore
+	} else {
+		if reviewReq.Score == nil || *reviewReq.Score < 0 || *reviewReq.Score > 100 {
+			return nil, fmt.Errorf("score must be between 0-100")
+		}
+
+		review.Score = reviewReq.Score
+	}
+
+	review.Comment = reviewReq.Comment
+	review.SubmittedAt = &now
+
+	if err := s.peerReviewRepo.SubmitReview(review, grades); err != nil {
+		return nil, fmt.Errorf("unable to submit peer review")
+	}
+
+	return review, nil
+}
+
+// rate how useful a submitted review is, 1-5 (admin & mentor)
+func (s *PeerReviewServiceImpl) RateReview(userClaims *middleware.UserClaims, courseID, projectID, reviewID string, qualityReq model.PeerReviewQualityReq) (*entity.PeerReview, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can rate a peer review")
+	}
+
+	if _, err := s.checkPeerReviewAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	if qualityReq.Quality < 1 || qualityReq.Quality > 5 {
+		return nil, fmt.Errorf("quality must be between 1-5")
+	}
+
+	review, err := s.getReview(userClaims, projectID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if review.SubmittedAt == nil {
+		return nil, fmt.Errorf("peer review hasn't been submitted yet")
+	}
+
+	review.Quality = &qualityReq.Quality
+	review.QualityNote = qualityReq.Note
+
+	if err := s.peerReviewRepo.UpdateReview(review); err != nil {
+		return nil, fmt.Errorf("unable to rate peer review")
+	}
+
+	return review, nil
+}
+
+// file of the reviewed submission, reviewer can download it without knowing the author
+func (s *PeerReviewServiceImpl) GetReviewFile(userClaims *middleware.UserClaims, courseID, projectID, reviewID string) (*storage.Link, error) {
+	if _, err := s.checkPeerReviewAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	review, err := s.getReview(userClaims, projectID, reviewID)
+	if err != nil {
+		return nil, err
+	}
+
+	projectSub, err := s.projectSubRepo.GetProjectSubByID(projectID, fmt.Sprint(review.ProjectSubID))
+	if err != nil || projectSub.FileKey == "" {
+		return nil, fmt.Errorf("submission file not found")
+	}
+
+	if err := checkFileScan(projectSub.ScanStatus, projectSub.ScanSignature); err != nil {
+		return nil, err
+	}
+
+	// original file name could give the author away
+	fileName := fmt.Sprintf("review-%d%s", review.ReviewID, filepath.Ext(projectSub.ProjectPath))
+
+	link, err := storage.NewLink(context.Background(), s.store, projectSub.FileKey, fileName, DownloadURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create download link")
+	}
+
+	return link, nil
+}
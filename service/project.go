@@ -1,12 +1,20 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
 
+	"github.com/nadyafa/go-learn/autograde"
 	"github.com/nadyafa/go-learn/entity"
 	"github.com/nadyafa/go-learn/middleware"
 	"github.com/nadyafa/go-learn/model"
 	"github.com/nadyafa/go-learn/repository"
+	"github.com/nadyafa/go-learn/storage"
 )
 
 type ProjectService interface {
@@ -15,20 +23,56 @@ type ProjectService interface {
 	GetProjectByID(courseID, projectID string) (*entity.Project, error)
 	UpdatedProjectByID(userClaims *middleware.UserClaims, courseID, projectID string, projectReq model.UpdateProject) (*entity.Project, error)
 	DeleteProjectByID(userClaims *middleware.UserClaims, courseID, projectID string) error
+	SetTestSuite(userClaims *middleware.UserClaims, courseID, projectID string, upload model.FileUpload) (*entity.Project, error)
+	RemoveTestSuite(userClaims *middleware.UserClaims, courseID, projectID string) (*entity.Project, error)
 }
 
 type ProjectServiceImpl struct {
 	projectRepo repository.ProjectRepo
 	courseRepo  repository.CourseRepo
+	rubricRepo  repository.RubricRepo
+	gradeRepo   repository.GradebookRepo
+	store       storage.Storage
 }
 
-func NewProjectService(projectRepo repository.ProjectRepo, courseRepo repository.CourseRepo) ProjectService {
+func NewProjectService(projectRepo repository.ProjectRepo, courseRepo repository.CourseRepo, rubricRepo repository.RubricRepo, gradeRepo repository.GradebookRepo, store storage.Storage) ProjectService {
 	return &ProjectServiceImpl{
 		projectRepo: projectRepo,
 		courseRepo:  courseRepo,
+		rubricRepo:  rubricRepo,
+		gradeRepo:   gradeRepo,
+		store:       store,
 	}
 }
 
+// category must belong to the project course, 0 uncategorizes the project
+func (s *ProjectServiceImpl) projectCategory(courseID string, categoryID uint) (*uint, error) {
+	if categoryID == 0 {
+		return nil, nil
+	}
+
+	category, err := s.gradeRepo.GetCategoryByID(courseID, fmt.Sprint(categoryID))
+	if err != nil {
+		return nil, fmt.Errorf("category_id %d not found in this course", categoryID)
+	}
+
+	return &category.CategoryID, nil
+}
+
+// rubric of any course can be attached, 0 detaches it
+func (s *ProjectServiceImpl) projectRubric(rubricID uint) (*uint, error) {
+	if rubricID == 0 {
+		return nil, nil
+	}
+
+	rubric, err := s.rubricRepo.GetRubricByID(fmt.Sprint(rubricID))
+	if err != nil {
+		return nil, fmt.Errorf("rubric_id %d not found", rubricID)
+	}
+
+	return &rubric.RubricID, nil
+}
+
 func (s *ProjectServiceImpl) CreateProject(userClaims *middleware.UserClaims, courseID string, projectReq model.CreateProject) (*entity.Project, error) {
 	if userClaims.Role == entity.Student {
 		return nil, fmt.Errorf("only admin & mentor can create a new project")
@@ -59,11 +103,96 @@ func (s *ProjectServiceImpl) CreateProject(userClaims *middleware.UserClaims, co
 		return nil, errMsg
 	}
 
+	if projectReq.MaxAttempts < 0 {
+		return nil, fmt.Errorf("max_attempts can't be negative")
+	}
+
+	// deadline passing closes submissions unless another policy is chosen
+	penaltyCap := 100.0
+	if projectReq.PenaltyCap != nil {
+		penaltyCap = *projectReq.PenaltyCap
+	}
+
 	newProject := entity.Project{
 		CourseID:    courseExist.CourseID,
 		ProjectName: projectReq.ProjectName,
 		Description: projectReq.Description,
 		Deadline:    projectReq.Deadline.Time,
+		Optional:    projectReq.Optional,
+		MaxAttempts: projectReq.MaxAttempts,
+
+		TeamProject:     projectReq.TeamProject,
+		MinTeamSize:     projectReq.MinTeamSize,
+		MaxTeamSize:     projectReq.MaxTeamSize,
+		SelfFormedTeams: projectReq.SelfFormedTeams,
+
+		PeerReviewEnabled: projectReq.PeerReviewEnabled,
+		PeerReviewers:     projectReq.PeerReviewers,
+		PeerWeight:        projectReq.PeerWeight,
+
+		LatePolicy:    entity.LateCutoff,
+		GraceHours:    projectReq.GraceHours,
+		PenaltyPerDay: projectReq.PenaltyPerDay,
+		PenaltyCap:    penaltyCap,
+	}
+
+	if projectReq.LatePolicy != "" {
+		newProject.LatePolicy = projectReq.LatePolicy
+	}
+
+	if err := validateLatePolicy(&newProject); err != nil {
+		return nil, err
+	}
+
+	if newProject.MinTeamSize == 0 {
+		newProject.MinTeamSize = 1
+	}
+
+	if err := validateTeamPolicy(&newProject); err != nil {
+		return nil, err
+	}
+
+	if projectReq.PeerReviewDeadline != nil && !projectReq.PeerReviewDeadline.IsZero() {
+		newProject.PeerReviewDeadline = &projectReq.PeerReviewDeadline.Time
+	}
+
+	if err := validatePeerReview(&newProject); err != nil {
+		return nil, err
+	}
+
+	newProject.TestTimeout = DefaultTestTimeout
+	if projectReq.TestTimeout != 0 {
+		newProject.TestTimeout = projectReq.TestTimeout
+	}
+
+	if err := validateTestTimeout(&newProject); err != nil {
+		return nil, err
+	}
+
+	newProject.MaxFileSize = middleware.MaxFileSize / (1024 * 1024)
+	if projectReq.MaxFileSize != 0 {
+		newProject.MaxFileSize = projectReq.MaxFileSize
+	}
+
+	fileTypes := projectReq.AllowedFileTypes
+	if len(fileTypes) == 0 {
+		fileTypes = middleware.DefaultFileTypes
+	}
+
+	if err := setFilePolicy(&newProject, fileTypes); err != nil {
+		return nil, err
+	}
+
+	if projectReq.RubricID != nil {
+		if newProject.RubricID, err = s.projectRubric(*projectReq.RubricID); err != nil {
+			return nil, err
+		}
+	}
+
+	if projectReq.CategoryID != nil {
+		if newProject.CategoryID, err = s.projectCategory(courseID, *projectReq.CategoryID); err != nil {
+			return nil, err
+		}
 	}
 
 	// input project to db
@@ -153,6 +282,117 @@ func (s *ProjectServiceImpl) UpdatedProjectByID(userClaims *middleware.UserClaim
 		projectExist.Description = projectReq.Description
 	}
 
+	if projectReq.Optional != nil {
+		projectExist.Optional = *projectReq.Optional
+	}
+
+	if projectReq.MaxAttempts != nil {
+		if *projectReq.MaxAttempts < 0 {
+			return nil, fmt.Errorf("max_attempts can't be negative")
+		}
+
+		projectExist.MaxAttempts = *projectReq.MaxAttempts
+	}
+
+	if projectReq.LatePolicy != "" {
+		projectExist.LatePolicy = projectReq.LatePolicy
+	}
+
+	if projectReq.GraceHours != nil {
+		projectExist.GraceHours = *projectReq.GraceHours
+	}
+
+	if projectReq.PenaltyPerDay != nil {
+		projectExist.PenaltyPerDay = *projectReq.PenaltyPerDay
+	}
+
+	if projectReq.PenaltyCap != nil {
+		projectExist.PenaltyCap = *projectReq.PenaltyCap
+	}
+
+	if err := validateLatePolicy(projectExist); err != nil {
+		return nil, err
+	}
+
+	if projectReq.TeamProject != nil {
+		projectExist.TeamProject = *projectReq.TeamProject
+	}
+
+	if projectReq.MinTeamSize != nil {
+		projectExist.MinTeamSize = *projectReq.MinTeamSize
+	}
+
+	if projectReq.MaxTeamSize != nil {
+		projectExist.MaxTeamSize = *projectReq.MaxTeamSize
+	}
+
+	if projectReq.SelfFormedTeams != nil {
+		projectExist.SelfFormedTeams = *projectReq.SelfFormedTeams
+	}
+
+	if err := validateTeamPolicy(projectExist); err != nil {
+		return nil, err
+	}
+
+	if projectReq.PeerReviewEnabled != nil {
+		projectExist.PeerReviewEnabled = *projectReq.PeerReviewEnabled
+	}
+
+	if projectReq.PeerReviewers != nil {
+		projectExist.PeerReviewers = *projectReq.PeerReviewers
+	}
+
+	if projectReq.PeerReviewDeadline != nil {
+		projectExist.PeerReviewDeadline = nil
+		if !projectReq.PeerReviewDeadline.IsZero() {
+			projectExist.PeerReviewDeadline = &projectReq.PeerReviewDeadline.Time
+		}
+	}
+
+	if projectReq.PeerWeight != nil {
+		projectExist.PeerWeight = *projectReq.PeerWeight
+	}
+
+	if err := validatePeerReview(projectExist); err != nil {
+		return nil, err
+	}
+
+	if projectReq.TestTimeout != nil {
+		projectExist.TestTimeout = *projectReq.TestTimeout
+	}
+
+	if err := validateTestTimeout(projectExist); err != nil {
+		return nil, err
+	}
+
+	if projectReq.MaxFileSize != nil {
+		projectExist.MaxFileSize = *projectReq.MaxFileSize
+	}
+
+	fileTypes := projectExist.FileTypes()
+	if projectReq.AllowedFileTypes != nil {
+		fileTypes = projectReq.AllowedFileTypes
+		if len(fileTypes) == 0 {
+			fileTypes = middleware.DefaultFileTypes
+		}
+	}
+
+	if err := setFilePolicy(projectExist, fileTypes); err != nil {
+		return nil, err
+	}
+
+	if projectReq.RubricID != nil {
+		if projectExist.RubricID, err = s.projectRubric(*projectReq.RubricID); err != nil {
+			return nil, err
+		}
+	}
+
+	if projectReq.CategoryID != nil {
+		if projectExist.CategoryID, err = s.projectCategory(courseID, *projectReq.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+
 	// update project
 	project, err := s.projectRepo.UpdateProjectByID(courseID, projectID, *projectExist)
 	if err != nil {
@@ -192,3 +432,231 @@ func (s *ProjectServiceImpl) DeleteProjectByID(userClaims *middleware.UserClaims
 
 	return nil
 }
+
+func validateLatePolicy(project *entity.Project) error {
+	if !project.LatePolicy.IsValid() {
+		return fmt.Errorf("late_policy must be one of %s, %s or %s", entity.LateCutoff, entity.LateGrace, entity.LatePenalty)
+	}
+
+	if project.GraceHours < 0 {
+		return fmt.Errorf("grace_hours can't be negative")
+	}
+
+	if project.PenaltyPerDay < 0 || project.PenaltyPerDay > 100 {
+		return fmt.Errorf("penalty_per_day must be between 0-100")
+	}
+
+	if project.PenaltyCap < 0 || project.PenaltyCap > 100 {
+		return fmt.Errorf("penalty_cap must be between 0-100")
+	}
+
+	if project.LatePolicy == entity.LateGrace && project.GraceHours == 0 {
+		return fmt.Errorf("grace_hours is required for %s policy", entity.LateGrace)
+	}
+
+	if project.LatePolicy == entity.LatePenalty && project.PenaltyPerDay == 0 {
+		return fmt.Errorf("penalty_per_day is required for %s policy", entity.LatePenalty)
+	}
+
+	return nil
+}
+
+func validateTeamPolicy(project *entity.Project) error {
+	if !project.TeamProject {
+		return nil
+	}
+
+	if project.MinTeamSize < 1 {
+		return fmt.Errorf("min_team_size must be at least 1")
+	}
+
+	if project.MaxTeamSize < project.MinTeamSize {
+		return fmt.Errorf("max_team_size must be at least min_team_size")
+	}
+
+	return nil
+}
+
+func validatePeerReview(project *entity.Project) error {
+	if !project.PeerReviewEnabled {
+		return nil
+	}
+
+	if project.PeerReviewers < 1 {
+		return fmt.Errorf("peer_reviewers must be at least 1")
+	}
+
+	if project.PeerWeight < 0 || project.PeerWeight > 100 {
+		return fmt.Errorf("peer_weight must be between 0-100")
+	}
+
+	if project.PeerReviewDeadline != nil && !project.PeerReviewDeadline.After(project.PeerReviewStart()) {
+		return fmt.Errorf("peer_review_deadline must be after the project deadline")
+	}
+
+	return nil
+}
+
+// file types must be known to the upload pipeline & size within the upload limit
+func setFilePolicy(project *entity.Project, fileTypes []string) error {
+	maxFileSize := middleware.MaxUploadSize / (1024 * 1024)
+	if project.MaxFileSize < 1 || project.MaxFileSize > maxFileSize {
+		return fmt.Errorf("max_file_size must be between 1-%d mb", maxFileSize)
+	}
+
+	normalized, err := middleware.NormalizeFileTypes(fileTypes)
+	if err != nil {
+		return err
+	}
+
+	project.AllowedFileTypes = strings.Join(normalized, ",")
+
+	return nil
+}
+
+// file types & size a submission of the project may have, autograded projects only take zips
+func projectFilePolicy(project *entity.Project) middleware.FilePolicy {
+	policy := middleware.FilePolicy{
+		FileTypes: project.FileTypes(),
+		MaxSize:   int64(project.MaxFileSize) * 1024 * 1024,
+	}
+
+	if len(policy.FileTypes) == 0 {
+		policy.FileTypes = middleware.DefaultFileTypes
+	}
+
+	if policy.MaxSize <= 0 {
+		policy.MaxSize = middleware.MaxFileSize
+	}
+
+	if project.TestSuiteKey != "" {
+		policy.FileTypes = []string{".zip"}
+	}
+
+	return policy
+}
+
+func validateTestTimeout(project *entity.Project) error {
+	if project.TestTimeout < 1 || project.TestTimeout > MaxTestTimeout {
+		return fmt.Errorf("test_timeout must be between 1-%d seconds", MaxTestTimeout)
+	}
+
+	return nil
+}
+
+// project must exist & mentor must own its course
+func (s *ProjectServiceImpl) getOwnProject(userClaims *middleware.UserClaims, courseID, projectID string) (*entity.Project, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can manage the project test suite")
+	}
+
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	project, err := s.projectRepo.GetProjectByID(courseID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project_id %s not found", projectID)
+	}
+
+	if userClaims.Role == entity.Mentor && userClaims.UserID != course.MentorID {
+		return nil, fmt.Errorf("user has no authority to this action")
+	}
+
+	return project, nil
+}
+
+// attach the hidden test suite, the previous suite file is removed
+func (s *ProjectServiceImpl) SetTestSuite(userClaims *middleware.UserClaims, courseID, projectID string, upload model.FileUpload) (*entity.Project, error) {
+	project, err := s.getOwnProject(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	suite, err := middleware.NewUploadReader(upload.File, upload.Name, testSuitePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	// archive is checked from a local copy before it goes to the storage
+	tmp, err := os.CreateTemp("", "suite-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to save file")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	size, err := io.Copy(tmp, suite)
+	if errors.Is(err, middleware.ErrFileTooLarge) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save file")
+	}
+
+	if err := autograde.CheckTestSuite(tmp.Name()); err != nil {
+		return nil, fmt.Errorf("invalid test suite: %v", err)
+	}
+
+	key, err := storage.NewKey(fmt.Sprintf("%s/%d", TestSuitePrefix, project.ProjectID), ".zip")
+	if err != nil {
+		return nil, fmt.Errorf("failed to save file")
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to save file")
+	}
+
+	if err := s.store.Put(context.Background(), key, tmp, size, "application/zip"); err != nil {
+		log.Printf("Error storing test suite %s: %v", key, err)
+		return nil, fmt.Errorf("failed to save file")
+	}
+
+	oldKey := project.TestSuiteKey
+	project.TestSuiteKey = key
+
+	updated, err := s.projectRepo.UpdateProjectByID(courseID, projectID, *project)
+	if err != nil {
+		s.removeTestSuite(key)
+		return nil, fmt.Errorf("unable to update project_id %s", projectID)
+	}
+
+	s.removeTestSuite(oldKey)
+
+	return updated, nil
+}
+
+// detach the test suite, submissions are graded by mentor only again
+func (s *ProjectServiceImpl) RemoveTestSuite(userClaims *middleware.UserClaims, courseID, projectID string) (*entity.Project, error) {
+	project, err := s.getOwnProject(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if project.TestSuiteKey == "" {
+		return nil, fmt.Errorf("project doesn't have a test suite")
+	}
+
+	oldKey := project.TestSuiteKey
+	project.TestSuiteKey = ""
+
+	updated, err := s.projectRepo.UpdateProjectByID(courseID, projectID, *project)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update project_id %s", projectID)
+	}
+
+	s.removeTestSuite(oldKey)
+
+	return updated, nil
+}
+
+func (s *ProjectServiceImpl) removeTestSuite(key string) {
+	if key == "" {
+		return
+	}
+
+	if err := s.store.Delete(context.Background(), key); err != nil {
+		log.Println("Error removing file:", err)
+	}
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/malware"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+	"github.com/nadyafa/go-learn/storage"
+)
+
+// how long a download link stays valid
+const DownloadURLExpiry = 15 * time.Minute
+
+type ProjectSubService interface {
+	StudentSubmitProject(userClaims *middleware.UserClaims, courseID, projectID string, upload model.FileUpload) (*entity.ProjectSub, error)
+	MentorSubmitScore(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string, projectSubReq model.ProjectSubMentor) (*entity.ProjectSub, error)
+	GetProjectSubmissions(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.ProjectSub, error)
+	GetProjectSubmissionByID(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) (*entity.ProjectSub, error)
+	GetProjectSubmissionHistory(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) ([]entity.ProjectSub, error)
+	DeleteProjectSubmissionByID(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) error
+	GetProjectSubmissionFile(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) (*storage.Link, error)
+	GetSubmissionPeerReviews(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) ([]entity.PeerReview, error)
+	GetSubmissionTestResults(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) (*entity.ProjectSub, []entity.TestResult, error)
+}
+
+type ProjectSubServiceImpl struct {
+	projectSubRepo repository.ProjectSubRepo
+	projectRepo    repository.ProjectRepo
+	courseRepo     repository.CourseRepo
+	enrollRepo     repository.EnrollRepo
+	extensionRepo  repository.ExtensionRepo
+	rubricRepo     repository.RubricRepo
+	teamRepo       repository.TeamRepo
+	peerReviewRepo repository.PeerReviewRepo
+	store          storage.Storage
+	scanner        malware.Scanner
+}
+
+func NewProjectSubService(projectSubRepo repository.ProjectSubRepo, projectRepo repository.ProjectRepo, courseRepo repository.CourseRepo, enrollRepo repository.EnrollRepo, extensionRepo repository.ExtensionRepo, rubricRepo repository.RubricRepo, teamRepo repository.TeamRepo, peerReviewRepo repository.PeerReviewRepo, store storage.Storage, scanner malware.Scanner) ProjectSubService {
+	return &ProjectSubServiceImpl{
+		projectSubRepo: projectSubRepo,
+		projectRepo:    projectRepo,
+		courseRepo:     courseRepo,
+		enrollRepo:     enrollRepo,
+		extensionRepo:  extensionRepo,
+		rubricRepo:     rubricRepo,
+		teamRepo:       teamRepo,
+		peerReviewRepo: peerReviewRepo,
+		store:          store,
+		scanner:        scanner,
+	}
+}
+
+// check course & project exist and the user can see the project submissions
+func (s *ProjectSubServiceImpl) checkProjectAccess(userClaims *middleware.UserClaims, courseID, projectID string) (*entity.Course, *entity.Project, error) {
+	// check if courseID exist
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("course not found")
+	}
+
+	// check if project belongs to the course
+	project, err := s.projectRepo.GetProjectByID(courseID, projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("project not found")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, nil, fmt.Errorf("mentor can only access submissions of their own course")
+	}
+
+	return course, project, nil
+}
+
+// submit project file, it is streamed to the storage once every check passes (student only)
+func (s *ProjectSubServiceImpl) StudentSubmitProject(userClaims *middleware.UserClaims, courseID, projectID string, upload model.FileUpload) (*entity.ProjectSub, error) {
+	if userClaims.Role != entity.Student {
+		return nil, fmt.Errorf("only student can submit a project")
+	}
+
+	course, project, err := s.checkProjectAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// student must be enrolled to the course
+	enroll, err := s.enrollRepo.GetStudentCourseEnroll(courseID, fmt.Sprint(userClaims.UserID))
+	if err != nil || enroll.EnrollStatus != entity.Enroll {
+		return nil, fmt.Errorf("student isn't enrolled to this course")
+	}
+
+	// validate current date with course startDate & endDate
+	currentTime := time.Now()
+	if currentTime.Before(course.StartDate) {
+		return nil, fmt.Errorf("submissions are not allowed before the course starts")
+	}
+
+	if currentTime.After(course.EndDate) {
+		return nil, fmt.Errorf("project submission period has ended")
+	}
+
+	// submissions are frozen once they have been handed out for peer review
+	if project.PeerAssignedAt != nil {
+		return nil, fmt.Errorf("submissions are closed, peer review has started")
+	}
+
+	// extension granted to the student replaces the project deadline
+	deadline := project.Deadline
+	if extension, err := s.extensionRepo.GetStudentExtension(project.ProjectID, userClaims.UserID); err == nil {
+		deadline = extension.Deadline
+	}
+
+	daysLate, latePenalty, ok := project.LateOutcome(deadline, currentTime)
+	if !ok {
+		return nil, fmt.Errorf("project deadline has passed")
+	}
+
+	// autograded project only takes a zip of go source
+	// declared size is checked first, the reader stops at the limit if it lied
+	policy := projectFilePolicy(project)
+	if upload.Size > policy.MaxSize {
+		return nil, fmt.Errorf("file size must be at most %s", middleware.FormatSize(policy.MaxSize))
+	}
+
+	file, err := middleware.NewUploadReader(upload.File, upload.Name, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	projectSub := entity.ProjectSub{
+		ProjectID:      project.ProjectID,
+		StudentID:      userClaims.UserID,
+		SubmissionDate: currentTime,
+		ProjectPath:    file.Name,
+		ContentType:    file.ContentType,
+		DaysLate:       daysLate,
+		LatePenalty:    latePenalty,
+	}
+
+	// file is quarantined until the scan job has checked it
+	if s.scanner != nil {
+		projectSub.ScanStatus = entity.ScanPending
+	}
+
+	if project.TestSuiteKey != "" {
+		projectSub.AutoStatus = entity.AutoGradePending
+	}
+
+	// one member submits for the whole team
+	if project.TeamProject {
+		team, err := s.teamRepo.GetStudentTeam(project.ProjectID, userClaims.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("student must join a team before submitting")
+		}
+
+		if len(team.Members) < project.MinTeamSize {
+			return nil, fmt.Errorf("team needs at least %d members to submit", project.MinTeamSize)
+		}
+
+		projectSub.TeamID = &team.TeamID
+	}
+
+	// stored key has nothing of the user file name
+	projectSub.FileKey, err = storage.NewKey(fmt.Sprintf("submissions/%d", project.ProjectID), file.Ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save file")
+	}
+
+	if err := s.store.Put(context.Background(), projectSub.FileKey, file, upload.Size, file.ContentType); err != nil {
+		if errors.Is(err, middleware.ErrFileTooLarge) {
+			return nil, fmt.Errorf("file size must be at most %s", middleware.FormatSize(policy.MaxSize))
+		}
+
+		log.Printf("Error storing submission file %s: %v", projectSub.FileKey, err)
+		return nil, fmt.Errorf("failed to save file")
+	}
+
+	// checksum is known once the file has been streamed
+	projectSub.FileSize = file.Size()
+	projectSub.FileHash = file.Checksum()
+
+	if err := s.projectSubRepo.CreateProjectSub(&projectSub, project.MaxAttempts); err != nil {
+		// file of a rejected submission is removed
+		if err := s.store.Delete(context.Background(), projectSub.FileKey); err != nil {
+			log.Println("Error removing file:", err)
+		}
+
+		if errors.Is(err, repository.ErrMaxAttempts) {
+			return nil, fmt.Errorf("maximum of %d submission attempts reached", project.MaxAttempts)
+		}
+
+		return nil, fmt.Errorf("failed to submit project")
+	}
+
+	return &projectSub, nil
+}
+
+// mentor scoring (course mentor only)
+func (s *ProjectSubServiceImpl) MentorSubmitScore(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string, projectSubReq model.ProjectSubMentor) (*entity.ProjectSub, error) {
+	if userClaims.Role != entity.Mentor {
+		return nil, fmt.Errorf("only mentor can score a submission")
+	}
+
+	_, project, err := s.checkProjectAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	projectSub, err := s.projectSubRepo.GetProjectSubByID(projectID, projectSubID)
+	if err != nil {
+		return nil, fmt.Errorf("project submission not found")
+	}
+
+	// earlier attempts are kept as history only
+	if !projectSub.IsLatest {
+		return nil, fmt.Errorf("only the latest attempt can be graded")
+	}
+
+	// mentor score replaces the auto score from now on
+	if projectSub.AutoStatus != "" {
+		projectSub.AutoOverridden = true
+	}
+
+	gradedAt := time.Now()
+	projectSub.GradedAt = &gradedAt
+
+	if project.RubricID != nil {
+		return s.gradeWithRubric(project, projectSub, projectSubReq)
+	}
+
+	// validation score
+	if projectSubReq.Score < 0 || projectSubReq.Score > 100 {
+		return nil, fmt.Errorf("score must be between 0-100")
+	}
+
+	if err := s.applyPeerScore(project, projectSub, projectSubReq.Score); err != nil {
+		return nil, err
+	}
+
+	if projectSubReq.Description != "" {
+		projectSub.Description = projectSubReq.Description
+	}
+
+	if err := s.projectSubRepo.UpdateProjectSub(projectSub); err != nil {
+		return nil, fmt.Errorf("failed to update project submission with ID %s", projectSubID)
+	}
+
+	return projectSub, nil
+}
+
+// score is the share of rubric points, every criterion must be graded once
+func (s *ProjectSubServiceImpl) gradeWithRubric(project *entity.Project, projectSub *entity.ProjectSub, projectSubReq model.ProjectSubMentor) (*entity.ProjectSub, error) {
+	rubric, err := s.rubricRepo.GetRubricByID(fmt.Sprint(*project.RubricID))
+	if err != nil {
+		return nil, fmt.Errorf("rubric not found")
+	}
+
+	grades, score, err := rubricGrades(rubric, projectSubReq.Criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyPeerScore(project, projectSub, score); err != nil {
+		return nil, err
+	}
+
+	if projectSubReq.Description != "" {
+		projectSub.Description = projectSubReq.Description
+	}
+
+	if err := s.projectSubRepo.GradeProjectSub(projectSub, grades); err != nil {
+		return nil, fmt.Errorf("failed to grade project submission with ID %d", projectSub.ProjectSubID)
+	}
+
+	return projectSub, nil
+}
+
+// mentor score is blended with the peer review average by the project peer weight
+func (s *ProjectSubServiceImpl) applyPeerScore(project *entity.Project, projectSub *entity.ProjectSub, mentorScore int) error {
+	projectSub.Score = mentorScore
+	projectSub.MentorScore = nil
+	projectSub.PeerScore = nil
+
+	if !project.PeerReviewEnabled || project.PeerWeight == 0 {
+		return nil
+	}
+
+	peerScore, err := s.peerReviewRepo.GetPeerAverage(projectSub.ProjectSubID)
+	if err != nil {
+		return fmt.Errorf("unable to get peer review score")
+	}
+
+	if peerScore == nil {
+		return nil
+	}
+
+	projectSub.MentorScore = &mentorScore
+	projectSub.PeerScore = peerScore
+	projectSub.Score = int(math.Round(float64(mentorScore)*(100-project.PeerWeight)/100 + *peerScore*project.PeerWeight/100))
+
+	return nil
+}
+
+// student sees every attempt of their own, mentor of the course & admin see the latest attempt of each student
+func (s *ProjectSubServiceImpl) GetProjectSubmissions(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.ProjectSub, error) {
+	if _, _, err := s.checkProjectAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	studentID := ""
+	if userClaims.Role == entity.Student {
+		studentID = fmt.Sprint(userClaims.UserID)
+	}
+
+	projectSubs, err := s.projectSubRepo.GetProjectSubs(projectID, studentID, userClaims.Role != entity.Student)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project submissions")
+	}
+
+	return projectSubs, nil
+}
+
+func (s *ProjectSubServiceImpl) GetProjectSubmissionByID(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) (*entity.ProjectSub, error) {
+	if _, _, err := s.checkProjectAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	projectSub, err := s.projectSubRepo.GetProjectSubByID(projectID, projectSubID)
+	if err != nil || (userClaims.Role == entity.Student && !s.isSubmissionOwner(userClaims.UserID, projectSub)) {
+		return nil, fmt.Errorf("project submission not found")
+	}
+
+	return projectSub, nil
+}
+
+// student made the submission or is in the team it was made for
+func (s *ProjectSubServiceImpl) isSubmissionOwner(studentID uint, projectSub *entity.ProjectSub) bool {
+	if projectSub.TeamID == nil {
+		return projectSub.StudentID == studentID
+	}
+
+	team, err := s.teamRepo.GetStudentTeam(projectSub.ProjectID, studentID)
+	return err == nil && team.TeamID == *projectSub.TeamID
+}
+
+// every attempt of the student or team who made the submission, newest first
+func (s *ProjectSubServiceImpl) GetProjectSubmissionHistory(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) ([]entity.ProjectSub, error) {
+	projectSub, err := s.GetProjectSubmissionByID(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		return nil, err
+	}
+
+	projectSubs, err := s.projectSubRepo.GetProjectSubHistory(projectSub)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get submission history")
+	}
+
+	return projectSubs, nil
+}
+
+// delete submission & its file (admin only)
+func (s *ProjectSubServiceImpl) DeleteProjectSubmissionByID(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) error {
+	if userClaims.Role != entity.Admin {
+		return fmt.Errorf("only admin can delete a project submission")
+	}
+
+	projectSub, err := s.GetProjectSubmissionByID(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.projectSubRepo.DeleteProjectSub(projectSub); err != nil {
+		return fmt.Errorf("unable to delete project submission")
+	}
+
+	if projectSub.FileKey != "" {
+		if err := s.store.Delete(context.Background(), projectSub.FileKey); err != nil {
+			log.Println("Error removing file:", err)
+		}
+	}
+
+	return nil
+}
+
+// expiring download link of the submission file under its original name
+func (s *ProjectSubServiceImpl) GetProjectSubmissionFile(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) (*storage.Link, error) {
+	projectSub, err := s.GetProjectSubmissionByID(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		return nil, err
+	}
+
+	if projectSub.FileKey == "" {
+		return nil, fmt.Errorf("submission file not found")
+	}
+
+	if err := checkFileScan(projectSub.ScanStatus, projectSub.ScanSignature); err != nil {
+		return nil, err
+	}
+
+	link, err := storage.NewLink(context.Background(), s.store, projectSub.FileKey, projectSub.ProjectPath, DownloadURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create download link")
+	}
+
+	return link, nil
+}
+
+// reviews of the submission, student only gets the submitted reviews of their own submission
+func (s *ProjectSubServiceImpl) GetSubmissionPeerReviews(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) ([]entity.PeerReview, error) {
+	projectSub, err := s.GetProjectSubmissionByID(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.peerReviewRepo.GetSubReviews(projectSub.ProjectSubID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get peer reviews")
+	}
+
+	if userClaims.Role != entity.Student {
+		return reviews, nil
+	}
+
+	submitted := make([]entity.PeerReview, 0, len(reviews))
+	for _, review := range reviews {
+		if review.SubmittedAt != nil {
+			submitted = append(submitted, review)
+		}
+	}
+
+	return submitted, nil
+}
+
+// auto grading outcome & results of the hidden tests, student only of their own submission
+func (s *ProjectSubServiceImpl) GetSubmissionTestResults(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) (*entity.ProjectSub, []entity.TestResult, error) {
+	projectSub, err := s.GetProjectSubmissionByID(userClaims, courseID, projectID, projectSubID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if projectSub.AutoStatus == "" {
+		return nil, nil, fmt.Errorf("project submission isn't auto graded")
+	}
+
+	results, err := s.projectSubRepo.GetTestResults(projectSub.ProjectSubID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get test results")
+	}
+
+	return projectSub, results, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+)
+
+type ReportService interface {
+	GetStudentAttendReports(userClaims *middleware.UserClaims, courseID string) ([]model.StudentAttendReport, error)
+	GetAtRiskStudents(userClaims *middleware.UserClaims, courseID string, threshold float64) ([]model.StudentAttendReport, error)
+	GetClassTurnoutReports(userClaims *middleware.UserClaims, courseID string) ([]model.ClassTurnoutReport, error)
+	GetAttendTrendReports(userClaims *middleware.UserClaims, courseID, interval string, loc *time.Location) ([]model.AttendTrendReport, error)
+}
+
+type ReportServiceImpl struct {
+	reportRepo repository.ReportRepo
+	courseRepo repository.CourseRepo
+}
+
+func NewReportService(reportRepo repository.ReportRepo, courseRepo repository.CourseRepo) ReportService {
+	return &ReportServiceImpl{
+		reportRepo: reportRepo,
+		courseRepo: courseRepo,
+	}
+}
+
+// only admin & course mentor can see course reports
+func (s *ReportServiceImpl) checkReportAccess(userClaims *middleware.UserClaims, courseID string) error {
+	if userClaims.Role == entity.Student {
+		return fmt.Errorf("only admin & mentor can see course reports")
+	}
+
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return fmt.Errorf("course_id %s not found", courseID)
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return fmt.Errorf("you prohibit to do this action")
+	}
+
+	return nil
+}
+
+func (s *ReportServiceImpl) GetStudentAttendReports(userClaims *middleware.UserClaims, courseID string) ([]model.StudentAttendReport, error) {
+	if err := s.checkReportAccess(userClaims, courseID); err != nil {
+		return nil, err
+	}
+
+	reports, err := s.reportRepo.GetStudentAttendReports(courseID, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch student attendance report")
+	}
+
+	return reports, nil
+}
+
+func (s *ReportServiceImpl) GetAtRiskStudents(userClaims *middleware.UserClaims, courseID string, threshold float64) ([]model.StudentAttendReport, error) {
+	if err := s.checkReportAccess(userClaims, courseID); err != nil {
+		return nil, err
+	}
+
+	if threshold <= 0 || threshold > 100 {
+		return nil, fmt.Errorf("threshold must be between 0-100")
+	}
+
+	reports, err := s.reportRepo.GetAtRiskStudents(courseID, time.Now(), threshold)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch at risk students")
+	}
+
+	return reports, nil
+}
+
+func (s *ReportServiceImpl) GetClassTurnoutReports(userClaims *middleware.UserClaims, courseID string) ([]model.ClassTurnoutReport, error) {
+	if err := s.checkReportAccess(userClaims, courseID); err != nil {
+		return nil, err
+	}
+
+	reports, err := s.reportRepo.GetClassTurnoutReports(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch class turnout report")
+	}
+
+	return reports, nil
+}
+
+func (s *ReportServiceImpl) GetAttendTrendReports(userClaims *middleware.UserClaims, courseID, interval string, loc *time.Location) ([]model.AttendTrendReport, error) {
+	if err := s.checkReportAccess(userClaims, courseID); err != nil {
+		return nil, err
+	}
+
+	// validate trend interval
+	if interval == "" {
+		interval = "week"
+	}
+
+	if interval != "day" && interval != "week" && interval != "month" {
+		return nil, fmt.Errorf("interval must be day, week or month")
+	}
+
+	// group period by user timezone
+	reports, err := s.reportRepo.GetAttendTrendReports(courseID, interval, loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch attendance trend report")
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+)
+
+type RoomService interface {
+	CreateRoom(userClaims *middleware.UserClaims, roomReq model.RoomReq) (*entity.Room, error)
+	GetRooms() ([]entity.Room, error)
+	GetRoomByID(roomID string) (*entity.Room, error)
+	UpdateRoomByID(userClaims *middleware.UserClaims, roomID string, roomReq model.RoomReq) (*entity.Room, error)
+	DeleteRoomByID(userClaims *middleware.UserClaims, roomID string) error
+}
+
+type RoomServiceImpl struct {
+	roomRepo repository.RoomRepo
+}
+
+func NewRoomService(roomRepo repository.RoomRepo) RoomService {
+	return &RoomServiceImpl{
+		roomRepo: roomRepo,
+	}
+}
+
+func (s *RoomServiceImpl) CreateRoom(userClaims *middleware.UserClaims, roomReq model.RoomReq) (*entity.Room, error) {
+	// admin only
+	if userClaims.Role != entity.Admin {
+		return nil, fmt.Errorf("only admin can create a new room")
+	}
+
+	// validate room input
+	isValid, errMsg := middleware.ValidateCourseName(roomReq.RoomName)
+	if !isValid {
+		return nil, errMsg
+	}
+
+	if roomReq.Capacity < 1 {
+		return nil, fmt.Errorf("room capacity must be at least 1")
+	}
+
+	room := entity.Room{
+		RoomName: roomReq.RoomName,
+		Location: roomReq.Location,
+		Capacity: roomReq.Capacity,
+	}
+
+	if err := s.roomRepo.CreateRoom(&room); err != nil {
+		return nil, fmt.Errorf("unable to create a new room")
+	}
+
+	return &room, nil
+}
+
+func (s *RoomServiceImpl) GetRooms() ([]entity.Room, error) {
+	rooms, err := s.roomRepo.GetRooms()
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch list of rooms")
+	}
+
+	return rooms, nil
+}
+
+func (s *RoomServiceImpl) GetRoomByID(roomID string) (*entity.Room, error) {
+	room, err := s.roomRepo.GetRoomByID(roomID)
+	if err != nil {
+		return nil, fmt.Errorf("room_id %s not found", roomID)
+	}
+
+	return room, nil
+}
+
+func (s *RoomServiceImpl) UpdateRoomByID(userClaims *middleware.UserClaims, roomID string, roomReq model.RoomReq) (*entity.Room, error) {
+	// admin only
+	if userClaims.Role != entity.Admin {
+		return nil, fmt.Errorf("only admin can update a room")
+	}
+
+	existingRoom, err := s.roomRepo.GetRoomByID(roomID)
+	if err != nil {
+		return nil, fmt.Errorf("room_id %s not found", roomID)
+	}
+
+	// update fields if not empty
+	if roomReq.RoomName != "" {
+		existingRoom.RoomName = roomReq.RoomName
+	}
+
+	if roomReq.Location != "" {
+		existingRoom.Location = roomReq.Location
+	}
+
+	if roomReq.Capacity < 0 {
+		return nil, fmt.Errorf("room capacity must be at least 1")
+	}
+
+	if roomReq.Capacity > 0 {
+		existingRoom.Capacity = roomReq.Capacity
+	}
+
+	if err := s.roomRepo.UpdateRoomByID(roomID, existingRoom); err != nil {
+		return nil, fmt.Errorf("unable to update room_id %s", roomID)
+	}
+
+	return existingRoom, nil
+}
+
+func (s *RoomServiceImpl) DeleteRoomByID(userClaims *middleware.UserClaims, roomID string) error {
+	// admin only
+	if userClaims.Role != entity.Admin {
+		return fmt.Errorf("only admin can delete a room")
+	}
+
+	if _, err := s.roomRepo.GetRoomByID(roomID); err != nil {
+		return fmt.Errorf("room_id %s not found", roomID)
+	}
+
+	if err := s.roomRepo.DeleteRoomByID(roomID); err != nil {
+		return fmt.Errorf("unable to delete room_id %s", roomID)
+	}
+
+	return nil
+}
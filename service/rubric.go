@@ -0,0 +1,275 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+)
+
+type RubricService interface {
+	CreateRubric(userClaims *middleware.UserClaims, rubricReq model.RubricReq) (*entity.Rubric, error)
+	GetRubrics(userClaims *middleware.UserClaims) ([]entity.Rubric, error)
+	GetRubricByID(userClaims *middleware.UserClaims, rubricID string) (*entity.Rubric, error)
+	UpdateRubric(userClaims *middleware.UserClaims, rubricID string, rubricReq model.RubricReq) (*entity.Rubric, error)
+	DeleteRubric(userClaims *middleware.UserClaims, rubricID string) error
+	GetProjectRubric(courseID, projectID string) (*entity.Rubric, error)
+}
+
+type RubricServiceImpl struct {
+	rubricRepo  repository.RubricRepo
+	projectRepo repository.ProjectRepo
+}
+
+func NewRubricService(rubricRepo repository.RubricRepo, projectRepo repository.ProjectRepo) RubricService {
+	return &RubricServiceImpl{
+		rubricRepo:  rubricRepo,
+		projectRepo: projectRepo,
+	}
+}
+
+// build rubric criteria from request, every criterion needs levels with distinct points
+func rubricCriteria(rubricReq model.RubricReq) ([]entity.RubricCriterion, error) {
+	if len(rubricReq.Criteria) == 0 {
+		return nil, fmt.Errorf("rubric needs at least one criterion")
+	}
+
+	criteria := make([]entity.RubricCriterion, 0, len(rubricReq.Criteria))
+	maxPoints := 0
+	for i, criterionReq := range rubricReq.Criteria {
+		title := strings.TrimSpace(criterionReq.Title)
+		if title == "" {
+			return nil, fmt.Errorf("criterion %d: title is required", i+1)
+		}
+
+		if len(criterionReq.Levels) == 0 {
+			return nil, fmt.Errorf("criterion %d: at least one level is required", i+1)
+		}
+
+		levels := make([]entity.RubricLevel, 0, len(criterionReq.Levels))
+		seen := make(map[int]bool)
+		best := 0
+		for _, levelReq := range criterionReq.Levels {
+			if levelReq.Points < 0 {
+				return nil, fmt.Errorf("criterion %d: points can't be negative", i+1)
+			}
+
+			if seen[levelReq.Points] {
+				return nil, fmt.Errorf("criterion %d: duplicate level with %d points", i+1, levelReq.Points)
+			}
+			seen[levelReq.Points] = true
+
+			descriptor := strings.TrimSpace(levelReq.Descriptor)
+			if descriptor == "" {
+				return nil, fmt.Errorf("criterion %d: level descriptor is required", i+1)
+			}
+
+			if levelReq.Points > best {
+				best = levelReq.Points
+			}
+
+			levels = append(levels, entity.RubricLevel{
+				Points:     levelReq.Points,
+				Descriptor: descriptor,
+			})
+		}
+		maxPoints += best
+
+		criteria = append(criteria, entity.RubricCriterion{
+			Title:       title,
+			Description: criterionReq.Description,
+			Position:    i + 1,
+			Levels:      levels,
+		})
+	}
+
+	if maxPoints == 0 {
+		return nil, fmt.Errorf("rubric must be worth more than 0 points")
+	}
+
+	return criteria, nil
+}
+
+// rubric is edited by its creator or admin
+func (s *RubricServiceImpl) getOwnedRubric(userClaims *middleware.UserClaims, rubricID string) (*entity.Rubric, error) {
+	rubric, err := s.GetRubricByID(userClaims, rubricID)
+	if err != nil {
+		return nil, err
+	}
+
+	if userClaims.Role != entity.Admin && rubric.CreatedBy != userClaims.UserID {
+		return nil, fmt.Errorf("only the rubric creator or admin can change a rubric")
+	}
+
+	return rubric, nil
+}
+
+func (s *RubricServiceImpl) CreateRubric(userClaims *middleware.UserClaims, rubricReq model.RubricReq) (*entity.Rubric, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can create a rubric")
+	}
+
+	criteria, err := rubricCriteria(rubricReq)
+	if err != nil {
+		return nil, err
+	}
+
+	rubric := entity.Rubric{
+		Title:       strings.TrimSpace(rubricReq.Title),
+		Description: rubricReq.Description,
+		CreatedBy:   userClaims.UserID,
+		Criteria:    criteria,
+	}
+
+	if err := s.rubricRepo.CreateRubric(&rubric); err != nil {
+		return nil, fmt.Errorf("unable to create rubric")
+	}
+
+	return &rubric, nil
+}
+
+// every rubric can be reused by any mentor
+func (s *RubricServiceImpl) GetRubrics(userClaims *middleware.UserClaims) ([]entity.Rubric, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can browse rubrics")
+	}
+
+	rubrics, err := s.rubricRepo.GetRubrics()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get rubrics")
+	}
+
+	return rubrics, nil
+}
+
+func (s *RubricServiceImpl) GetRubricByID(userClaims *middleware.UserClaims, rubricID string) (*entity.Rubric, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can browse rubrics")
+	}
+
+	rubric, err := s.rubricRepo.GetRubricByID(rubricID)
+	if err != nil {
+		return nil, fmt.Errorf("rubric not found")
+	}
+
+	return rubric, nil
+}
+
+// criteria are replaced as a whole, graded rubric is kept as it is
+func (s *RubricServiceImpl) UpdateRubric(userClaims *middleware.UserClaims, rubricID string, rubricReq model.RubricReq) (*entity.Rubric, error) {
+	rubric, err := s.getOwnedRubric(userClaims, rubricID)
+	if err != nil {
+		return nil, err
+	}
+
+	graded, err := s.rubricRepo.IsRubricGraded(rubric.RubricID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update rubric")
+	}
+	if graded {
+		return nil, fmt.Errorf("rubric has been used for grading, create a new rubric instead")
+	}
+
+	criteria, err := rubricCriteria(rubricReq)
+	if err != nil {
+		return nil, err
+	}
+
+	rubric.Title = strings.TrimSpace(rubricReq.Title)
+	rubric.Description = rubricReq.Description
+	rubric.Criteria = criteria
+
+	if err := s.rubricRepo.ReplaceRubric(rubric); err != nil {
+		return nil, fmt.Errorf("unable to update rubric")
+	}
+
+	return rubric, nil
+}
+
+func (s *RubricServiceImpl) DeleteRubric(userClaims *middleware.UserClaims, rubricID string) error {
+	rubric, err := s.getOwnedRubric(userClaims, rubricID)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.rubricRepo.IsRubricUsed(rubric.RubricID)
+	if err != nil {
+		return fmt.Errorf("unable to delete rubric")
+	}
+	if used {
+		return fmt.Errorf("rubric is attached to a project")
+	}
+
+	if err := s.rubricRepo.DeleteRubric(rubric); err != nil {
+		return fmt.Errorf("unable to delete rubric")
+	}
+
+	return nil
+}
+
+// students can read the rubric before they submit
+func (s *RubricServiceImpl) GetProjectRubric(courseID, projectID string) (*entity.Rubric, error) {
+	project, err := s.projectRepo.GetProjectByID(courseID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	if project.RubricID == nil {
+		return nil, fmt.Errorf("project has no rubric")
+	}
+
+	rubric, err := s.rubricRepo.GetRubricByID(fmt.Sprint(*project.RubricID))
+	if err != nil {
+		return nil, fmt.Errorf("rubric not found")
+	}
+
+	return rubric, nil
+}
+
+// criterion grades & score out of 100 from the picked levels, every criterion must be graded once
+func rubricGrades(rubric *entity.Rubric, gradeReqs []model.CriterionGradeReq) ([]entity.CriterionGrade, int, error) {
+	picked := make(map[uint]model.CriterionGradeReq)
+	for _, gradeReq := range gradeReqs {
+		if _, ok := picked[gradeReq.CriterionID]; ok {
+			return nil, 0, fmt.Errorf("criterion_id %d is graded more than once", gradeReq.CriterionID)
+		}
+		picked[gradeReq.CriterionID] = gradeReq
+	}
+
+	if len(picked) != len(rubric.Criteria) {
+		return nil, 0, fmt.Errorf("every one of the %d rubric criteria must be graded", len(rubric.Criteria))
+	}
+
+	grades := make([]entity.CriterionGrade, 0, len(rubric.Criteria))
+	points := 0
+	for _, criterion := range rubric.Criteria {
+		gradeReq, ok := picked[criterion.CriterionID]
+		if !ok {
+			return nil, 0, fmt.Errorf("criterion %q isn't graded", criterion.Title)
+		}
+
+		var level *entity.RubricLevel
+		for i := range criterion.Levels {
+			if criterion.Levels[i].LevelID == gradeReq.LevelID {
+				level = &criterion.Levels[i]
+			}
+		}
+		if level == nil {
+			return nil, 0, fmt.Errorf("level_id %d isn't a level of criterion %q", gradeReq.LevelID, criterion.Title)
+		}
+
+		points += level.Points
+		grades = append(grades, entity.CriterionGrade{
+			CriterionID: criterion.CriterionID,
+			LevelID:     level.LevelID,
+			Points:      level.Points,
+			Comment:     gradeReq.Comment,
+		})
+	}
+
+	return grades, int(math.Round(100 * float64(points) / float64(rubric.MaxPoints()))), nil
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/malware"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/repository"
+	"github.com/nadyafa/go-learn/storage"
+)
+
+// files scanned per job run
+const scanBatch = 20
+
+type ScanService interface {
+	RunPending() (int, error)
+	RescanSubmission(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) (*entity.ProjectSub, error)
+}
+
+type ScanServiceImpl struct {
+	scanRepo       repository.ScanRepo
+	projectSubRepo repository.ProjectSubRepo
+	projectRepo    repository.ProjectRepo
+	store          storage.Storage
+	scanner        malware.Scanner
+
+	// files stored before the scanner was enabled have been queued
+	queuedUnscanned atomic.Bool
+}
+
+func NewScanService(scanRepo repository.ScanRepo, projectSubRepo repository.ProjectSubRepo, projectRepo repository.ProjectRepo, store storage.Storage, scanner malware.Scanner) ScanService {
+	return &ScanServiceImpl{
+		scanRepo:       scanRepo,
+		projectSubRepo: projectSubRepo,
+		projectRepo:    projectRepo,
+		store:          store,
+		scanner:        scanner,
+	}
+}
+
+// scan quarantined submission files & excuse documents, an unreachable scanner ends the run & the files wait for the next one
+func (s *ScanServiceImpl) RunPending() (int, error) {
+	if s.scanner == nil {
+		return 0, nil
+	}
+
+	// first run quarantines files that were stored without a scan
+	if !s.queuedUnscanned.Load() {
+		queued, err := s.scanRepo.QueueUnscanned()
+		if err != nil {
+			return 0, err
+		}
+		if queued > 0 {
+			log.Printf("Queued %d stored files for malware scan", queued)
+		}
+		s.queuedUnscanned.Store(true)
+	}
+
+	projectSubs, err := s.scanRepo.GetPendingScans(scanBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	scanned := 0
+	for i := range projectSubs {
+		projectSub := &projectSubs[i]
+
+		status, signature, err := s.scan(projectSub.FileKey, fmt.Sprintf("submission %d", projectSub.ProjectSubID))
+		if err != nil {
+			return scanned, err
+		}
+
+		scannedAt := time.Now()
+		projectSub.ScanStatus = status
+		projectSub.ScanSignature = signature
+		projectSub.ScannedAt = &scannedAt
+
+		if err := s.scanRepo.SaveScan(projectSub); err != nil {
+			return scanned, err
+		}
+		scanned++
+	}
+
+	excuses, err := s.scanRepo.GetPendingExcuseScans(scanBatch)
+	if err != nil {
+		return scanned, err
+	}
+
+	for i := range excuses {
+		excuse := &excuses[i]
+
+		status, signature, err := s.scan(excuse.DocumentKey, fmt.Sprintf("excuse %d", excuse.ExcuseID))
+		if err != nil {
+			return scanned, err
+		}
+
+		scannedAt := time.Now()
+		excuse.ScanStatus = status
+		excuse.ScanSignature = signature
+		excuse.ScannedAt = &scannedAt
+
+		if err := s.scanRepo.SaveExcuseScan(excuse); err != nil {
+			return scanned, err
+		}
+		scanned++
+	}
+
+	return scanned, nil
+}
+
+// file is streamed from the storage straight to the scanner, only an unreachable scanner is returned as error
+func (s *ScanServiceImpl) scan(key, label string) (entity.ScanStatus, string, error) {
+	result, err := s.scanFile(key)
+	if errors.Is(err, malware.ErrUnavailable) {
+		return "", "", err
+	}
+
+	switch {
+	case err != nil:
+		log.Printf("Error scanning %s: %v", label, err)
+		return entity.ScanError, "", nil
+	case result.Infected:
+		log.Printf("Malware %s found in %s", result.Signature, label)
+		return entity.ScanInfected, result.Signature, nil
+	}
+
+	return entity.ScanClean, "", nil
+}
+
+func (s *ScanServiceImpl) scanFile(key string) (*malware.Result, error) {
+	if key == "" {
+		return nil, fmt.Errorf("file not found")
+	}
+
+	ctx := context.Background()
+
+	object, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Body.Close()
+
+	return s.scanner.Scan(ctx, object.Body)
+}
+
+// quarantined & infected files can't be downloaded
+func checkFileScan(status entity.ScanStatus, signature string) error {
+	switch status {
+	case entity.ScanPending:
+		return fmt.Errorf("file is waiting for the malware scan")
+	case entity.ScanInfected:
+		return fmt.Errorf("file is blocked, malware was found: %s", signature)
+	case entity.ScanError:
+		return fmt.Errorf("file is blocked, it couldn't be scanned")
+	}
+
+	return nil
+}
+
+// quarantine the file until it's scanned again, e.g. after a failed scan or a signature update (admin only)
+func (s *ScanServiceImpl) RescanSubmission(userClaims *middleware.UserClaims, courseID, projectID, projectSubID string) (*entity.ProjectSub, error) {
+	if userClaims.Role != entity.Admin {
+		return nil, fmt.Errorf("only admin can rescan a submission file")
+	}
+
+	if s.scanner == nil {
+		return nil, fmt.Errorf("malware scanning isn't enabled")
+	}
+
+	if _, err := s.projectRepo.GetProjectByID(courseID, projectID); err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	projectSub, err := s.projectSubRepo.GetProjectSubByID(projectID, projectSubID)
+	if err != nil || projectSub.FileKey == "" {
+		return nil, fmt.Errorf("project submission not found")
+	}
+
+	if err := s.scanRepo.QueueScan(projectSub); err != nil {
+		return nil, fmt.Errorf("unable to queue project submission")
+	}
+
+	projectSub.ScanStatus = entity.ScanPending
+	projectSub.ScanSignature = ""
+	projectSub.ScannedAt = nil
+
+	return projectSub, nil
+}
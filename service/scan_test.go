@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/malware"
+	"github.com/nadyafa/go-learn/storage"
+)
+
+func TestCheckFileScan(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    entity.ScanStatus
+		signature string
+		wantErr   string
+	}{
+		{"never queued", "", "", ""},
+		{"clean", entity.ScanClean, "", ""},
+		{"pending", entity.ScanPending, "", "file is waiting for the malware scan"},
+		{"infected", entity.ScanInfected, "Eicar-Test-Signature", "file is blocked, malware was found: Eicar-Test-Signature"},
+		{"scan failed", entity.ScanError, "", "file is blocked, it couldn't be scanned"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkFileScan(tt.status, tt.signature)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// scan repo keeping rows in memory
+type memoryScanRepo struct {
+	projectSubs []entity.ProjectSub
+	excuses     []entity.Excuse
+	queued      int
+}
+
+func (r *memoryScanRepo) GetPendingScans(limit int) ([]entity.ProjectSub, error) {
+	var pending []entity.ProjectSub
+	for _, projectSub := range r.projectSubs {
+		if projectSub.ScanStatus == entity.ScanPending && len(pending) < limit {
+			pending = append(pending, projectSub)
+		}
+	}
+	return pending, nil
+}
+
+func (r *memoryScanRepo) SaveScan(projectSub *entity.ProjectSub) error {
+	for i := range r.projectSubs {
+		if r.projectSubs[i].ProjectSubID == projectSub.ProjectSubID {
+			r.projectSubs[i] = *projectSub
+		}
+	}
+	return nil
+}
+
+func (r *memoryScanRepo) QueueScan(projectSub *entity.ProjectSub) error {
+	projectSub.ScanStatus = entity.ScanPending
+	return r.SaveScan(projectSub)
+}
+
+func (r *memoryScanRepo) QueueUnscanned() (int64, error) {
+	r.queued++
+
+	var queued int64
+	for i := range r.projectSubs {
+		if r.projectSubs[i].ScanStatus == "" && r.projectSubs[i].FileKey != "" {
+			r.projectSubs[i].ScanStatus = entity.ScanPending
+			queued++
+		}
+	}
+	for i := range r.excuses {
+		if r.excuses[i].ScanStatus == "" && r.excuses[i].DocumentKey != "" {
+			r.excuses[i].ScanStatus = entity.ScanPending
+			queued++
+		}
+	}
+	return queued, nil
+}
+
+func (r *memoryScanRepo) GetPendingExcuseScans(limit int) ([]entity.Excuse, error) {
+	var pending []entity.Excuse
+	for _, excuse := range r.excuses {
+		if excuse.ScanStatus == entity.ScanPending && len(pending) < limit {
+			pending = append(pending, excuse)
+		}
+	}
+	return pending, nil
+}
+
+func (r *memoryScanRepo) SaveExcuseScan(excuse *entity.Excuse) error {
+	for i := range r.excuses {
+		if r.excuses[i].ExcuseID == excuse.ExcuseID {
+			r.excuses[i] = *excuse
+		}
+	}
+	return nil
+}
+
+func TestScanRunPending(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir(), "http://localhost/files", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"submissions/1/clean.zip":   "clean",
+		"submissions/1/eicar.zip":   malware.EICAR,
+		"submissions/1/old.zip":     "stored before scanning",
+		"excuses/1/doctor-note.pdf": "%PDF-1.4 " + malware.EICAR,
+	}
+	for key, content := range files {
+		if err := store.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "application/zip"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := &memoryScanRepo{
+		projectSubs: []entity.ProjectSub{
+			{ProjectSubID: 1, FileKey: "submissions/1/clean.zip", ScanStatus: entity.ScanPending},
+			{ProjectSubID: 2, FileKey: "submissions/1/eicar.zip", ScanStatus: entity.ScanPending},
+			{ProjectSubID: 3, FileKey: "submissions/1/old.zip"},
+			{ProjectSubID: 4, FileKey: "submissions/1/missing.zip", ScanStatus: entity.ScanPending},
+			{ProjectSubID: 5},
+		},
+		excuses: []entity.Excuse{
+			{ExcuseID: 1, DocumentKey: "excuses/1/doctor-note.pdf"},
+			{ExcuseID: 2},
+		},
+	}
+
+	scanner := malware.NewFakeScanner()
+	service := &ScanServiceImpl{scanRepo: repo, store: store, scanner: scanner}
+
+	scanned, err := service.RunPending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scanned != 5 {
+		t.Fatalf("got %d scanned, want 5", scanned)
+	}
+
+	wantSubs := map[uint]entity.ScanStatus{1: entity.ScanClean, 2: entity.ScanInfected, 3: entity.ScanClean, 4: entity.ScanError, 5: ""}
+	for _, projectSub := range repo.projectSubs {
+		if projectSub.ScanStatus != wantSubs[projectSub.ProjectSubID] {
+			t.Fatalf("submission %d got %q, want %q", projectSub.ProjectSubID, projectSub.ScanStatus, wantSubs[projectSub.ProjectSubID])
+		}
+	}
+	if repo.projectSubs[1].ScanSignature != "Eicar-Test-Signature" {
+		t.Fatalf("got signature %q", repo.projectSubs[1].ScanSignature)
+	}
+
+	wantExcuses := map[uint]entity.ScanStatus{1: entity.ScanInfected, 2: ""}
+	for _, excuse := range repo.excuses {
+		if excuse.ScanStatus != wantExcuses[excuse.ExcuseID] {
+			t.Fatalf("excuse %d got %q, want %q", excuse.ExcuseID, excuse.ScanStatus, wantExcuses[excuse.ExcuseID])
+		}
+	}
+
+	// stored files are only queued on the first run, an unreachable scanner leaves files pending
+	repo.projectSubs = append(repo.projectSubs, entity.ProjectSub{ProjectSubID: 6, FileKey: "submissions/1/clean.zip", ScanStatus: entity.ScanPending})
+	scanner.Err = malware.ErrUnavailable
+
+	if _, err := service.RunPending(); !errors.Is(err, malware.ErrUnavailable) {
+		t.Fatalf("got %v, want ErrUnavailable", err)
+	}
+	if repo.queued != 1 {
+		t.Fatalf("stored files queued %d times, want once", repo.queued)
+	}
+	if repo.projectSubs[5].ScanStatus != entity.ScanPending {
+		t.Fatalf("got %q, want the file to stay pending", repo.projectSubs[5].ScanStatus)
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+	"github.com/nadyafa/go-learn/similarity"
+	"github.com/nadyafa/go-learn/storage"
+)
+
+// share of shingles two submissions have in common before they are reported
+const DefaultSimilarityThreshold = 0.3
+
+type SimilarityService interface {
+	AnalyzeProject(userClaims *middleware.UserClaims, courseID, projectID string, analyzeReq model.SimilarityReq) (*entity.SimilarityReport, error)
+	AnalyzeDueProjects() (int, error)
+	GetReports(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.SimilarityReport, error)
+	GetReportByID(userClaims *middleware.UserClaims, courseID, projectID, reportID string) (*entity.SimilarityReport, error)
+}
+
+type SimilarityServiceImpl struct {
+	similarityRepo repository.SimilarityRepo
+	projectSubRepo repository.ProjectSubRepo
+	projectRepo    repository.ProjectRepo
+	courseRepo     repository.CourseRepo
+	store          storage.Storage
+}
+
+func NewSimilarityService(similarityRepo repository.SimilarityRepo, projectSubRepo repository.ProjectSubRepo, projectRepo repository.ProjectRepo, courseRepo repository.CourseRepo, store storage.Storage) SimilarityService {
+	return &SimilarityServiceImpl{
+		similarityRepo: similarityRepo,
+		projectSubRepo: projectSubRepo,
+		projectRepo:    projectRepo,
+		courseRepo:     courseRepo,
+		store:          store,
+	}
+}
+
+// reports are for course staff only, mentor must own the course
+func (s *SimilarityServiceImpl) checkReportAccess(userClaims *middleware.UserClaims, courseID, projectID string) (*entity.Project, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can access similarity reports")
+	}
+
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	project, err := s.projectRepo.GetProjectByID(courseID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("mentor can only access similarity reports of their own course")
+	}
+
+	return project, nil
+}
+
+// start analysis in background (admin & mentor), the report is done once its status changes
+func (s *SimilarityServiceImpl) AnalyzeProject(userClaims *middleware.UserClaims, courseID, projectID string, analyzeReq model.SimilarityReq) (*entity.SimilarityReport, error) {
+	project, err := s.checkReportAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	threshold := DefaultSimilarityThreshold
+	if analyzeReq.Threshold != nil {
+		if *analyzeReq.Threshold <= 0 || *analyzeReq.Threshold > 1 {
+			return nil, fmt.Errorf("threshold must be greater than 0 and at most 1")
+		}
+		threshold = *analyzeReq.Threshold
+	}
+
+	report, err := s.startReport(project, threshold, userClaims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.runReport(project, report)
+
+	return report, nil
+}
+
+// analyze every project with submissions newer than its last report, returns the number of reports made
+func (s *SimilarityServiceImpl) AnalyzeDueProjects() (int, error) {
+	projects, err := s.similarityRepo.GetProjectsToAnalyze(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	analyzed := 0
+	for i := range projects {
+		report, err := s.startReport(&projects[i], DefaultSimilarityThreshold, 0)
+		if err != nil {
+			log.Printf("Error analyzing similarity of project %d: %v", projects[i].ProjectID, err)
+			continue
+		}
+
+		s.runReport(&projects[i], report)
+		analyzed++
+	}
+
+	return analyzed, nil
+}
+
+func (s *SimilarityServiceImpl) startReport(project *entity.Project, threshold float64, requestedBy uint) (*entity.SimilarityReport, error) {
+	report := entity.SimilarityReport{
+		ProjectID:   project.ProjectID,
+		Status:      entity.ReportRunning,
+		Threshold:   threshold,
+		RequestedBy: requestedBy,
+	}
+
+	if err := s.similarityRepo.CreateReport(&report); err != nil {
+		if errors.Is(err, repository.ErrReportRunning) {
+			return nil, fmt.Errorf("similarity analysis of this project is already running")
+		}
+
+		return nil, fmt.Errorf("unable to start similarity analysis")
+	}
+
+	return &report, nil
+}
+
+// analyzed latest submission with its file hash & text
+type analyzedSub struct {
+	projectSub *entity.ProjectSub
+	document   *similarity.Document
+}
+
+// compare every pair of latest submissions, identical files are exact duplicates
+func (s *SimilarityServiceImpl) runReport(project *entity.Project, report *entity.SimilarityReport) {
+	if err := s.compareSubmissions(project, report); err != nil {
+		report.Status = entity.ReportFailed
+		report.Error = err.Error()
+		report.Pairs = nil
+	} else {
+		report.Status = entity.ReportDone
+	}
+
+	completedAt := time.Now()
+	report.CompletedAt = &completedAt
+
+	if err := s.similarityRepo.SaveReport(report); err != nil {
+		log.Printf("Error saving similarity report %d: %v", report.ReportID, err)
+	}
+}
+
+func (s *SimilarityServiceImpl) compareSubmissions(project *entity.Project, report *entity.SimilarityReport) error {
+	projectSubs, err := s.projectSubRepo.GetProjectSubs(fmt.Sprint(project.ProjectID), "", true)
+	if err != nil {
+		return fmt.Errorf("unable to get project submissions")
+	}
+
+	subs := make([]analyzedSub, 0, len(projectSubs))
+	for i := range projectSubs {
+		projectSub := &projectSubs[i]
+
+		sub, err := s.analyzeSubmission(projectSub)
+		if err != nil {
+			report.Skipped++
+			continue
+		}
+
+		// without text only an exact duplicate can be found
+		if sub.document == nil || sub.document.Empty() {
+			report.Skipped++
+		}
+
+		subs = append(subs, *sub)
+	}
+
+	report.Analyzed = len(subs)
+
+	for i := 0; i < len(subs); i++ {
+		for j := i + 1; j < len(subs); j++ {
+			if pair, ok := comparePair(subs[i], subs[j], report.Threshold); ok {
+				report.Pairs = append(report.Pairs, pair)
+			}
+		}
+	}
+
+	return nil
+}
+
+// hash & text of a submission file, fetched from the storage into a temporary file
+func (s *SimilarityServiceImpl) analyzeSubmission(projectSub *entity.ProjectSub) (*analyzedSub, error) {
+	if projectSub.FileKey == "" {
+		return nil, fmt.Errorf("submission file not found")
+	}
+
+	// quarantined & infected files are never opened
+	if !projectSub.ScanStatus.Released() {
+		return nil, fmt.Errorf("submission file hasn't passed the malware scan")
+	}
+
+	filePath, err := storage.TempFile(context.Background(), s.store, projectSub.FileKey)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(filePath)
+
+	if projectSub.FileHash == "" {
+		fileHash, err := similarity.HashFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		projectSub.FileHash = fileHash
+		if err := s.similarityRepo.UpdateFileHash(projectSub.ProjectSubID, fileHash); err != nil {
+			log.Printf("Error saving file hash of submission %d: %v", projectSub.ProjectSubID, err)
+		}
+	}
+
+	sub := analyzedSub{projectSub: projectSub}
+	if text, ok, err := similarity.ExtractText(filePath); err == nil && ok {
+		sub.document = similarity.NewDocument(text)
+	}
+
+	return &sub, nil
+}
+
+func comparePair(a, b analyzedSub, threshold float64) (entity.SimilarityPair, bool) {
+	pair := entity.SimilarityPair{
+		ProjectSubID:   a.projectSub.ProjectSubID,
+		StudentID:      a.projectSub.StudentID,
+		MatchSubID:     b.projectSub.ProjectSubID,
+		MatchStudentID: b.projectSub.StudentID,
+	}
+
+	if a.projectSub.FileHash == b.projectSub.FileHash {
+		pair.ExactDuplicate = true
+		pair.Similarity = 1
+		return pair, true
+	}
+
+	if a.document == nil || b.document == nil {
+		return pair, false
+	}
+
+	match := similarity.Compare(a.document, b.document)
+	if match.Similarity < threshold {
+		return pair, false
+	}
+
+	pair.Similarity = match.Similarity
+	for _, segment := range match.Segments {
+		pair.Segments = append(pair.Segments, entity.SimilaritySegment{
+			Text:  segment.Text,
+			Words: segment.Words,
+		})
+	}
+
+	return pair, true
+}
+
+func (s *SimilarityServiceImpl) GetReports(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.SimilarityReport, error) {
+	if _, err := s.checkReportAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	reports, err := s.similarityRepo.GetReports(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get similarity reports")
+	}
+
+	return reports, nil
+}
+
+func (s *SimilarityServiceImpl) GetReportByID(userClaims *middleware.UserClaims, courseID, projectID, reportID string) (*entity.SimilarityReport, error) {
+	if _, err := s.checkReportAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	report, err := s.similarityRepo.GetReportByID(projectID, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("similarity report not found")
+	}
+
+	return report, nil
+}
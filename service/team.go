@@ -0,0 +1,438 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/nadyafa/go-learn/entity"
+	"github.com/nadyafa/go-learn/middleware"
+	"github.com/nadyafa/go-learn/model"
+	"github.com/nadyafa/go-learn/repository"
+)
+
+type TeamService interface {
+	CreateTeam(userClaims *middleware.UserClaims, courseID, projectID string, teamReq model.TeamReq) (*entity.Team, error)
+	GenerateTeams(userClaims *middleware.UserClaims, courseID, projectID string, generateReq model.GenerateTeamsReq) ([]entity.Team, error)
+	GetTeams(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.Team, error)
+	GetTeamByID(userClaims *middleware.UserClaims, courseID, projectID, teamID string) (*entity.Team, error)
+	JoinTeam(userClaims *middleware.UserClaims, courseID, projectID, teamID string) (*entity.Team, error)
+	LeaveTeam(userClaims *middleware.UserClaims, courseID, projectID, teamID string) error
+	AdjustMember(userClaims *middleware.UserClaims, courseID, projectID, teamID, studentID string, memberReq model.TeamMemberReq) (*entity.TeamMember, error)
+	RemoveMember(userClaims *middleware.UserClaims, courseID, projectID, teamID, studentID string) error
+	DeleteTeam(userClaims *middleware.UserClaims, courseID, projectID, teamID string) error
+}
+
+type TeamServiceImpl struct {
+	teamRepo    repository.TeamRepo
+	projectRepo repository.ProjectRepo
+	courseRepo  repository.CourseRepo
+	enrollRepo  repository.EnrollRepo
+}
+
+func NewTeamService(teamRepo repository.TeamRepo, projectRepo repository.ProjectRepo, courseRepo repository.CourseRepo, enrollRepo repository.EnrollRepo) TeamService {
+	return &TeamServiceImpl{
+		teamRepo:    teamRepo,
+		projectRepo: projectRepo,
+		courseRepo:  courseRepo,
+		enrollRepo:  enrollRepo,
+	}
+}
+
+// check course & team project exist and mentor owns the course
+func (s *TeamServiceImpl) checkTeamAccess(userClaims *middleware.UserClaims, courseID, projectID string) (*entity.Project, error) {
+	course, err := s.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	project, err := s.projectRepo.GetProjectByID(courseID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	if !project.TeamProject {
+		return nil, fmt.Errorf("project isn't a team project")
+	}
+
+	if userClaims.Role == entity.Mentor && course.MentorID != userClaims.UserID {
+		return nil, fmt.Errorf("mentor can only manage teams of their own course")
+	}
+
+	return project, nil
+}
+
+func (s *TeamServiceImpl) checkEnrolled(courseID string, studentID uint) error {
+	enroll, err := s.enrollRepo.GetStudentCourseEnroll(courseID, fmt.Sprint(studentID))
+	if err != nil || enroll.EnrollStatus != entity.Enroll {
+		return fmt.Errorf("student_id %d isn't enrolled to this course", studentID)
+	}
+
+	return nil
+}
+
+// team must belong to the project
+func (s *TeamServiceImpl) getTeam(projectID, teamID string) (*entity.Team, error) {
+	team, err := s.teamRepo.GetTeamByID(projectID, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("team not found")
+	}
+
+	return team, nil
+}
+
+func (s *TeamServiceImpl) CreateTeam(userClaims *middleware.UserClaims, courseID, projectID string, teamReq model.TeamReq) (*entity.Team, error) {
+	project, err := s.checkTeamAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	studentIDs := teamReq.StudentIDs
+	if userClaims.Role == entity.Student {
+		if !project.SelfFormedTeams {
+			return nil, fmt.Errorf("teams of this project are formed by mentor")
+		}
+		studentIDs = []uint{userClaims.UserID}
+	}
+
+	if len(studentIDs) == 0 {
+		return nil, fmt.Errorf("student_ids is required")
+	}
+
+	if len(studentIDs) > project.MaxTeamSize {
+		return nil, fmt.Errorf("team can have at most %d members", project.MaxTeamSize)
+	}
+
+	teamed, err := s.teamRepo.GetTeamedStudentIDs(project.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project teams")
+	}
+
+	inTeam := make(map[uint]bool)
+	for _, studentID := range teamed {
+		inTeam[studentID] = true
+	}
+
+	team := entity.Team{
+		ProjectID: project.ProjectID,
+		Name:      strings.TrimSpace(teamReq.Name),
+		CreatedBy: userClaims.UserID,
+	}
+
+	for _, studentID := range studentIDs {
+		if inTeam[studentID] {
+			return nil, fmt.Errorf("student_id %d already has a team", studentID)
+		}
+		inTeam[studentID] = true
+
+		if err := s.checkEnrolled(courseID, studentID); err != nil {
+			return nil, err
+		}
+
+		team.Members = append(team.Members, entity.TeamMember{
+			ProjectID: project.ProjectID,
+			StudentID: studentID,
+		})
+	}
+
+	if team.Name == "" {
+		team.Name = fmt.Sprintf("Team %d", len(teamed)+1)
+	}
+
+	teams := []entity.Team{team}
+	if err := s.teamRepo.CreateTeams(teams); err != nil {
+		return nil, fmt.Errorf("unable to create team")
+	}
+
+	return &teams[0], nil
+}
+
+// enrolled students without a team are shuffled into balanced teams (admin & mentor)
+func (s *TeamServiceImpl) GenerateTeams(userClaims *middleware.UserClaims, courseID, projectID string, generateReq model.GenerateTeamsReq) ([]entity.Team, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can generate teams")
+	}
+
+	project, err := s.checkTeamAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	teamSize := generateReq.TeamSize
+	if teamSize == 0 {
+		teamSize = project.MaxTeamSize
+	}
+
+	if teamSize < project.MinTeamSize || teamSize > project.MaxTeamSize {
+		return nil, fmt.Errorf("team_size must be between %d-%d", project.MinTeamSize, project.MaxTeamSize)
+	}
+
+	enrolls, _, err := s.enrollRepo.SearchEnrolls(model.EnrollFilter{CourseID: project.CourseID, Status: entity.Enroll})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get enrolled students")
+	}
+
+	teamed, err := s.teamRepo.GetTeamedStudentIDs(project.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project teams")
+	}
+
+	inTeam := make(map[uint]bool)
+	for _, studentID := range teamed {
+		inTeam[studentID] = true
+	}
+
+	var studentIDs []uint
+	for _, enroll := range enrolls {
+		if !inTeam[enroll.StudentID] {
+			studentIDs = append(studentIDs, enroll.StudentID)
+		}
+	}
+
+	if len(studentIDs) == 0 {
+		return nil, fmt.Errorf("every enrolled student already has a team")
+	}
+
+	rand.Shuffle(len(studentIDs), func(i, j int) {
+		studentIDs[i], studentIDs[j] = studentIDs[j], studentIDs[i]
+	})
+
+	// round robin keeps team sizes within one of each other
+	existing, err := s.teamRepo.GetTeams(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get project teams")
+	}
+
+	count := (len(studentIDs) + teamSize - 1) / teamSize
+	teams := make([]entity.Team, count)
+	for i := range teams {
+		teams[i] = entity.Team{
+			ProjectID: project.ProjectID,
+			Name:      fmt.Sprintf("Team %d", len(existing)+i+1),
+			CreatedBy: userClaims.UserID,
+		}
+	}
+
+	for i, studentID := range studentIDs {
+		teams[i%count].Members = append(teams[i%count].Members, entity.TeamMember{
+			ProjectID: project.ProjectID,
+			StudentID: studentID,
+		})
+	}
+
+	if err := s.teamRepo.CreateTeams(teams); err != nil {
+		return nil, fmt.Errorf("unable to generate teams")
+	}
+
+	return teams, nil
+}
+
+func (s *TeamServiceImpl) GetTeams(userClaims *middleware.UserClaims, courseID, projectID string) ([]entity.Team, error) {
+	if _, err := s.checkTeamAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	teams, err := s.teamRepo.GetTeams(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get teams")
+	}
+
+	return teams, nil
+}
+
+func (s *TeamServiceImpl) GetTeamByID(userClaims *middleware.UserClaims, courseID, projectID, teamID string) (*entity.Team, error) {
+	if _, err := s.checkTeamAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	return s.getTeam(projectID, teamID)
+}
+
+// student joins a self formed team (student only)
+func (s *TeamServiceImpl) JoinTeam(userClaims *middleware.UserClaims, courseID, projectID, teamID string) (*entity.Team, error) {
+	if userClaims.Role != entity.Student {
+		return nil, fmt.Errorf("only student can join a team")
+	}
+
+	project, err := s.checkTeamAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !project.SelfFormedTeams {
+		return nil, fmt.Errorf("teams of this project are formed by mentor")
+	}
+
+	if err := s.checkEnrolled(courseID, userClaims.UserID); err != nil {
+		return nil, err
+	}
+
+	team, err := s.getTeam(projectID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.teamRepo.GetStudentTeam(project.ProjectID, userClaims.UserID); err == nil {
+		return nil, fmt.Errorf("student already has a team")
+	}
+
+	member := entity.TeamMember{
+		TeamID:    team.TeamID,
+		ProjectID: project.ProjectID,
+		StudentID: userClaims.UserID,
+	}
+
+	if err := s.teamRepo.AddMember(&member, project.MaxTeamSize); err != nil {
+		if errors.Is(err, repository.ErrTeamFull) {
+			return nil, fmt.Errorf("team already has %d members", project.MaxTeamSize)
+		}
+
+		return nil, fmt.Errorf("unable to join team")
+	}
+
+	team.Members = append(team.Members, member)
+
+	return team, nil
+}
+
+// student leaves a team that hasn't submitted (student only)
+func (s *TeamServiceImpl) LeaveTeam(userClaims *middleware.UserClaims, courseID, projectID, teamID string) error {
+	if userClaims.Role != entity.Student {
+		return fmt.Errorf("only student can leave a team")
+	}
+
+	project, err := s.checkTeamAccess(userClaims, courseID, projectID)
+	if err != nil {
+		return err
+	}
+
+	if !project.SelfFormedTeams {
+		return fmt.Errorf("teams of this project are formed by mentor")
+	}
+
+	team, err := s.getTeam(projectID, teamID)
+	if err != nil {
+		return err
+	}
+
+	submitted, err := s.teamRepo.HasTeamSubmissions(team.TeamID)
+	if err != nil {
+		return fmt.Errorf("unable to leave team")
+	}
+	if submitted {
+		return fmt.Errorf("team has submitted, ask mentor to change the team")
+	}
+
+	if err := s.teamRepo.RemoveMember(team, userClaims.UserID); err != nil {
+		return fmt.Errorf("student isn't a member of this team")
+	}
+
+	return nil
+}
+
+// points added to or taken from a member's team score (admin & mentor)
+func (s *TeamServiceImpl) AdjustMember(userClaims *middleware.UserClaims, courseID, projectID, teamID, studentID string, memberReq model.TeamMemberReq) (*entity.TeamMember, error) {
+	if userClaims.Role == entity.Student {
+		return nil, fmt.Errorf("only admin & mentor can adjust member grade")
+	}
+
+	if _, err := s.checkTeamAccess(userClaims, courseID, projectID); err != nil {
+		return nil, err
+	}
+
+	team, err := s.getTeam(projectID, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	if *memberReq.Adjustment < -100 || *memberReq.Adjustment > 100 {
+		return nil, fmt.Errorf("adjustment must be between -100 and 100")
+	}
+
+	for i := range team.Members {
+		if fmt.Sprint(team.Members[i].StudentID) != studentID {
+			continue
+		}
+
+		member := &team.Members[i]
+		member.Adjustment = *memberReq.Adjustment
+
+		if err := s.teamRepo.UpdateMember(member); err != nil {
+			return nil, fmt.Errorf("unable to adjust member grade")
+		}
+
+		return member, nil
+	}
+
+	return nil, fmt.Errorf("student isn't a member of this team")
+}
+
+// member loses the team grade (admin & mentor)
+func (s *TeamServiceImpl) RemoveMember(userClaims *middleware.UserClaims, courseID, projectID, teamID, studentID string) error {
+	if userClaims.Role == entity.Student {
+		return fmt.Errorf("only admin & mentor can remove a member")
+	}
+
+	if _, err := s.checkTeamAccess(userClaims, courseID, projectID); err != nil {
+		return err
+	}
+
+	team, err := s.getTeam(projectID, teamID)
+	if err != nil {
+		return err
+	}
+
+	// submissions of the team must keep at least one member
+	if len(team.Members) == 1 {
+		submitted, err := s.teamRepo.HasTeamSubmissions(team.TeamID)
+		if err != nil {
+			return fmt.Errorf("unable to remove member")
+		}
+		if submitted {
+			return fmt.Errorf("last member of a team with submissions can't be removed")
+		}
+	}
+
+	for _, member := range team.Members {
+		if fmt.Sprint(member.StudentID) == studentID {
+			if err := s.teamRepo.RemoveMember(team, member.StudentID); err != nil {
+				return fmt.Errorf("unable to remove member")
+			}
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("student isn't a member of this team")
+}
+
+// team that hasn't submitted can be deleted (admin & mentor)
+func (s *TeamServiceImpl) DeleteTeam(userClaims *middleware.UserClaims, courseID, projectID, teamID string) error {
+	if userClaims.Role == entity.Student {
+		return fmt.Errorf("only admin & mentor can delete a team")
+	}
+
+	if _, err := s.checkTeamAccess(userClaims, courseID, projectID); err != nil {
+		return err
+	}
+
+	team, err := s.getTeam(projectID, teamID)
+	if err != nil {
+		return err
+	}
+
+	submitted, err := s.teamRepo.HasTeamSubmissions(team.TeamID)
+	if err != nil {
+		return fmt.Errorf("unable to delete team")
+	}
+	if submitted {
+		return fmt.Errorf("team has submissions and can't be deleted")
+	}
+
+	if err := s.teamRepo.DeleteTeam(team); err != nil {
+		return fmt.Errorf("unable to delete team")
+	}
+
+	return nil
+}
@@ -17,6 +17,7 @@ type UserService interface {
 	GetUserByID(userID string, userClaims *middleware.UserClaims) (*entity.User, error)
 	UpdateUserRoleByID(userClaims *middleware.UserClaims, userID string, role string) (*entity.User, error)
 	DeleteUserByID(userClaims *middleware.UserClaims, userID string) error
+	UpdateUserTimezone(userClaims *middleware.UserClaims, timezone string) (*entity.User, error)
 }
 
 type UserServiceImpl struct {
@@ -147,3 +148,25 @@ func (s *UserServiceImpl) DeleteUserByID(userClaims *middleware.UserClaims, user
 
 	return nil
 }
+
+func (s *UserServiceImpl) UpdateUserTimezone(userClaims *middleware.UserClaims, timezone string) (*entity.User, error) {
+	// validate timezone input
+	if _, err := middleware.LoadTimezone(timezone); err != nil {
+		return nil, err
+	}
+
+	userID := fmt.Sprint(userClaims.UserID)
+
+	// update user timezone
+	if err := s.userRepo.UpdateUserTimezone(userID, timezone); err != nil {
+		return nil, fmt.Errorf("unable to update user timezone")
+	}
+
+	// return user value
+	user, err := s.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	return user, nil
+}
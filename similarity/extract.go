@@ -0,0 +1,225 @@
+package similarity
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// text of a single file or archive entry read for comparison at most
+const maxTextSize = 5 * 1024 * 1024
+
+// files compared as plain text, archives are searched for these too
+var textExtensions = map[string]bool{
+	".txt": true, ".md": true, ".csv": true, ".json": true, ".yaml": true, ".yml": true, ".xml": true,
+	".html": true, ".css": true, ".sql": true, ".go": true, ".py": true, ".js": true, ".ts": true,
+	".java": true, ".kt": true, ".c": true, ".h": true, ".cpp": true, ".hpp": true, ".cs": true,
+	".rb": true, ".php": true, ".rs": true, ".swift": true, ".sh": true,
+}
+
+// sha256 of the file content in hex
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// text of a pdf, source file or zip of source files, ok is false for files without readable text
+func ExtractText(path string) (text string, ok bool, err error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	switch {
+	case ext == ".pdf":
+		data, err := readLimited(path)
+		if err != nil {
+			return "", false, err
+		}
+		text = pdfText(data)
+	case ext == ".zip":
+		text, err = zipText(path)
+		if err != nil {
+			return "", false, err
+		}
+	case textExtensions[ext]:
+		data, err := readLimited(path)
+		if err != nil {
+			return "", false, err
+		}
+		text = string(data)
+	default:
+		return "", false, nil
+	}
+
+	return text, strings.TrimSpace(text) != "", nil
+}
+
+func readLimited(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(io.LimitReader(file, maxTextSize))
+}
+
+// source files of the archive in name order, total text is capped like a single file
+func zipText(path string) (string, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to open zip: %w", err)
+	}
+	defer archive.Close()
+
+	var text strings.Builder
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || !textExtensions[strings.ToLower(filepath.Ext(entry.Name))] {
+			continue
+		}
+
+		remaining := int64(maxTextSize - text.Len())
+		if remaining <= 0 {
+			break
+		}
+
+		reader, err := entry.Open()
+		if err != nil {
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(reader, remaining))
+		reader.Close()
+		if err != nil {
+			continue
+		}
+
+		text.Write(data)
+		text.WriteByte('\n')
+	}
+
+	return text.String(), nil
+}
+
+var (
+	pdfStream = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n`)
+	pdfBlock  = regexp.MustCompile(`(?s)BT(.*?)ET`)
+)
+
+// best effort text of the pdf content streams, literal strings inside BT/ET blocks are kept,
+// pdfs with embedded font encodings may give no text at all
+func pdfText(data []byte) string {
+	var text strings.Builder
+
+	for _, loc := range pdfStream.FindAllSubmatchIndex(data, -1) {
+		start := loc[1]
+		end := bytes.Index(data[start:], []byte("endstream"))
+		if end < 0 {
+			continue
+		}
+
+		content := data[start : start+end]
+		if bytes.Contains(data[loc[2]:loc[3]], []byte("/FlateDecode")) {
+			reader, err := zlib.NewReader(bytes.NewReader(content))
+			if err != nil {
+				continue
+			}
+
+			// truncated streams still give the text decoded so far
+			content, _ = io.ReadAll(io.LimitReader(reader, maxTextSize))
+			reader.Close()
+		}
+
+		for _, block := range pdfBlock.FindAllSubmatch(content, -1) {
+			text.WriteString(pdfStrings(block[1]))
+			text.WriteByte('\n')
+		}
+
+		if text.Len() > maxTextSize {
+			break
+		}
+	}
+
+	return text.String()
+}
+
+// literal strings of a text block, text positioning operators become spaces
+func pdfStrings(block []byte) string {
+	var text strings.Builder
+
+	for i := 0; i < len(block); i++ {
+		switch block[i] {
+		case '(':
+			str, next := pdfLiteral(block, i+1)
+			text.WriteString(str)
+			i = next
+		case 'T', '\'', '"':
+			// Td, TD, T* & quote operators move to the next line or position
+			text.WriteByte(' ')
+		}
+	}
+
+	return text.String()
+}
+
+// decode literal string starting after its opening bracket, returns the index of its closing bracket
+func pdfLiteral(block []byte, i int) (string, int) {
+	var str strings.Builder
+	depth := 1
+
+	for ; i < len(block); i++ {
+		c := block[i]
+		switch {
+		case c == '\\' && i+1 < len(block):
+			i++
+			switch block[i] {
+			case 'n', 'r':
+				str.WriteByte(' ')
+			case 't':
+				str.WriteByte('\t')
+			case '(', ')', '\\':
+				str.WriteByte(block[i])
+			default:
+				// octal character code, up to 3 digits
+				if block[i] >= '0' && block[i] <= '7' {
+					code := 0
+					for n := 0; n < 3 && i < len(block) && block[i] >= '0' && block[i] <= '7'; n++ {
+						code = code*8 + int(block[i]-'0')
+						i++
+					}
+					i--
+					str.WriteByte(byte(code))
+				}
+			}
+		case c == '(':
+			depth++
+			str.WriteByte(c)
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return str.String(), i
+			}
+			str.WriteByte(c)
+		default:
+			str.WriteByte(c)
+		}
+	}
+
+	return str.String(), i
+}
@@ -0,0 +1,126 @@
+package similarity
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// words per shingle, shorter runs of shared words aren't considered copied
+	ShingleSize = 5
+
+	// longest matching segments kept per pair
+	maxSegments = 5
+
+	// words of a matching segment kept for the report
+	maxSegmentWords = 80
+)
+
+// words of a submission & the positions of each of its shingles
+type Document struct {
+	words    []string
+	shingles map[uint64][]int
+}
+
+// matching segment shared by two documents
+type Segment struct {
+	Text  string
+	Words int
+}
+
+// jaccard similarity of two documents shingle sets & their longest shared segments
+type Match struct {
+	Similarity float64
+	Segments   []Segment
+}
+
+func NewDocument(text string) *Document {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_'
+	})
+
+	doc := &Document{
+		words:    words,
+		shingles: make(map[uint64][]int),
+	}
+
+	for i := 0; i+ShingleSize <= len(words); i++ {
+		key := shingleKey(words[i : i+ShingleSize])
+		doc.shingles[key] = append(doc.shingles[key], i)
+	}
+
+	return doc
+}
+
+// document is too short to have a single shingle
+func (d *Document) Empty() bool {
+	return len(d.shingles) == 0
+}
+
+func shingleKey(words []string) uint64 {
+	hash := fnv.New64a()
+	for _, word := range words {
+		hash.Write([]byte(strings.ToLower(word)))
+		hash.Write([]byte{0})
+	}
+	return hash.Sum64()
+}
+
+func Compare(a, b *Document) Match {
+	if a.Empty() || b.Empty() {
+		return Match{}
+	}
+
+	shared := 0
+	covered := make([]bool, len(a.words))
+	for key, positions := range a.shingles {
+		if _, ok := b.shingles[key]; !ok {
+			continue
+		}
+
+		shared++
+		for _, position := range positions {
+			for i := position; i < position+ShingleSize; i++ {
+				covered[i] = true
+			}
+		}
+	}
+
+	match := Match{
+		Similarity: float64(shared) / float64(len(a.shingles)+len(b.shingles)-shared),
+	}
+
+	// consecutive covered words of the first document form a segment
+	for start := 0; start < len(covered); start++ {
+		if !covered[start] {
+			continue
+		}
+
+		end := start
+		for end < len(covered) && covered[end] {
+			end++
+		}
+
+		words := a.words[start:end]
+		if len(words) > maxSegmentWords {
+			words = words[:maxSegmentWords]
+		}
+
+		match.Segments = append(match.Segments, Segment{
+			Text:  strings.Join(words, " "),
+			Words: end - start,
+		})
+		start = end
+	}
+
+	sort.SliceStable(match.Segments, func(i, j int) bool {
+		return match.Segments[i].Words > match.Segments[j].Words
+	})
+	if len(match.Segments) > maxSegments {
+		match.Segments = match.Segments[:maxSegments]
+	}
+
+	return match
+}
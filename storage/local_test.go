@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocalStoragePutGet(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+
+	store, err := NewLocalStorage(root, "http://localhost/files", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		key         string
+		content     string
+		size        int64
+		contentType string
+		wantType    string
+		wantErr     bool
+	}{
+		{"pdf", "excuses/1/note.pdf", "%PDF-1.4", 8, "application/pdf", "application/pdf", false},
+		// html upload is only allowed as plain text, the key extension must not decide how it's served
+		{"html served as stored type", "submissions/1/page.html", "<script>alert(1)</script>", 25, "text/plain; charset=utf-8", "text/plain; charset=utf-8", false},
+		{"no content type", "submissions/1/blob.bin", "data", 4, "", "application/octet-stream", false},
+		{"unknown size", "submissions/1/stream.zip", "zip", -1, "application/zip", "application/zip", false},
+		{"short body", "submissions/1/short.zip", "zip", 10, "application/zip", "", true},
+		{"parent directory", "../outside.txt", "x", 1, "text/plain", "", true},
+		{"absolute", "/etc/passwd", "x", 1, "text/plain", "", true},
+		{"not clean", "submissions/../outside.txt", "x", 1, "text/plain", "", true},
+		{"backslash", `submissions\1.txt`, "x", 1, "text/plain", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.Put(ctx, tt.key, strings.NewReader(tt.content), tt.size, tt.contentType)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				// failed upload leaves no object behind
+				if _, err := store.Get(ctx, tt.key); err == nil {
+					t.Fatal("object of a failed upload exists")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			object, err := store.Get(ctx, tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer object.Body.Close()
+
+			content, err := io.ReadAll(object.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != tt.content || object.Size != int64(len(tt.content)) || object.ContentType != tt.wantType {
+				t.Fatalf("got %q size %d type %q, want %q type %q", content, object.Size, object.ContentType, tt.content, tt.wantType)
+			}
+		})
+	}
+
+	t.Run("file stored before content types were kept", func(t *testing.T) {
+		target := filepath.Join(root, "submissions", "legacy.html")
+		if err := os.WriteFile(target, []byte("<html>"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		object, err := store.Get(ctx, "submissions/legacy.html")
+		if err != nil {
+			t.Fatal(err)
+		}
+		object.Body.Close()
+
+		if object.ContentType != "application/octet-stream" {
+			t.Fatalf("got %q, want application/octet-stream", object.ContentType)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		key := "excuses/1/note.pdf"
+		if err := store.Delete(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("got %v, want ErrNotFound", err)
+		}
+
+		if _, err := os.Stat(filepath.Join(root, "excuses", "1", "note.pdf"+typeSuffix)); !os.IsNotExist(err) {
+			t.Fatalf("content type of a deleted object is left: %v", err)
+		}
+
+		// deleting again is fine
+		if err := store.Delete(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestLocalStorageVerify(t *testing.T) {
+	store, err := NewLocalStorage(t.TempDir(), "http://localhost/files", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put(context.Background(), "submissions/1/a.zip", bytes.NewReader([]byte("zip")), 3, "application/zip"); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := NewLink(context.Background(), store, "submissions/1/a.zip", "report.zip", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(link.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+
+	expired, err := NewLocalStorage(t.TempDir(), "http://localhost/files", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiredURL, err := expired.SignedURL(context.Background(), "submissions/1/a.zip", "report.zip", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiredURLParsed, _ := url.Parse(expiredURL)
+	expiredQuery := expiredURLParsed.Query()
+
+	later := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		key       string
+		fileName  string
+		expires   string
+		signature string
+		wantErr   string
+	}{
+		{"valid", "secret", query.Get("key"), query.Get("name"), query.Get("expires"), query.Get("signature"), ""},
+		{"other key", "secret", "submissions/1/b.zip", query.Get("name"), query.Get("expires"), query.Get("signature"), "invalid download link"},
+		{"other name", "secret", query.Get("key"), "report.html", query.Get("expires"), query.Get("signature"), "invalid download link"},
+		{"extended expiry", "secret", query.Get("key"), query.Get("name"), later, query.Get("signature"), "invalid download link"},
+		{"forged signature", "secret", query.Get("key"), query.Get("name"), query.Get("expires"), strings.Repeat("0", 64), "invalid download link"},
+		{"other secret", "another", query.Get("key"), query.Get("name"), query.Get("expires"), query.Get("signature"), "invalid download link"},
+		{"expired", "secret", expiredQuery.Get("key"), expiredQuery.Get("name"), expiredQuery.Get("expires"), expiredQuery.Get("signature"), "download link has expired"},
+		{"bad expiry", "secret", query.Get("key"), query.Get("name"), "soon", query.Get("signature"), "download link has expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := NewLocalStorage(t.TempDir(), "http://localhost/files", []byte(tt.secret))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = verifier.Verify(tt.key, tt.fileName, tt.expires, tt.signature)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("got %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+
+	if link.FileName != "report.zip" || time.Until(link.ExpiresAt) > time.Minute {
+		t.Fatalf("got link %+v", link)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	key, err := deriveKey("jwt-secret", linkKeyLabel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, _ := deriveKey("jwt-secret", linkKeyLabel)
+	other, _ := deriveKey("jwt-secret", "another purpose")
+
+	switch {
+	case len(key) != 32:
+		t.Fatalf("got %d byte key, want 32", len(key))
+	case !bytes.Equal(key, again):
+		t.Fatal("derived key isn't stable")
+	case bytes.Equal(key, other):
+		t.Fatal("labels must give different keys")
+	case bytes.Contains(key, []byte("jwt-secret")):
+		t.Fatal("derived key contains the master secret")
+	}
+
+	if _, err := deriveKey("", linkKeyLabel); err == nil {
+		t.Fatal("expected an error without a master secret")
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	key, err := NewKey("submissions/7", ".ZIP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(key, "submissions/7/") || !strings.HasSuffix(key, ".zip") || validKey(key) != nil {
+		t.Fatalf("got key %q", key)
+	}
+
+	other, _ := NewKey("submissions/7", ".zip")
+	if other == key {
+		t.Fatal("keys must be unique")
+	}
+}
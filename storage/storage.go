@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// hkdf info of the link signing key when it's derived from the jwt secret
+const linkKeyLabel = "go-learn storage link signing v1"
+
+// object doesn't exist in the storage
+var ErrNotFound = errors.New("object not found")
+
+// stored object, body must be closed by the caller
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+}
+
+// keys are opaque slash separated names chosen by the app, never derived from user input
+type Storage interface {
+	Name() string
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (*Object, error)
+	Delete(ctx context.Context, key string) error
+	// expiring link downloading the object as fileName
+	SignedURL(ctx context.Context, key, fileName string, expiry time.Duration) (string, error)
+}
+
+// choose storage from STORAGE_DRIVER env (local or s3), default to local disk under STORAGE_LOCAL_DIR
+func NewStorage(appURL string) (Storage, error) {
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "s3":
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		})
+	case "", "local":
+		root := os.Getenv("STORAGE_LOCAL_DIR")
+		if root == "" {
+			root = "uploads"
+		}
+
+		// a dedicated key is preferred, otherwise one is derived so link & jwt signatures never share a key
+		secret := []byte(os.Getenv("STORAGE_SIGNING_KEY"))
+		if len(secret) == 0 {
+			var err error
+			if secret, err = deriveKey(os.Getenv("JWT_SECRET_KEY"), linkKeyLabel); err != nil {
+				return nil, err
+			}
+		}
+
+		return NewLocalStorage(root, appURL+"/files", secret)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", os.Getenv("STORAGE_DRIVER"))
+	}
+}
+
+// download link handed to the client
+type Link struct {
+	URL       string
+	FileName  string
+	ExpiresAt time.Time
+}
+
+func NewLink(ctx context.Context, store Storage, key, fileName string, expiry time.Duration) (*Link, error) {
+	url, err := store.SignedURL(ctx, key, fileName, expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Link{URL: url, FileName: fileName, ExpiresAt: time.Now().Add(expiry)}, nil
+}
+
+// copy an object to a temporary file keeping the key extension, the caller removes the file
+func TempFile(ctx context.Context, store Storage, key string) (string, error) {
+	object, err := store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer object.Body.Close()
+
+	file, err := os.CreateTemp("", "object-*"+filepath.Ext(key))
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(file, object.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+// new random key under prefix keeping the file extension, e.g. submissions/12/3f2a...9c.pdf
+func NewKey(prefix, ext string) (string, error) {
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate storage key")
+	}
+
+	return path.Join(prefix, hex.EncodeToString(suffix)+strings.ToLower(ext)), nil
+}
+
+// key must be a clean relative path so it can't leave the storage root
+func validKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key ||
+		key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+
+	return nil
+}
+
+// 32 byte key of its own for label, derived from master with hkdf-sha256
+func deriveKey(master, label string) ([]byte, error) {
+	if master == "" {
+		return nil, fmt.Errorf("STORAGE_SIGNING_KEY or JWT_SECRET_KEY is required to sign download links")
+	}
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(master), nil, []byte(label)), key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// hmac signature of a download link, name & expiry are signed so neither can be changed
+func sign(secret []byte, key, fileName string, expires int64) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%s\n%d", key, fileName, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}